@@ -1,6 +1,9 @@
 import { initSearch } from "./modules/search";
 import { initAgeGate } from "./modules/age";
-import { initReadingProgressSaver } from "./modules/progress";
+import {
+  initReadingProgressSaver,
+  initServerProgressLinks,
+} from "./modules/progress";
 import { initStatusBadge } from "./modules/status";
 import { initCatalogPagination } from "./modules/catalog";
 import Dropdown from "./modules/dropdown";
@@ -9,6 +12,15 @@ import { initDescription } from "./modules/description";
 import { initProfile, initProfileModal } from "./modules/profile";
 import { initComments } from "./modules/comments";
 import { initSettings, initSettingsModal } from "./modules/settings";
+import { initLibraryPage, initLibraryButton } from "./modules/library";
+import { initHistoryPage, initHistoryRecorder } from "./modules/history";
+import {
+  initNotificationsBell,
+  initFollowButton,
+  initNotificationsPage,
+} from "./modules/notifications";
+import { initPushToggle } from "./modules/push";
+import { initRatings } from "./modules/reviews";
 
 declare global {
   interface Window {
@@ -31,9 +43,19 @@ document.addEventListener("DOMContentLoaded", () => {
     initAgeGate();
     initDescription();
     initReadingProgressSaver();
+    initServerProgressLinks();
     initStatusBadge();
     initCatalogPagination();
     initComments();
+    initLibraryPage();
+    initLibraryButton();
+    initHistoryPage();
+    initHistoryRecorder();
+    initNotificationsBell();
+    initFollowButton();
+    initNotificationsPage();
+    initPushToggle();
+    initRatings();
 
     console.info("All modules initialized successfully");
   } catch (err) {
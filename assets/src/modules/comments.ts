@@ -1,15 +1,22 @@
-import { profileManager, getAvatarUrl } from "./profile";
+import { profileManager, getAvatarUrl, BlockKind } from "./profile";
 
 const API_URL = process.env.API_URL;
 const TURNSTILE_COMMENTS_SITE_KEY =
   process.env.TURNSTILE_COMMENTS_SITE_KEY || "";
 const PENDING_COMMENTS_KEY = "kappalib_pending_comments";
 const PENDING_TTL = 3 * 60 * 60 * 1000;
+const REPORT_REASONS: Record<string, string> = {
+  spam: "Спам",
+  offensive: "Оскорбления",
+  spoiler: "Спойлер",
+  other: "Другое",
+};
 
 interface PendingComment {
   id: string;
   visibleId: string;
   chapterId: string;
+  parentId?: string | null;
   contentHtml: string;
   createdAt: number;
   userDisplayName: string;
@@ -21,12 +28,33 @@ interface Comment {
   id: string;
   chapter_id: string;
   user_id: string;
+  parent_id: string | null;
+  depth: number;
+  replies_count: number;
+  likes_count: number;
+  dislikes_count: number;
   content_html: string;
   status: string;
   created_at: string;
+  edited_at: string | null;
+  is_deleted: boolean;
   user_display_name: string;
   user_avatar_seed: string;
   user_has_custom_avatar: boolean;
+  replies?: Comment[];
+  has_more_replies?: boolean;
+}
+
+interface CommentReplies {
+  replies: Comment[];
+  has_more: boolean;
+}
+
+interface CommentReaction {
+  comment_id: string;
+  reaction: string;
+  likes_count: number;
+  dislikes_count: number;
 }
 
 interface CommentsPage {
@@ -35,6 +63,7 @@ interface CommentsPage {
   page_size: number;
   total_count: number;
   total_pages: number;
+  total_with_replies: number;
 }
 
 function getPendingComments(): PendingComment[] {
@@ -64,7 +93,7 @@ function removePendingComment(id: string): void {
   localStorage.setItem(PENDING_COMMENTS_KEY, JSON.stringify(comments));
 }
 
-function formatRelativeTime(dateStr: string): string {
+export function formatRelativeTime(dateStr: string): string {
   const date = new Date(dateStr);
   const now = new Date();
   const diff = now.getTime() - date.getTime();
@@ -103,41 +132,227 @@ function createCommentHTML(
 
   const avatarUrl = getAvatarUrl(userId, hasCustomAvatar, avatarSeed);
 
+  if (isApiComment && comment.is_deleted) {
+    return `
+    <div class="comment-item comment-deleted" id="comment-${comment.id}" data-comment-id="${comment.id}">
+      <div class="comment-avatar"></div>
+      <div class="comment-body">
+        <div class="comment-header">
+          <span class="comment-date">${formatRelativeTime(createdAt)}</span>
+        </div>
+        <div class="comment-content comment-deleted-text">Комментарий удалён</div>
+      </div>
+    </div>
+  `;
+  }
+
+  const isOwn = userId === profileManager.getProfileId();
+  const replyButton =
+    !isPending && profileManager.isLoggedIn()
+      ? `<button class="comment-reply-btn" type="button" data-reply-to="${comment.id}">Ответить</button>`
+      : "";
+  const reportButton =
+    isApiComment && !isPending && !isOwn && profileManager.isLoggedIn()
+      ? `<button class="comment-reply-btn" type="button" data-report="${comment.id}">Пожаловаться</button>
+          <button class="comment-reply-btn" type="button" data-hide="${comment.id}" data-author="${userId}">Скрыть автора</button>`
+      : "";
+  const ownButtons = isOwn
+    ? `${isPending ? "" : `<button class="comment-reply-btn" type="button" data-edit="${comment.id}">Изменить</button>`}
+          <button class="comment-reply-btn" type="button" data-delete="${comment.id}">Удалить</button>`
+    : "";
+  const reactions =
+    isApiComment && !isPending ? createReactionsHTML(comment) : "";
+  const edited =
+    isApiComment && comment.edited_at
+      ? `<span class="comment-edited" title="${new Date(comment.edited_at).toLocaleString("ru-RU")}">изменено</span>`
+      : "";
+
   return `
-    <div class="comment-item${isPending ? " comment-pending" : ""}" data-comment-id="${comment.id}">
+    <div class="comment-item${isPending ? " comment-pending" : ""}" id="comment-${comment.id}" data-comment-id="${comment.id}">
       <img src="${avatarUrl}" alt="${displayName}" class="comment-avatar" loading="lazy"/>
       <div class="comment-body">
         <div class="comment-header">
-          <span class="comment-author">${displayName}</span>
+          <a href="/user/${userId}" class="comment-author">${displayName}</a>
           <span class="comment-date">${formatRelativeTime(createdAt)}</span>
+          ${edited}
           ${isPending ? '<span class="comment-moderation-badge">На модерации</span>' : ""}
         </div>
         <div class="comment-content">${contentHtml}</div>
+        <div class="comment-actions">
+          ${reactions}
+          ${replyButton}
+          ${ownButtons}
+          ${reportButton}
+        </div>
       </div>
     </div>
   `;
 }
 
+function createReactionsHTML(comment: Comment): string {
+  const isOwn = comment.user_id === profileManager.getProfileId();
+  const disabled =
+    isOwn || !profileManager.isLoggedIn() ? " disabled" : "";
+  return `
+    <button class="comment-reaction" type="button" data-comment-id="${comment.id}" data-reaction="like" aria-label="Нравится"${disabled}>
+      <span>▲</span><span class="comment-reaction-count">${comment.likes_count}</span>
+    </button>
+    <button class="comment-reaction" type="button" data-comment-id="${comment.id}" data-reaction="dislike" aria-label="Не нравится"${disabled}>
+      <span>▼</span><span class="comment-reaction-count">${comment.dislikes_count}</span>
+    </button>
+  `;
+}
+
+function applyReaction(container: HTMLElement, r: CommentReaction): void {
+  container
+    .querySelectorAll<HTMLButtonElement>(
+      `.comment-reaction[data-comment-id="${CSS.escape(r.comment_id)}"]`,
+    )
+    .forEach((btn) => {
+      const kind = btn.dataset.reaction;
+      btn.classList.toggle("active", kind === r.reaction);
+      const count = btn.querySelector(".comment-reaction-count");
+      if (count) {
+        count.textContent = String(
+          kind === "like" ? r.likes_count : r.dislikes_count,
+        );
+      }
+    });
+}
+
+async function loadOwnReactions(
+  container: HTMLElement,
+  chapterId: string,
+): Promise<void> {
+  if (!profileManager.isLoggedIn()) return;
+  try {
+    const res = await fetch(`${API_URL}/chapters/${chapterId}/reactions`, {
+      headers: {
+        "X-Profile-ID": profileManager.getProfileId() || "",
+        "X-Secret-Token": profileManager.getSecretToken() || "",
+      },
+    });
+    if (!res.ok) return;
+    const data: { reactions: Record<string, string> } = await res.json();
+    Object.entries(data.reactions || {}).forEach(([commentId, reaction]) => {
+      container
+        .querySelectorAll<HTMLElement>(
+          `.comment-reaction[data-comment-id="${CSS.escape(commentId)}"][data-reaction="${reaction}"]`,
+        )
+        .forEach((btn) => btn.classList.add("active"));
+    });
+  } catch (err) {
+    console.error("Failed to load own reactions", err);
+  }
+}
+
+async function toggleReaction(
+  container: HTMLElement,
+  btn: HTMLButtonElement,
+): Promise<void> {
+  const commentId = btn.dataset.commentId;
+  const reaction = btn.dataset.reaction;
+  if (!commentId || !reaction) return;
+
+  const isActive = btn.classList.contains("active");
+  btn.disabled = true;
+
+  try {
+    const res = await fetch(`${API_URL}/comments/${commentId}/reaction`, {
+      method: isActive ? "DELETE" : "PUT",
+      headers: {
+        "Content-Type": "application/json",
+        "X-Profile-ID": profileManager.getProfileId() || "",
+        "X-Secret-Token": profileManager.getSecretToken() || "",
+      },
+      body: isActive ? undefined : JSON.stringify({ reaction }),
+    });
+    if (!res.ok) throw new Error("Failed to update reaction");
+    applyReaction(container, await res.json());
+  } catch (err) {
+    console.error("Failed to update reaction", err);
+  } finally {
+    btn.disabled = false;
+  }
+}
+
+function createThreadHTML(
+  comment: Comment,
+  pendingByParent: Map<string, PendingComment[]>,
+): string {
+  const pending = pendingByParent.get(comment.id) || [];
+  const replies = comment.replies || [];
+
+  let repliesHtml = "";
+  replies.forEach((r) => {
+    repliesHtml += createThreadHTML(r, pendingByParent);
+  });
+  pending.forEach((p) => {
+    repliesHtml += createCommentHTML(p, true);
+  });
+  if (comment.has_more_replies) {
+    repliesHtml += `<button type="button" class="comment-reply-btn" data-more-replies="${comment.id}">Показать все ответы</button>`;
+  }
+
+  return `
+    <div class="comment-thread">
+      ${createCommentHTML(comment, false)}
+      <div class="comment-reply-form-slot"></div>
+      ${repliesHtml ? `<div class="comment-replies">${repliesHtml}</div>` : ""}
+    </div>
+  `;
+}
+
+function collectIds(comments: Comment[], ids: Set<string>): void {
+  comments.forEach((c) => {
+    ids.add(c.id);
+    if (c.replies) collectIds(c.replies, ids);
+  });
+}
+
+function groupPendingByParent(
+  pendingComments: PendingComment[],
+  chapterId: string,
+  loadedIds: Set<string>,
+): Map<string, PendingComment[]> {
+  const pendingByParent = new Map<string, PendingComment[]>();
+  pendingComments.forEach((c) => {
+    if (c.chapterId !== chapterId || loadedIds.has(c.id)) return;
+    if (c.parentId && loadedIds.has(c.parentId)) {
+      const list = pendingByParent.get(c.parentId) || [];
+      list.push(c);
+      pendingByParent.set(c.parentId, list);
+    }
+  });
+  return pendingByParent;
+}
+
 function renderComments(
   container: HTMLElement,
   comments: Comment[],
   pendingComments: PendingComment[],
   chapterId: string,
 ): void {
-  const chapterPending = pendingComments.filter(
-    (c) => c.chapterId === chapterId,
+  const loadedIds = new Set<string>();
+  collectIds(comments, loadedIds);
+
+  const pendingByParent = groupPendingByParent(
+    pendingComments,
+    chapterId,
+    loadedIds,
+  );
+  const topLevelPending = pendingComments.filter(
+    (c) => c.chapterId === chapterId && !loadedIds.has(c.id) && !c.parentId,
   );
-  const pendingIds = new Set(chapterPending.map((c) => c.id));
-  const filteredComments = comments.filter((c) => !pendingIds.has(c.id));
 
   let html = "";
 
-  chapterPending.forEach((c) => {
+  topLevelPending.forEach((c) => {
     html += createCommentHTML(c, true);
   });
 
-  filteredComments.forEach((c) => {
-    html += createCommentHTML(c, false);
+  comments.forEach((c) => {
+    html += createThreadHTML(c, pendingByParent);
   });
 
   const listEl = container.querySelector(".comments-list");
@@ -151,6 +366,57 @@ function renderComments(
   }
 }
 
+async function loadAllReplies(
+  container: HTMLElement,
+  btn: HTMLButtonElement,
+  chapterId: string,
+): Promise<void> {
+  const commentId = btn.dataset.moreReplies;
+  const thread = btn.closest(".comment-thread");
+  if (!commentId || !thread) return;
+
+  btn.disabled = true;
+  try {
+    const headers: Record<string, string> = {};
+    if (profileManager.isLoggedIn()) {
+      headers["X-Profile-ID"] = profileManager.getProfileId() || "";
+      headers["X-Secret-Token"] = profileManager.getSecretToken() || "";
+    }
+    const res = await fetch(`${API_URL}/comments/${commentId}/replies`, {
+      headers,
+    });
+    if (!res.ok) throw new Error("Failed to load replies");
+
+    const data: CommentReplies = await res.json();
+
+    const loadedIds = new Set<string>([commentId]);
+    collectIds(data.replies, loadedIds);
+    const pendingByParent = groupPendingByParent(
+      getPendingComments(),
+      chapterId,
+      loadedIds,
+    );
+
+    let html = "";
+    data.replies.forEach((r) => {
+      html += createThreadHTML(r, pendingByParent);
+    });
+    (pendingByParent.get(commentId) || []).forEach((p) => {
+      html += createCommentHTML(p, true);
+    });
+
+    const repliesEl = thread.querySelector(":scope > .comment-replies");
+    if (repliesEl) {
+      repliesEl.innerHTML = html;
+    }
+
+    loadOwnReactions(container, chapterId);
+  } catch (err) {
+    console.error("Failed to load replies", err);
+    btn.disabled = false;
+  }
+}
+
 function renderPagination(
   container: HTMLElement,
   page: number,
@@ -234,18 +500,26 @@ async function loadComments(
   }
 
   try {
+    const headers: Record<string, string> = {};
+    if (profileManager.isLoggedIn()) {
+      headers["X-Profile-ID"] = profileManager.getProfileId() || "";
+      headers["X-Secret-Token"] = profileManager.getSecretToken() || "";
+    }
     const res = await fetch(
-      `${API_URL}/chapters/${chapterId}/comments?page=${page}`,
+      `${API_URL}/chapters/${chapterId}/comments?page=${page}&sort=${container.dataset.sort || "newest"}`,
+      { headers },
     );
     if (!res.ok) throw new Error("Failed to load comments");
 
     const data: CommentsPage = await res.json();
-    const pendingComments = getPendingComments();
 
-    data.comments.forEach((c) => {
-      removePendingComment(c.id);
+    const loadedIds = new Set<string>();
+    collectIds(data.comments, loadedIds);
+    loadedIds.forEach((id) => {
+      removePendingComment(id);
     });
 
+    container.dataset.page = String(data.page);
     renderComments(container, data.comments, getPendingComments(), chapterId);
     renderPagination(container, data.page, data.total_pages, chapterId);
 
@@ -254,9 +528,12 @@ async function loadComments(
       const pendingCount = getPendingComments().filter(
         (c) => c.chapterId === chapterId,
       ).length;
-      const totalDisplay = data.total_count + pendingCount;
+      const totalDisplay = data.total_with_replies + pendingCount;
       countEl.textContent = `${totalDisplay}`;
     }
+
+    loadOwnReactions(container, chapterId);
+    scrollToLinkedComment(container);
   } catch (err) {
     console.error("Failed to load comments", err);
     if (listEl) {
@@ -306,7 +583,7 @@ function loadTurnstileScript(): Promise<void> {
   });
 }
 
-async function initTurnstileForComments(container: HTMLElement): Promise<void> {
+export async function initTurnstileForComments(container: HTMLElement): Promise<void> {
   if (!TURNSTILE_COMMENTS_SITE_KEY) {
     console.warn("TURNSTILE_COMMENTS_SITE_KEY not set");
     return;
@@ -338,7 +615,7 @@ async function initTurnstileForComments(container: HTMLElement): Promise<void> {
 
 let tokenPromise: Promise<string | null> | null = null;
 
-async function getTurnstileToken(): Promise<string | null> {
+export async function getTurnstileToken(): Promise<string | null> {
   if (!turnstileWidgetId) return null;
 
   if (tokenPromise) {
@@ -409,8 +686,50 @@ export function initComments(): void {
   renderCommentForm(container);
   loadComments(container, chapterId);
 
+  document
+    .getElementById("comments-sort")
+    ?.addEventListener("change", (e: Event) => {
+      const value = (e as CustomEvent<{ value: string }>).detail.value;
+      container.dataset.sort = value;
+      loadComments(container, chapterId, 1);
+    });
+
   container.addEventListener("click", (e) => {
     const target = e.target as HTMLElement;
+    const reactionBtn = target.closest(
+      ".comment-reaction",
+    ) as HTMLButtonElement | null;
+    if (reactionBtn && !reactionBtn.disabled) {
+      toggleReaction(container, reactionBtn);
+      return;
+    }
+
+    const replyBtn = target.closest(".comment-reply-btn") as HTMLElement;
+    if (replyBtn?.dataset.moreReplies) {
+      loadAllReplies(container, replyBtn as HTMLButtonElement, chapterId);
+      return;
+    }
+    if (replyBtn?.dataset.replyTo) {
+      openReplyForm(container, replyBtn.dataset.replyTo);
+      return;
+    }
+    if (replyBtn?.dataset.edit) {
+      openEditForm(container, replyBtn.dataset.edit);
+      return;
+    }
+    if (replyBtn?.dataset.delete) {
+      deleteComment(container, replyBtn.dataset.delete);
+      return;
+    }
+    if (replyBtn?.dataset.report) {
+      openReportForm(container, replyBtn.dataset.report);
+      return;
+    }
+    if (replyBtn?.dataset.hide && replyBtn.dataset.author) {
+      openHideForm(container, replyBtn.dataset.hide, replyBtn.dataset.author);
+      return;
+    }
+
     const pageBtn = target.closest(".page-link[data-page]") as HTMLElement;
     if (pageBtn && !pageBtn.classList.contains("disabled")) {
       const page = parseInt(pageBtn.dataset.page || "1", 10);
@@ -452,6 +771,412 @@ function renderCommentForm(container: HTMLElement): void {
   }
 }
 
+async function submitComment(
+  container: HTMLElement,
+  chapterId: string,
+  textarea: HTMLTextAreaElement,
+  submitBtn: HTMLButtonElement,
+  parentId: string | null,
+): Promise<boolean> {
+  const content = textarea.value.trim();
+  if (!content) return false;
+  if (content.length > 1000) {
+    alert("Комментарий слишком длинный (максимум 1000 символов)");
+    return false;
+  }
+
+  if (!profileManager.isLoggedIn()) {
+    alert("Войдите в аккаунт, чтобы оставить комментарий");
+    return false;
+  }
+
+  const label = submitBtn.textContent;
+  submitBtn.disabled = true;
+  submitBtn.textContent = "Проверка...";
+
+  const token = await getTurnstileToken();
+  if (!token) {
+    alert("Не удалось пройти проверку. Попробуйте ещё раз.");
+    submitBtn.disabled = false;
+    submitBtn.textContent = label;
+    return false;
+  }
+
+  submitBtn.textContent = "Отправка...";
+
+  try {
+    const profileId = profileManager.getProfileId();
+    const secretToken = localStorage.getItem("kappalib_secret_token");
+
+    const res = await fetch(`${API_URL}/chapters/${chapterId}/comments`, {
+      method: "POST",
+      headers: {
+        "Content-Type": "application/json",
+        "X-Profile-ID": profileId || "",
+        "X-Secret-Token": secretToken || "",
+      },
+      body: JSON.stringify({
+        content: content,
+        parent_id: parentId || undefined,
+        turnstile_token: token,
+      }),
+    });
+
+    if (!res.ok) {
+      const err = await res.json();
+      throw new Error(err.detail || "Failed to create comment");
+    }
+
+    const comment: Comment = await res.json();
+
+    addPendingComment({
+      id: comment.id,
+      visibleId: comment.user_id,
+      chapterId: comment.chapter_id,
+      parentId: comment.parent_id,
+      contentHtml: comment.content_html,
+      createdAt: Date.now(),
+      userDisplayName: comment.user_display_name,
+      userAvatarSeed: comment.user_avatar_seed,
+      userHasCustomAvatar: comment.user_has_custom_avatar,
+    });
+
+    textarea.value = "";
+
+    if (turnstileWidgetId) {
+      (window as any).turnstile.reset(turnstileWidgetId);
+    }
+
+    const page = parseInt(container.dataset.page || "1", 10);
+    await loadComments(container, chapterId, parentId ? page : 1);
+    return true;
+  } catch (err) {
+    console.error("Failed to submit comment", err);
+    alert("Не удалось отправить комментарий. Попробуйте ещё раз.");
+    return false;
+  } finally {
+    submitBtn.disabled = false;
+    submitBtn.textContent = label;
+  }
+}
+
+function openReplyForm(container: HTMLElement, commentId: string): void {
+  container.querySelectorAll(".comment-reply-form").forEach((el) => el.remove());
+
+  const item = container.querySelector(`#comment-${CSS.escape(commentId)}`);
+  const slot = item?.parentElement?.querySelector(
+    ":scope > .comment-reply-form-slot",
+  );
+  const chapterId = container.dataset.chapterId;
+  if (!slot || !chapterId) return;
+
+  slot.innerHTML = `
+    <div class="comment-form comment-reply-form">
+      <textarea
+        class="comment-textarea"
+        placeholder="Написать ответ..."
+        maxlength="1000"
+        rows="2"
+      ></textarea>
+      <div class="comment-form-footer">
+        <button class="history-clear comment-reply-cancel" type="button">Отмена</button>
+        <button class="action-btn btn-primary comment-submit-btn comment-reply-submit">Ответить</button>
+      </div>
+    </div>
+  `;
+
+  const textarea = slot.querySelector("textarea") as HTMLTextAreaElement;
+  const submitBtn = slot.querySelector(
+    ".comment-reply-submit",
+  ) as HTMLButtonElement;
+
+  textarea.addEventListener("input", () => autoResizeTextarea(textarea));
+  initTurnstileForComments(container);
+  textarea.focus();
+
+  slot
+    .querySelector(".comment-reply-cancel")
+    ?.addEventListener("click", () => {
+      slot.innerHTML = "";
+    });
+
+  submitBtn.addEventListener("click", () => {
+    submitComment(container, chapterId, textarea, submitBtn, commentId);
+  });
+}
+
+function openEditForm(container: HTMLElement, commentId: string): void {
+  const item = container.querySelector<HTMLElement>(
+    `#comment-${CSS.escape(commentId)}`,
+  );
+  const contentEl = item?.querySelector<HTMLElement>(".comment-content");
+  const actionsEl = item?.querySelector<HTMLElement>(".comment-actions");
+  const chapterId = container.dataset.chapterId;
+  if (!item || !contentEl || !chapterId) return;
+  if (item.querySelector(".comment-edit-form")) return;
+
+  const tmp = document.createElement("div");
+  tmp.innerHTML = contentEl.innerHTML;
+  const original = tmp.innerText.trim();
+
+  contentEl.style.display = "none";
+  if (actionsEl) actionsEl.style.display = "none";
+  contentEl.insertAdjacentHTML(
+    "afterend",
+    `
+    <div class="comment-form comment-edit-form">
+      <textarea class="comment-textarea" maxlength="1000" rows="2"></textarea>
+      <div class="comment-form-footer">
+        <button class="history-clear comment-edit-cancel" type="button">Отмена</button>
+        <button class="action-btn btn-primary comment-submit-btn comment-edit-save">Сохранить</button>
+      </div>
+    </div>
+  `,
+  );
+
+  const form = item.querySelector<HTMLElement>(".comment-edit-form")!;
+  const textarea = form.querySelector("textarea") as HTMLTextAreaElement;
+  const saveBtn = form.querySelector(".comment-edit-save") as HTMLButtonElement;
+  textarea.value = original;
+  autoResizeTextarea(textarea);
+  textarea.addEventListener("input", () => autoResizeTextarea(textarea));
+  textarea.focus();
+
+  const close = () => {
+    form.remove();
+    contentEl.style.display = "";
+    if (actionsEl) actionsEl.style.display = "";
+  };
+
+  form
+    .querySelector(".comment-edit-cancel")
+    ?.addEventListener("click", close);
+
+  saveBtn.addEventListener("click", async () => {
+    const content = textarea.value.trim();
+    if (!content) return;
+    if (content === original) {
+      close();
+      return;
+    }
+
+    saveBtn.disabled = true;
+    saveBtn.textContent = "Сохранение...";
+
+    try {
+      const res = await fetch(`${API_URL}/comments/${commentId}`, {
+        method: "PATCH",
+        headers: {
+          "Content-Type": "application/json",
+          "X-Profile-ID": profileManager.getProfileId() || "",
+          "X-Secret-Token": profileManager.getSecretToken() || "",
+        },
+        body: JSON.stringify({ content }),
+      });
+      if (!res.ok) {
+        const err = await res.json();
+        throw new Error(err.detail || "Failed to edit comment");
+      }
+
+      const comment: Comment = await res.json();
+      removePendingComment(comment.id);
+      addPendingComment({
+        id: comment.id,
+        visibleId: comment.user_id,
+        chapterId: comment.chapter_id,
+        parentId: comment.parent_id,
+        contentHtml: comment.content_html,
+        createdAt: Date.now(),
+        userDisplayName: comment.user_display_name,
+        userAvatarSeed: comment.user_avatar_seed,
+        userHasCustomAvatar: comment.user_has_custom_avatar,
+      });
+
+      const page = parseInt(container.dataset.page || "1", 10);
+      await loadComments(container, chapterId, page);
+    } catch (err) {
+      console.error("Failed to edit comment", err);
+      alert("Не удалось изменить комментарий. Попробуйте ещё раз.");
+      saveBtn.disabled = false;
+      saveBtn.textContent = "Сохранить";
+    }
+  });
+}
+
+async function deleteComment(
+  container: HTMLElement,
+  commentId: string,
+): Promise<void> {
+  const chapterId = container.dataset.chapterId;
+  if (!chapterId) return;
+  if (!confirm("Удалить комментарий?")) return;
+
+  try {
+    const res = await fetch(`${API_URL}/comments/${commentId}`, {
+      method: "DELETE",
+      headers: {
+        "X-Profile-ID": profileManager.getProfileId() || "",
+        "X-Secret-Token": profileManager.getSecretToken() || "",
+      },
+    });
+    if (!res.ok && res.status !== 404) {
+      throw new Error("Failed to delete comment");
+    }
+
+    removePendingComment(commentId);
+    const page = parseInt(container.dataset.page || "1", 10);
+    await loadComments(container, chapterId, page);
+  } catch (err) {
+    console.error("Failed to delete comment", err);
+    alert("Не удалось удалить комментарий. Попробуйте ещё раз.");
+  }
+}
+
+function openReportForm(container: HTMLElement, commentId: string): void {
+  container.querySelectorAll(".comment-report-form").forEach((el) => el.remove());
+
+  const item = container.querySelector(`#comment-${CSS.escape(commentId)}`);
+  const slot = item?.parentElement?.querySelector(
+    ":scope > .comment-reply-form-slot",
+  );
+  if (!slot) return;
+
+  const reasons = Object.entries(REPORT_REASONS)
+    .map(
+      ([value, label]) =>
+        `<button class="history-clear" type="button" data-reason="${value}">${label}</button>`,
+    )
+    .join("");
+
+  slot.innerHTML = `
+    <div class="comment-form comment-report-form">
+      <p class="comment-report-title">Причина жалобы</p>
+      <div class="comment-form-footer">
+        ${reasons}
+        <button class="history-clear comment-report-cancel" type="button">Отмена</button>
+      </div>
+    </div>
+  `;
+
+  slot
+    .querySelector(".comment-report-cancel")
+    ?.addEventListener("click", () => {
+      slot.innerHTML = "";
+    });
+
+  slot.querySelectorAll<HTMLButtonElement>("[data-reason]").forEach((btn) => {
+    btn.addEventListener("click", async () => {
+      const form = slot.querySelector(".comment-report-form");
+      slot
+        .querySelectorAll<HTMLButtonElement>("button")
+        .forEach((b) => (b.disabled = true));
+
+      try {
+        const res = await fetch(`${API_URL}/comments/${commentId}/report`, {
+          method: "POST",
+          headers: {
+            "Content-Type": "application/json",
+            "X-Profile-ID": profileManager.getProfileId() || "",
+            "X-Secret-Token": profileManager.getSecretToken() || "",
+          },
+          body: JSON.stringify({ reason: btn.dataset.reason }),
+        });
+        if (!res.ok && res.status !== 409) {
+          throw new Error("Failed to report comment");
+        }
+        if (form) {
+          form.innerHTML =
+            '<p class="comment-report-title">Спасибо! Жалоба отправлена модераторам.</p>';
+        }
+        item
+          ?.querySelector(`[data-report="${CSS.escape(commentId)}"]`)
+          ?.remove();
+      } catch (err) {
+        console.error("Failed to report comment", err);
+        alert("Не удалось отправить жалобу. Попробуйте ещё раз.");
+        slot.innerHTML = "";
+      }
+    });
+  });
+}
+
+const HIDE_KINDS: Record<BlockKind, string> = {
+  mute: "Не показывать комментарии",
+  block: "Заблокировать",
+};
+
+function openHideForm(
+  container: HTMLElement,
+  commentId: string,
+  authorId: string,
+): void {
+  container.querySelectorAll(".comment-report-form").forEach((el) => el.remove());
+
+  const item = container.querySelector(`#comment-${CSS.escape(commentId)}`);
+  const slot = item?.parentElement?.querySelector(
+    ":scope > .comment-reply-form-slot",
+  );
+  if (!slot) return;
+
+  const kinds = Object.entries(HIDE_KINDS)
+    .map(
+      ([value, label]) =>
+        `<button class="history-clear" type="button" data-kind="${value}">${label}</button>`,
+    )
+    .join("");
+
+  slot.innerHTML = `
+    <div class="comment-form comment-report-form">
+      <p class="comment-report-title">Скрыть комментарии этого пользователя? Заблокированный также не сможет отвечать вам.</p>
+      <div class="comment-form-footer">
+        ${kinds}
+        <button class="history-clear comment-report-cancel" type="button">Отмена</button>
+      </div>
+    </div>
+  `;
+
+  slot
+    .querySelector(".comment-report-cancel")
+    ?.addEventListener("click", () => {
+      slot.innerHTML = "";
+    });
+
+  slot.querySelectorAll<HTMLButtonElement>("[data-kind]").forEach((btn) => {
+    btn.addEventListener("click", async () => {
+      slot
+        .querySelectorAll<HTMLButtonElement>("button")
+        .forEach((b) => (b.disabled = true));
+
+      const kind = btn.dataset.kind as BlockKind;
+      if (!(await profileManager.blockUser(authorId, kind))) {
+        alert("Не удалось скрыть пользователя. Попробуйте ещё раз.");
+        slot.innerHTML = "";
+        return;
+      }
+
+      const chapterId = container.dataset.chapterId;
+      if (!chapterId) return;
+      const page = parseInt(container.dataset.page || "1", 10);
+      await loadComments(container, chapterId, page);
+    });
+  });
+}
+
+let linkedCommentHandled = false;
+
+function scrollToLinkedComment(container: HTMLElement): void {
+  if (linkedCommentHandled) return;
+  const hash = window.location.hash;
+  if (!hash.startsWith("#comment-")) return;
+
+  const target = container.querySelector(`#${CSS.escape(hash.slice(1))}`);
+  if (!target) return;
+
+  linkedCommentHandled = true;
+  target.classList.add("comment-highlight");
+  target.scrollIntoView({ behavior: "smooth", block: "center" });
+}
+
 function initFormHandlers(container: HTMLElement): void {
   const textarea = container.querySelector(
     "#comment-textarea",
@@ -476,81 +1201,9 @@ function initFormHandlers(container: HTMLElement): void {
 
   if (submitBtn && textarea) {
     submitBtn.addEventListener("click", async () => {
-      const content = textarea.value.trim();
-      if (!content) return;
-      if (content.length > 1000) {
-        alert("Комментарий слишком длинный (максимум 1000 символов)");
-        return;
-      }
-
-      if (!profileManager.isLoggedIn()) {
-        alert("Войдите в аккаунт, чтобы оставить комментарий");
-        return;
-      }
-
-      submitBtn.disabled = true;
-      submitBtn.textContent = "Проверка...";
-
-      const token = await getTurnstileToken();
-      if (!token) {
-        alert("Не удалось пройти проверку. Попробуйте ещё раз.");
-        submitBtn.disabled = false;
-        submitBtn.textContent = "Отправить";
-        return;
-      }
-
-      submitBtn.textContent = "Отправка...";
-
-      try {
-        const profileId = profileManager.getProfileId();
-        const secretToken = localStorage.getItem("kappalib_secret_token");
-
-        const res = await fetch(`${API_URL}/chapters/${chapterId}/comments`, {
-          method: "POST",
-          headers: {
-            "Content-Type": "application/json",
-            "X-Profile-ID": profileId || "",
-            "X-Secret-Token": secretToken || "",
-          },
-          body: JSON.stringify({
-            content: content,
-            turnstile_token: token,
-          }),
-        });
-
-        if (!res.ok) {
-          const err = await res.json();
-          throw new Error(err.detail || "Failed to create comment");
-        }
-
-        const comment: Comment = await res.json();
-
-        addPendingComment({
-          id: comment.id,
-          visibleId: comment.user_id,
-          chapterId: comment.chapter_id,
-          contentHtml: comment.content_html,
-          createdAt: Date.now(),
-          userDisplayName: comment.user_display_name,
-          userAvatarSeed: comment.user_avatar_seed,
-          userHasCustomAvatar: comment.user_has_custom_avatar,
-        });
-
-        textarea.value = "";
+      if (await submitComment(container, chapterId, textarea, submitBtn, null)) {
         updateCharCounter(textarea);
         autoResizeTextarea(textarea);
-
-        if (turnstileWidgetId) {
-          (window as any).turnstile.reset(turnstileWidgetId);
-        }
-
-        await loadComments(container, chapterId);
-      } catch (err) {
-        console.error("Failed to submit comment", err);
-        alert("Не удалось отправить комментарий. Попробуйте ещё раз.");
-      } finally {
-        submitBtn.disabled = false;
-        submitBtn.textContent = "Отправить";
       }
     });
   }
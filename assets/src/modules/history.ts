@@ -0,0 +1,192 @@
+import { profileManager } from "./profile";
+
+const API_URL = process.env.API_URL;
+const RECORD_DELAY = 5000;
+
+const COVER_PLACEHOLDER =
+  "data:image/svg+xml,%3Csvg xmlns='http://www.w3.org/2000/svg' width='200' height='300'%3E%3Crect fill='%23ecf0f1' width='200' height='300'/%3E%3C/svg%3E";
+
+interface HistoryEntry {
+  id: number;
+  novel_id: string;
+  novel_title: string;
+  novel_cover_url: string | null;
+  chapter_id: string;
+  chapter_num: number;
+  chapter_title: string;
+  read_at: string;
+}
+
+interface HistoryPage {
+  entries: HistoryEntry[];
+  page: number;
+  page_size: number;
+  has_more: boolean;
+}
+
+function authHeaders(): Record<string, string> {
+  return {
+    "X-Profile-ID": profileManager.getProfileId() || "",
+    "X-Secret-Token": profileManager.getSecretToken() || "",
+  };
+}
+
+function escapeHtml(text: string): string {
+  const div = document.createElement("div");
+  div.textContent = text;
+  return div.innerHTML;
+}
+
+function moscowDate(date: Date): string {
+  return date.toLocaleDateString("en-CA", { timeZone: "Europe/Moscow" });
+}
+
+function formatDayLabel(day: string): string {
+  const now = new Date();
+  if (day === moscowDate(now)) return "Сегодня";
+  if (day === moscowDate(new Date(now.getTime() - 86400000))) return "Вчера";
+  return new Date(`${day}T12:00:00+03:00`).toLocaleDateString("ru-RU", {
+    day: "numeric",
+    month: "long",
+    year: "numeric",
+    timeZone: "Europe/Moscow",
+  });
+}
+
+function formatTime(date: Date): string {
+  return date.toLocaleTimeString("ru-RU", {
+    hour: "2-digit",
+    minute: "2-digit",
+    timeZone: "Europe/Moscow",
+  });
+}
+
+function chapterLabel(entry: HistoryEntry): string {
+  if (entry.chapter_title && entry.chapter_title !== "Без названия") {
+    return `Глава ${entry.chapter_num}: ${entry.chapter_title}`;
+  }
+  return `Глава ${entry.chapter_num}`;
+}
+
+function renderEntry(entry: HistoryEntry): string {
+  const cover = entry.novel_cover_url || COVER_PLACEHOLDER;
+  return `
+    <a href="/${entry.novel_id}/chapter/${entry.chapter_id}" class="update-item">
+      <img src="${cover}" alt="${escapeHtml(entry.novel_title)}" class="update-cover" loading="lazy"/>
+      <div class="update-info">
+        <span class="update-title">${escapeHtml(entry.novel_title)}</span>
+        <span class="update-chapters">${escapeHtml(chapterLabel(entry))}</span>
+      </div>
+      <span class="update-date">${formatTime(new Date(entry.read_at))}</span>
+    </a>`;
+}
+
+function appendEntries(list: HTMLElement, entries: HistoryEntry[]): void {
+  entries.forEach((entry) => {
+    const day = moscowDate(new Date(entry.read_at));
+    let group = list.querySelector<HTMLElement>(
+      `.updates-day[data-day="${day}"]`,
+    );
+    if (!group) {
+      group = document.createElement("div");
+      group.className = "updates-day";
+      group.dataset.day = day;
+      group.innerHTML = `<div class="updates-day-label">${formatDayLabel(day)}</div>`;
+      list.appendChild(group);
+    }
+    group.insertAdjacentHTML("beforeend", renderEntry(entry));
+  });
+}
+
+async function fetchHistory(page: number): Promise<HistoryPage | null> {
+  try {
+    const res = await fetch(`${API_URL}/history?page=${page}`, {
+      headers: authHeaders(),
+    });
+    if (!res.ok) return null;
+    return await res.json();
+  } catch (err) {
+    console.error("Failed to fetch history", err);
+    return null;
+  }
+}
+
+export function initHistoryPage(): void {
+  const container = document.getElementById("history-content");
+  const moreBtn = document.getElementById("history-more");
+  const clearBtn = document.getElementById("history-clear");
+  if (!container || !moreBtn || !clearBtn) return;
+
+  if (!profileManager.isLoggedIn()) {
+    container.innerHTML = `<div class="no-results">Войдите в профиль, чтобы видеть историю чтения.</div>`;
+    return;
+  }
+
+  let page = 1;
+  const list = document.createElement("div");
+  list.className = "updates-list";
+
+  const load = async (): Promise<void> => {
+    moreBtn.setAttribute("disabled", "true");
+    const history = await fetchHistory(page);
+    moreBtn.removeAttribute("disabled");
+
+    if (!history) {
+      if (page === 1) {
+        container.innerHTML = `<div class="no-results">Не удалось загрузить историю.</div>`;
+      }
+      return;
+    }
+
+    if (page === 1) {
+      if (history.entries.length === 0) {
+        container.innerHTML = `<div class="no-results">История пуста.</div>`;
+        return;
+      }
+      container.innerHTML = "";
+      container.appendChild(list);
+      clearBtn.style.display = "";
+    }
+
+    appendEntries(list, history.entries);
+    moreBtn.style.display = history.has_more ? "" : "none";
+    page++;
+  };
+
+  moreBtn.addEventListener("click", () => load());
+
+  clearBtn.addEventListener("click", async () => {
+    if (!confirm("Очистить всю историю чтения?")) return;
+    try {
+      const res = await fetch(`${API_URL}/history`, {
+        method: "DELETE",
+        headers: authHeaders(),
+      });
+      if (res.ok) {
+        container.innerHTML = `<div class="no-results">История пуста.</div>`;
+        clearBtn.style.display = "none";
+        moreBtn.style.display = "none";
+      }
+    } catch (err) {
+      console.error("Failed to clear history", err);
+    }
+  });
+
+  load();
+}
+
+export function initHistoryRecorder(): void {
+  const tracker = document.getElementById("reading-tracker");
+  if (!tracker || !profileManager.isLoggedIn()) return;
+
+  const chapterId = tracker.dataset.chapterId;
+  if (!chapterId) return;
+
+  window.setTimeout(() => {
+    fetch(`${API_URL}/history`, {
+      method: "POST",
+      headers: { ...authHeaders(), "Content-Type": "application/json" },
+      body: JSON.stringify({ chapter_id: chapterId }),
+    }).catch((err) => console.error("Failed to record history", err));
+  }, RECORD_DELAY);
+}
@@ -0,0 +1,204 @@
+import { profileManager } from "./profile";
+
+const API_URL = process.env.API_URL;
+
+const SHELF_LABELS: Record<string, string> = {
+  reading: "Читаю",
+  planned: "В планах",
+  completed: "Прочитано",
+  dropped: "Брошено",
+};
+
+interface LibraryNovel {
+  title: string;
+  title_en: string;
+  author: string;
+  cover_url: string | null;
+  chapters_count: number;
+}
+
+interface LibraryEntry {
+  novel_id: string;
+  shelf: string;
+  rating?: number | null;
+  created_at: string;
+  updated_at: string;
+  novel?: LibraryNovel;
+}
+
+interface Library {
+  entries: LibraryEntry[];
+  counts: Record<string, number>;
+}
+
+function authHeaders(): Record<string, string> {
+  return {
+    "X-Profile-ID": profileManager.getProfileId() || "",
+    "X-Secret-Token": profileManager.getSecretToken() || "",
+  };
+}
+
+async function fetchLibrary(shelf: string): Promise<Library | null> {
+  try {
+    const res = await fetch(
+      `${API_URL}/library?shelf=${encodeURIComponent(shelf)}`,
+      { headers: authHeaders() },
+    );
+    if (!res.ok) return null;
+    return await res.json();
+  } catch (err) {
+    console.error("Failed to fetch library", err);
+    return null;
+  }
+}
+
+function escapeHtml(text: string): string {
+  const div = document.createElement("div");
+  div.textContent = text;
+  return div.innerHTML;
+}
+
+const COVER_PLACEHOLDER =
+  "data:image/svg+xml,%3Csvg xmlns='http://www.w3.org/2000/svg' width='200' height='300'%3E%3Crect fill='%23ecf0f1' width='200' height='300'/%3E%3C/svg%3E";
+
+function resolveCover(url: string | null): string {
+  return url || COVER_PLACEHOLDER;
+}
+
+function renderEntries(container: HTMLElement, entries: LibraryEntry[]): void {
+  if (entries.length === 0) {
+    container.innerHTML = `<div class="no-results">На этой полке пока пусто.</div>`;
+    return;
+  }
+
+  container.innerHTML = `<div class="novels-grid">${entries
+    .map((e) => {
+      const novel = e.novel;
+      if (!novel) return "";
+      const rating = e.rating
+        ? `<span class="library-rating">★ ${e.rating}</span>`
+        : "";
+      return `
+        <a href="/${e.novel_id}" class="novel-card">
+          <div class="poster-wrapper" style="--bg-url: url(&quot;${resolveCover(novel.cover_url)}&quot;)">
+            <img src="${resolveCover(novel.cover_url)}" alt="${escapeHtml(novel.title)}" loading="lazy"/>
+          </div>
+          <div class="novel-card-info">
+            <h3>${escapeHtml(novel.title)}</h3>
+            <p class="author">${escapeHtml(novel.author)}</p>
+            ${rating}
+          </div>
+        </a>`;
+    })
+    .join("")}</div>`;
+}
+
+function updateCounts(counts: Record<string, number>): void {
+  document
+    .querySelectorAll<HTMLElement>(".library-tab-count")
+    .forEach((el) => {
+      const shelf = el.dataset.countFor || "";
+      const count = counts[shelf] || 0;
+      el.textContent = count > 0 ? String(count) : "";
+    });
+}
+
+async function loadShelf(container: HTMLElement, shelf: string): Promise<void> {
+  container.dataset.shelf = shelf;
+  container.innerHTML = `<div class="no-results">Загрузка...</div>`;
+
+  const library = await fetchLibrary(shelf);
+  if (container.dataset.shelf !== shelf) return;
+
+  if (!library) {
+    container.innerHTML = `<div class="no-results">Не удалось загрузить библиотеку.</div>`;
+    return;
+  }
+
+  updateCounts(library.counts);
+  renderEntries(container, library.entries);
+}
+
+export function initLibraryPage(): void {
+  const container = document.getElementById("library-content");
+  const tabs = document.getElementById("library-tabs");
+  if (!container || !tabs) return;
+
+  if (!profileManager.isLoggedIn()) {
+    container.innerHTML = `<div class="no-results">Войдите в профиль, чтобы вести библиотеку.</div>`;
+    return;
+  }
+
+  tabs.querySelectorAll<HTMLElement>(".library-tab").forEach((tab) => {
+    tab.addEventListener("click", () => {
+      const shelf = tab.dataset.shelf || "reading";
+      tabs.querySelectorAll<HTMLElement>(".library-tab").forEach((t) => {
+        t.classList.toggle("active", t === tab);
+        t.setAttribute("aria-selected", String(t === tab));
+      });
+      history.replaceState(null, "", `/library?shelf=${shelf}`);
+      loadShelf(container, shelf);
+    });
+  });
+
+  loadShelf(container, container.dataset.shelf || "reading");
+}
+
+function setControlState(control: HTMLElement, shelf: string | null): void {
+  const label = control.querySelector<HTMLElement>(".js-dropdown-label");
+  if (label) {
+    label.innerText = shelf
+      ? SHELF_LABELS[shelf] || shelf
+      : "Добавить в библиотеку";
+  }
+  control.classList.toggle("in-library", shelf !== null);
+  control.querySelectorAll<HTMLElement>(".dropdown-item").forEach((item) => {
+    const selected = item.dataset.value === shelf;
+    item.classList.toggle("selected", selected);
+    item.setAttribute("aria-selected", String(selected));
+  });
+}
+
+export function initLibraryButton(): void {
+  const control = document.getElementById("library-control");
+  if (!control || !profileManager.isLoggedIn()) return;
+
+  const novelId = control.dataset.novelId;
+  if (!novelId) return;
+
+  let current: string | null = null;
+  control.style.display = "";
+
+  fetch(`${API_URL}/library/${novelId}`, { headers: authHeaders() })
+    .then((res) => (res.ok ? res.json() : null))
+    .then((entry: LibraryEntry | null) => {
+      current = entry ? entry.shelf : null;
+      setControlState(control, current);
+    })
+    .catch((err) => console.error("Failed to fetch library entry", err));
+
+  control.addEventListener("change", async (e: Event) => {
+    const value = (e as CustomEvent<{ value: string }>).detail.value;
+
+    try {
+      if (value === "none") {
+        const res = await fetch(`${API_URL}/library/${novelId}`, {
+          method: "DELETE",
+          headers: authHeaders(),
+        });
+        if (res.ok || res.status === 404) current = null;
+      } else {
+        const res = await fetch(`${API_URL}/library/${novelId}`, {
+          method: "PUT",
+          headers: { ...authHeaders(), "Content-Type": "application/json" },
+          body: JSON.stringify({ shelf: value }),
+        });
+        if (res.ok) current = value;
+      }
+    } catch (err) {
+      console.error("Failed to update library entry", err);
+    }
+
+    setControlState(control, current);
+  });
+}
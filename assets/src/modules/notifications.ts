@@ -0,0 +1,268 @@
+import { profileManager } from "./profile";
+
+const API_URL = process.env.API_URL;
+const UNREAD_POLL_INTERVAL = 5 * 60 * 1000;
+
+const COVER_PLACEHOLDER =
+  "data:image/svg+xml,%3Csvg xmlns='http://www.w3.org/2000/svg' width='200' height='300'%3E%3Crect fill='%23ecf0f1' width='200' height='300'/%3E%3C/svg%3E";
+
+interface Notification {
+  id: number;
+  type: string;
+  novel_id: string;
+  novel_title: string;
+  novel_cover_url: string | null;
+  chapter_id: string | null;
+  first_chapter_num: number;
+  last_chapter_num: number;
+  chapters_count: number;
+  comment_id: string | null;
+  actor_name: string | null;
+  is_read: boolean;
+  created_at: string;
+  updated_at: string;
+}
+
+interface NotificationsPage {
+  notifications: Notification[];
+  page: number;
+  page_size: number;
+  has_more: boolean;
+  unread_count: number;
+}
+
+interface FollowStatus {
+  novel_id: string;
+  following: boolean;
+}
+
+function authHeaders(): Record<string, string> {
+  return {
+    "X-Profile-ID": profileManager.getProfileId() || "",
+    "X-Secret-Token": profileManager.getSecretToken() || "",
+  };
+}
+
+function escapeHtml(text: string): string {
+  const div = document.createElement("div");
+  div.textContent = text;
+  return div.innerHTML;
+}
+
+function setUnreadBadge(count: number): void {
+  const badge = document.getElementById("notifications-badge");
+  if (!badge) return;
+  if (count > 0) {
+    badge.textContent = count > 99 ? "99+" : String(count);
+    badge.style.display = "";
+  } else {
+    badge.style.display = "none";
+  }
+}
+
+async function refreshUnreadCount(): Promise<void> {
+  try {
+    const res = await fetch(`${API_URL}/notifications/unread-count`, {
+      headers: authHeaders(),
+    });
+    if (!res.ok) return;
+    const data: { unread_count: number } = await res.json();
+    setUnreadBadge(data.unread_count);
+  } catch (err) {
+    console.error("Failed to fetch unread notifications", err);
+  }
+}
+
+async function markRead(ids: number[]): Promise<void> {
+  try {
+    const res = await fetch(`${API_URL}/notifications/read`, {
+      method: "POST",
+      keepalive: true,
+      headers: { ...authHeaders(), "Content-Type": "application/json" },
+      body: JSON.stringify({ ids }),
+    });
+    if (res.ok) {
+      const data: { unread_count: number } = await res.json();
+      setUnreadBadge(data.unread_count);
+    }
+  } catch (err) {
+    console.error("Failed to mark notifications as read", err);
+  }
+}
+
+function formatChapterRange(first: number, last: number): string {
+  if (first === last) return `Новая глава ${first}`;
+  return `Новые главы ${first}–${last}`;
+}
+
+function formatRelative(date: Date): string {
+  const diff = Math.floor((Date.now() - date.getTime()) / 1000);
+  if (diff < 60) return "только что";
+  if (diff < 3600) return `${Math.floor(diff / 60)} мин. назад`;
+  if (diff < 86400) return `${Math.floor(diff / 3600)} ч. назад`;
+  return date.toLocaleDateString("ru-RU", {
+    day: "numeric",
+    month: "long",
+    timeZone: "Europe/Moscow",
+  });
+}
+
+function notificationLink(n: Notification): string {
+  if (n.type === "comment_reply" && n.chapter_id && n.comment_id) {
+    return `/${n.novel_id}/chapter/${n.chapter_id}#comment-${n.comment_id}`;
+  }
+  if (n.chapter_id) return `/${n.novel_id}/chapter/${n.chapter_id}`;
+  return `/${n.novel_id}`;
+}
+
+function notificationText(n: Notification): string {
+  if (n.type === "comment_reply") {
+    return `${n.actor_name || "Кто-то"} ответил(а) на ваш комментарий`;
+  }
+  return formatChapterRange(n.first_chapter_num, n.last_chapter_num);
+}
+
+function renderNotification(n: Notification): string {
+  const cover = n.novel_cover_url || COVER_PLACEHOLDER;
+  return `
+    <a href="${notificationLink(n)}" class="update-item notification-item${n.is_read ? "" : " unread"}" data-id="${n.id}">
+      <img src="${cover}" alt="${escapeHtml(n.novel_title)}" class="update-cover" loading="lazy"/>
+      <div class="update-info">
+        <span class="update-title">${escapeHtml(n.novel_title)}</span>
+        <span class="update-chapters">${escapeHtml(notificationText(n))}</span>
+      </div>
+      <span class="update-date">${formatRelative(new Date(n.updated_at))}</span>
+    </a>`;
+}
+
+export function initNotificationsBell(): void {
+  const bell = document.getElementById("header-notifications-btn");
+  if (!bell || !profileManager.isLoggedIn()) return;
+
+  bell.style.display = "";
+  refreshUnreadCount();
+  window.setInterval(() => {
+    if (document.visibilityState === "visible") refreshUnreadCount();
+  }, UNREAD_POLL_INTERVAL);
+}
+
+export function initFollowButton(): void {
+  const btn = document.getElementById("follow-btn") as HTMLButtonElement | null;
+  if (!btn || !profileManager.isLoggedIn()) return;
+
+  const novelId = btn.dataset.novelId;
+  if (!novelId) return;
+
+  const url = `${API_URL}/novels/${novelId}/follow`;
+  let following = false;
+
+  const render = (): void => {
+    btn.textContent = following ? "Вы следите" : "Следить";
+    btn.classList.toggle("following", following);
+  };
+
+  fetch(url, { headers: authHeaders() })
+    .then((res) => (res.ok ? res.json() : null))
+    .then((status: FollowStatus | null) => {
+      if (!status) return;
+      following = status.following;
+      render();
+      btn.style.display = "";
+    })
+    .catch((err) => console.error("Failed to fetch follow status", err));
+
+  btn.addEventListener("click", async () => {
+    btn.disabled = true;
+    try {
+      const res = await fetch(url, {
+        method: following ? "DELETE" : "PUT",
+        headers: authHeaders(),
+      });
+      if (res.ok) {
+        const status: FollowStatus = await res.json();
+        following = status.following;
+        render();
+      }
+    } catch (err) {
+      console.error("Failed to toggle follow", err);
+    }
+    btn.disabled = false;
+  });
+}
+
+export function initNotificationsPage(): void {
+  const container = document.getElementById("notifications-content");
+  const moreBtn = document.getElementById("notifications-more");
+  const readAllBtn = document.getElementById("notifications-read-all");
+  if (!container || !moreBtn || !readAllBtn) return;
+
+  if (!profileManager.isLoggedIn()) {
+    container.innerHTML = `<div class="no-results">Войдите в профиль, чтобы получать уведомления.</div>`;
+    return;
+  }
+
+  let page = 1;
+  const list = document.createElement("div");
+  list.className = "updates-list";
+
+  const load = async (): Promise<void> => {
+    let data: NotificationsPage | null = null;
+    try {
+      const res = await fetch(`${API_URL}/notifications?page=${page}`, {
+        headers: authHeaders(),
+      });
+      if (res.ok) data = await res.json();
+    } catch (err) {
+      console.error("Failed to fetch notifications", err);
+    }
+
+    if (!data) {
+      if (page === 1) {
+        container.innerHTML = `<div class="no-results">Не удалось загрузить уведомления.</div>`;
+      }
+      return;
+    }
+
+    if (page === 1) {
+      if (data.notifications.length === 0) {
+        container.innerHTML = `<div class="no-results">Уведомлений пока нет. Нажмите «Следить» на странице новеллы, чтобы узнавать о новых главах.</div>`;
+        return;
+      }
+      container.innerHTML = "";
+      container.appendChild(list);
+    }
+
+    setUnreadBadge(data.unread_count);
+    readAllBtn.style.display = data.unread_count > 0 ? "" : "none";
+    list.insertAdjacentHTML(
+      "beforeend",
+      data.notifications.map(renderNotification).join(""),
+    );
+    moreBtn.style.display = data.has_more ? "" : "none";
+    page++;
+  };
+
+  list.addEventListener("click", (e: Event) => {
+    const item = (e.target as HTMLElement).closest<HTMLElement>(
+      ".notification-item.unread",
+    );
+    if (!item) return;
+    const id = parseInt(item.dataset.id || "0", 10);
+    if (id > 0) {
+      item.classList.remove("unread");
+      markRead([id]);
+    }
+  });
+
+  moreBtn.addEventListener("click", () => load());
+
+  readAllBtn.addEventListener("click", async () => {
+    await markRead([]);
+    list
+      .querySelectorAll(".notification-item.unread")
+      .forEach((el) => el.classList.remove("unread"));
+    readAllBtn.style.display = "none";
+  });
+
+  load();
+}
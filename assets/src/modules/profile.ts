@@ -1,6 +1,9 @@
 const API_URL = process.env.API_URL;
 const PROFILE_ID_KEY = "kappalib_profile_id";
 const SECRET_TOKEN_KEY = "kappalib_secret_token";
+const LEGACY_SESSION_COOKIE_KEY = "kl_session";
+const READER_COOKIE_ISSUED_KEY = "kl_reader_cookie_issued_at";
+const READER_COOKIE_REFRESH_MS = 30 * 24 * 60 * 60 * 1000;
 const TURNSTILE_SITE_KEY = process.env.TURNSTILE_SITE_KEY || "";
 const S3_URL = `${process.env.S3_USE_SSL !== "false" ? "https" : "http"}://${process.env.S3_ENDPOINT}/${process.env.S3_BUCKET}`;
 
@@ -14,6 +17,8 @@ interface ProfilePublic {
   display_name: string;
   avatar_seed: string;
   has_custom_avatar: boolean;
+  avatar_status?: "pending" | "rejected";
+  is_public: boolean;
   created_at: string;
 }
 
@@ -21,19 +26,73 @@ interface ProfileWithToken extends ProfilePublic {
   secret_token: string;
 }
 
+interface Session {
+  id: string;
+  device_label: string;
+  created_at: string;
+  last_seen_at: string;
+  current: boolean;
+}
+
+export type BlockKind = "mute" | "block";
+
+interface BlockedUser {
+  user_id: string;
+  display_name: string;
+  avatar_seed: string;
+  has_custom_avatar: boolean;
+  kind: BlockKind;
+  created_at: string;
+}
+
 interface LoginResponse {
-  profile: ProfilePublic;
-  secret_token: string;
+  status: "ok" | "pending";
+  profile?: ProfilePublic;
+  secret_token?: string;
+  cookies?: Record<string, CookieValue>;
+  request_id?: string;
+  poll_token?: string;
+}
+
+interface LoginRequest {
+  id: string;
+  device_label: string;
+  ip: string;
+  created_at: string;
+  expires_at: string;
+}
+
+interface ProfileImportResult {
+  profile: ProfileWithToken;
   cookies: Record<string, CookieValue>;
+  library: number;
+  progress: number;
+  history: number;
+  follows: number;
+  ratings: number;
+  avatar: boolean;
 }
 
+type LoginResult =
+  | { ok: true; data: LoginResponse }
+  | { ok: false; status: number; message: string };
+
+const LOGIN_POLL_INTERVAL = 3000;
+const LOGIN_REQUESTS_POLL_INTERVAL = 5000;
+
+const AVATAR_MAX_SIZE = 5 * 1024 * 1024;
+
+type AvatarSize = 256 | 128 | 64;
+
 export function getAvatarUrl(
   userId: string,
   hasCustomAvatar: boolean,
   avatarSeed: string,
+  size: AvatarSize = 128,
 ): string {
   if (hasCustomAvatar) {
-    return `${S3_URL}/avatars/${userId}.jpg?v=${Date.now()}`;
+    const suffix = size === 256 ? "" : `_${size}`;
+    return `${S3_URL}/avatars/${userId}${suffix}.webp?v=${Date.now()}`;
   }
   return `https://api.dicebear.com/9.x/bottts-neutral/svg?seed=${avatarSeed}&backgroundType=solid,gradientLinear`;
 }
@@ -45,6 +104,41 @@ class ProfileManager {
   constructor() {
     this.profileId = localStorage.getItem(PROFILE_ID_KEY);
     this.secretToken = localStorage.getItem(SECRET_TOKEN_KEY);
+    document.cookie = `${LEGACY_SESSION_COOKIE_KEY}=; path=/; max-age=0; SameSite=Lax`;
+    if (this.isLoggedIn()) this.issueReaderCookie();
+  }
+
+  private saveCredentials(profileId: string, secretToken: string): void {
+    this.profileId = profileId;
+    this.secretToken = secretToken;
+    localStorage.setItem(PROFILE_ID_KEY, profileId);
+    localStorage.setItem(SECRET_TOKEN_KEY, secretToken);
+    this.issueReaderCookie(true);
+  }
+
+  private async issueReaderCookie(force: boolean = false): Promise<void> {
+    if (!this.profileId || !this.secretToken) return;
+    const issuedAt = parseInt(
+      localStorage.getItem(READER_COOKIE_ISSUED_KEY) || "0",
+      10,
+    );
+    if (!force && Date.now() - issuedAt < READER_COOKIE_REFRESH_MS) return;
+    try {
+      const res = await fetch(
+        `${API_URL}/profile/${this.profileId}/reader-settings/cookie`,
+        {
+          method: "PUT",
+          headers: { "X-Secret-Token": this.secretToken },
+        },
+      );
+      if (res.ok) {
+        localStorage.setItem(READER_COOKIE_ISSUED_KEY, String(Date.now()));
+      } else if (res.status === 403) {
+        this.logout();
+      }
+    } catch (err) {
+      console.error("Issue reader settings cookie failed", err);
+    }
   }
 
   isLoggedIn(): boolean {
@@ -59,11 +153,13 @@ class ProfileManager {
     return this.secretToken;
   }
 
-  getAvatarUrl(profile: ProfilePublic): string {
-    if (profile.has_custom_avatar) {
-      return `${S3_URL}/avatars/${profile.id}.jpg?v=${Date.now()}`;
-    }
-    return `https://api.dicebear.com/9.x/bottts-neutral/svg?seed=${profile.avatar_seed}&backgroundType=solid,gradientLinear`;
+  getAvatarUrl(profile: ProfilePublic, size: AvatarSize = 128): string {
+    return getAvatarUrl(
+      profile.id,
+      profile.has_custom_avatar,
+      profile.avatar_seed,
+      size,
+    );
   }
 
   async createProfile(turnstileToken: string): Promise<ProfilePublic | null> {
@@ -75,10 +171,7 @@ class ProfileManager {
       });
       if (res.ok) {
         const data: ProfileWithToken = await res.json();
-        this.profileId = data.id;
-        this.secretToken = data.secret_token;
-        localStorage.setItem(PROFILE_ID_KEY, data.id);
-        localStorage.setItem(SECRET_TOKEN_KEY, data.secret_token);
+        this.saveCredentials(data.id, data.secret_token);
         this.syncCookiesToServer();
         return data;
       }
@@ -88,28 +181,90 @@ class ProfileManager {
     return null;
   }
 
-  async login(syncCode: string): Promise<LoginResponse | null> {
+  async login(syncCode: string): Promise<LoginResult> {
     try {
       const res = await fetch(`${API_URL}/profile/login`, {
         method: "POST",
         headers: { "Content-Type": "application/json" },
         body: JSON.stringify({ sync_code: syncCode }),
       });
-      if (res.ok) {
-        const data: LoginResponse = await res.json();
-        this.profileId = data.profile.id;
-        this.secretToken = data.secret_token;
-        localStorage.setItem(PROFILE_ID_KEY, data.profile.id);
-        localStorage.setItem(SECRET_TOKEN_KEY, data.secret_token);
-        this.applyCookies(data.cookies);
-        return data;
-      }
+      return await this.handleLoginResponse(res);
     } catch (err) {
       console.error("Login failed", err);
     }
+    return { ok: false, status: 0, message: "" };
+  }
+
+  async completeLogin(
+    requestId: string,
+    pollToken: string,
+  ): Promise<LoginResult> {
+    try {
+      const res = await fetch(`${API_URL}/profile/login/${requestId}`, {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ poll_token: pollToken }),
+      });
+      return await this.handleLoginResponse(res);
+    } catch (err) {
+      console.error("Complete login failed", err);
+    }
+    return { ok: false, status: 0, message: "" };
+  }
+
+  private async handleLoginResponse(res: Response): Promise<LoginResult> {
+    if (!res.ok) {
+      const body = await res.json().catch(() => null);
+      return { ok: false, status: res.status, message: body?.detail || "" };
+    }
+    const data: LoginResponse = await res.json();
+    if (data.status === "ok" && data.profile && data.secret_token) {
+      this.saveCredentials(data.profile.id, data.secret_token);
+      this.applyCookies(data.cookies || {});
+    }
+    return { ok: true, data };
+  }
+
+  async getLoginRequests(): Promise<LoginRequest[] | null> {
+    if (!this.profileId || !this.secretToken) return null;
+    try {
+      const res = await fetch(
+        `${API_URL}/profile/${this.profileId}/login-requests`,
+        { headers: { "X-Secret-Token": this.secretToken } },
+      );
+      if (res.ok) return await res.json();
+      if (res.status === 403) this.logout();
+    } catch (err) {
+      console.error("Get login requests failed", err);
+    }
     return null;
   }
 
+  async resolveLoginRequest(
+    requestId: string,
+    approve: boolean,
+  ): Promise<boolean> {
+    if (!this.profileId || !this.secretToken) return false;
+    try {
+      const res = await fetch(
+        `${API_URL}/profile/${this.profileId}/login-requests/${requestId}`,
+        {
+          method: "POST",
+          headers: {
+            "Content-Type": "application/json",
+            "X-Secret-Token": this.secretToken,
+          },
+          body: JSON.stringify({ approve }),
+        },
+      );
+      if (res.ok) return true;
+      if (res.status === 403) this.logout();
+    } catch (err) {
+      console.error("Resolve login request failed", err);
+    }
+    return false;
+  }
+
   async fetchProfile(): Promise<ProfilePublic | null> {
     if (!this.profileId) return null;
     try {
@@ -123,9 +278,10 @@ class ProfileManager {
     return null;
   }
 
-  async generateSyncCode(): Promise<{
+  async generateSyncCode(requireConfirmation: boolean): Promise<{
     sync_code: string;
     expires_at: string;
+    requires_confirmation: boolean;
   } | null> {
     if (!this.profileId || !this.secretToken) return null;
     try {
@@ -133,7 +289,13 @@ class ProfileManager {
         `${API_URL}/profile/${this.profileId}/sync-code`,
         {
           method: "POST",
-          headers: { "X-Secret-Token": this.secretToken },
+          headers: {
+            "Content-Type": "application/json",
+            "X-Secret-Token": this.secretToken,
+          },
+          body: JSON.stringify({
+            require_confirmation: requireConfirmation,
+          }),
         },
       );
       if (res.ok) return await res.json();
@@ -166,6 +328,107 @@ class ProfileManager {
     }
   }
 
+  async getSessions(): Promise<Session[] | null> {
+    if (!this.profileId || !this.secretToken) return null;
+    try {
+      const res = await fetch(`${API_URL}/profile/${this.profileId}/sessions`, {
+        headers: { "X-Secret-Token": this.secretToken },
+      });
+      if (res.ok) return await res.json();
+      if (res.status === 403) this.logout();
+    } catch (err) {
+      console.error("Fetch sessions failed", err);
+    }
+    return null;
+  }
+
+  async revokeSession(sessionId: string): Promise<boolean> {
+    if (!this.profileId || !this.secretToken) return false;
+    try {
+      const res = await fetch(
+        `${API_URL}/profile/${this.profileId}/sessions/${sessionId}`,
+        {
+          method: "DELETE",
+          headers: { "X-Secret-Token": this.secretToken },
+        },
+      );
+      if (res.status === 403) this.logout();
+      return res.ok;
+    } catch (err) {
+      console.error("Revoke session failed", err);
+    }
+    return false;
+  }
+
+  async revokeOtherSessions(): Promise<boolean> {
+    if (!this.profileId || !this.secretToken) return false;
+    try {
+      const res = await fetch(`${API_URL}/profile/${this.profileId}/sessions`, {
+        method: "DELETE",
+        headers: { "X-Secret-Token": this.secretToken },
+      });
+      if (res.status === 403) this.logout();
+      return res.ok;
+    } catch (err) {
+      console.error("Revoke sessions failed", err);
+    }
+    return false;
+  }
+
+  async getBlockedUsers(): Promise<BlockedUser[] | null> {
+    if (!this.profileId || !this.secretToken) return null;
+    try {
+      const res = await fetch(`${API_URL}/profile/${this.profileId}/blocks`, {
+        headers: { "X-Secret-Token": this.secretToken },
+      });
+      if (res.ok) return await res.json();
+      if (res.status === 403) this.logout();
+    } catch (err) {
+      console.error("Fetch blocked users failed", err);
+    }
+    return null;
+  }
+
+  async blockUser(userId: string, kind: BlockKind): Promise<boolean> {
+    if (!this.profileId || !this.secretToken) return false;
+    try {
+      const res = await fetch(
+        `${API_URL}/profile/${this.profileId}/blocks/${userId}`,
+        {
+          method: "PUT",
+          headers: {
+            "Content-Type": "application/json",
+            "X-Secret-Token": this.secretToken,
+          },
+          body: JSON.stringify({ kind }),
+        },
+      );
+      if (res.status === 403) this.logout();
+      return res.ok;
+    } catch (err) {
+      console.error("Block user failed", err);
+    }
+    return false;
+  }
+
+  async unblockUser(userId: string): Promise<boolean> {
+    if (!this.profileId || !this.secretToken) return false;
+    try {
+      const res = await fetch(
+        `${API_URL}/profile/${this.profileId}/blocks/${userId}`,
+        {
+          method: "DELETE",
+          headers: { "X-Secret-Token": this.secretToken },
+        },
+      );
+      if (res.status === 403) this.logout();
+      return res.ok || res.status === 404;
+    } catch (err) {
+      console.error("Unblock user failed", err);
+    }
+    return false;
+  }
+
   async updateDisplayName(newName: string): Promise<ProfilePublic | null> {
     if (!this.profileId || !this.secretToken) return null;
     try {
@@ -184,18 +447,34 @@ class ProfileManager {
     return null;
   }
 
-  async uploadAvatar(file: File): Promise<ProfilePublic | null> {
+  async updatePrivacy(isPublic: boolean): Promise<ProfilePublic | null> {
     if (!this.profileId || !this.secretToken) return null;
     try {
-      const base64 = await this.fileToBase64(file);
-
-      const res = await fetch(`${API_URL}/profile/${this.profileId}/avatar`, {
-        method: "POST",
+      const res = await fetch(`${API_URL}/profile/${this.profileId}/privacy`, {
+        method: "PATCH",
         headers: {
           "Content-Type": "application/json",
           "X-Secret-Token": this.secretToken,
         },
-        body: JSON.stringify({ image: base64 }),
+        body: JSON.stringify({ is_public: isPublic }),
+      });
+      if (res.ok) return await res.json();
+    } catch (err) {
+      console.error("Update privacy failed", err);
+    }
+    return null;
+  }
+
+  async uploadAvatar(file: File): Promise<ProfilePublic | null> {
+    if (!this.profileId || !this.secretToken) return null;
+    try {
+      const form = new FormData();
+      form.append("image", file);
+
+      const res = await fetch(`${API_URL}/profile/${this.profileId}/avatar`, {
+        method: "POST",
+        headers: { "X-Secret-Token": this.secretToken },
+        body: form,
       });
 
       if (res.ok) return await res.json();
@@ -211,6 +490,20 @@ class ProfileManager {
     return null;
   }
 
+  async removeAvatar(): Promise<ProfilePublic | null> {
+    if (!this.profileId || !this.secretToken) return null;
+    try {
+      const res = await fetch(`${API_URL}/profile/${this.profileId}/avatar`, {
+        method: "DELETE",
+        headers: { "X-Secret-Token": this.secretToken },
+      });
+      if (res.ok) return await res.json();
+    } catch (err) {
+      console.error("Remove avatar failed", err);
+    }
+    return null;
+  }
+
   private fileToBase64(file: File): Promise<string> {
     return new Promise((resolve, reject) => {
       const reader = new FileReader();
@@ -224,6 +517,68 @@ class ProfileManager {
     });
   }
 
+  async updateReaderSettings(settings: object): Promise<boolean> {
+    if (!this.profileId || !this.secretToken) return false;
+    try {
+      const res = await fetch(
+        `${API_URL}/profile/${this.profileId}/reader-settings`,
+        {
+          method: "PUT",
+          headers: {
+            "Content-Type": "application/json",
+            "X-Secret-Token": this.secretToken,
+          },
+          body: JSON.stringify(settings),
+        },
+      );
+      if (res.ok) return true;
+      if (res.status === 403) this.logout();
+    } catch (err) {
+      console.error("Update reader settings failed", err);
+    }
+    return false;
+  }
+
+  async exportProfile(format: "json" | "zip"): Promise<Blob | null> {
+    if (!this.profileId || !this.secretToken) return null;
+    const path = format === "zip" ? "export/archive" : "export";
+    try {
+      const res = await fetch(`${API_URL}/profile/${this.profileId}/${path}`, {
+        headers: { "X-Secret-Token": this.secretToken },
+      });
+      if (res.ok) return await res.blob();
+      if (res.status === 403) this.logout();
+    } catch (err) {
+      console.error("Export profile failed", err);
+    }
+    return null;
+  }
+
+  async importProfile(
+    file: File,
+    turnstileToken: string,
+  ): Promise<ProfileImportResult | string> {
+    try {
+      const archive = await this.fileToBase64(file);
+      const res = await fetch(`${API_URL}/profile/import`, {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ turnstile_token: turnstileToken, archive }),
+      });
+      if (res.ok) {
+        const data: ProfileImportResult = await res.json();
+        this.saveCredentials(data.profile.id, data.profile.secret_token);
+        this.applyCookies(data.cookies || {});
+        return data;
+      }
+      const error = await res.json().catch(() => null);
+      return error?.detail || "";
+    } catch (err) {
+      console.error("Import profile failed", err);
+    }
+    return "";
+  }
+
   async deleteProfile(): Promise<boolean> {
     if (!this.profileId || !this.secretToken) return false;
     try {
@@ -243,11 +598,17 @@ class ProfileManager {
   }
 
   logout(): void {
+    if (this.profileId) {
+      fetch(`${API_URL}/profile/${this.profileId}/reader-settings/cookie`, {
+        method: "DELETE",
+      }).catch(() => {});
+    }
     this.profileId = null;
     this.secretToken = null;
     localStorage.removeItem(PROFILE_ID_KEY);
     localStorage.removeItem(SECRET_TOKEN_KEY);
     localStorage.removeItem("kappalib_pending_comments");
+    localStorage.removeItem(READER_COOKIE_ISSUED_KEY);
   }
 
   private getKappalibCookies(): Record<string, CookieValue> {
@@ -448,6 +809,51 @@ function renderGuestView(): void {
     }
   });
 
+  const importInput = document.getElementById(
+    "pc-import-input",
+  ) as HTMLInputElement | null;
+
+  document.getElementById("pc-import")?.addEventListener("click", () => {
+    if (!(window as any).turnstileToken) {
+      showError("Дождитесь проверки на робота");
+      return;
+    }
+    importInput?.click();
+  });
+
+  importInput?.addEventListener("change", async () => {
+    const file = importInput.files?.[0];
+    importInput.value = "";
+    if (!file) return;
+
+    if (file.size > 10 * 1024 * 1024) {
+      showError("Файл слишком большой (макс. 10 МБ)");
+      return;
+    }
+
+    const token = (window as any).turnstileToken;
+    if (!token) return;
+
+    const btn = document.getElementById("pc-import") as HTMLButtonElement;
+    btn.disabled = true;
+    btn.textContent = "Восстановление...";
+    showError("");
+
+    const result = await profileManager.importProfile(file, token);
+    if (typeof result !== "string") {
+      renderLoggedInView();
+      return;
+    }
+
+    btn.disabled = false;
+    btn.textContent = "Восстановить из архива";
+    showError(
+      result === "Invalid archive" || result === "Unsupported archive version"
+        ? "Файл не похож на архив профиля"
+        : "Ошибка восстановления",
+    );
+  });
+
   document.getElementById("pc-login")?.addEventListener("click", async () => {
     const input = document.getElementById("pc-sync-input") as HTMLInputElement;
     const code = input.value.trim().toUpperCase();
@@ -458,13 +864,41 @@ function renderGuestView(): void {
 
     const btn = document.getElementById("pc-login") as HTMLButtonElement;
     btn.disabled = true;
+    showError("");
+
+    let result = await profileManager.login(code);
+    if (result.ok && result.data.status === "pending") {
+      const { request_id, poll_token } = result.data;
+      showError("Подтвердите вход на другом устройстве");
+      while (result.ok && result.data.status === "pending") {
+        await new Promise((r) => setTimeout(r, LOGIN_POLL_INTERVAL));
+        if (!document.getElementById("pc-login")) return;
+        result = await profileManager.completeLogin(request_id!, poll_token!);
+      }
+    }
 
-    const result = await profileManager.login(code);
-    if (result) {
+    if (result.ok) {
       renderLoggedInView();
-    } else {
-      btn.disabled = false;
-      showError("Неверный или просроченный код");
+      return;
+    }
+
+    btn.disabled = false;
+    switch (result.status) {
+      case 429:
+        showError("Слишком много попыток, попробуйте позже");
+        break;
+      case 403:
+        showError("Вход отклонён");
+        break;
+      case 404:
+        showError(
+          result.message === "Login request not found"
+            ? "Время подтверждения истекло"
+            : "Неверный или просроченный код",
+        );
+        break;
+      default:
+        showError("Ошибка входа");
     }
   });
 }
@@ -498,11 +932,35 @@ function renderLoggedInView(): void {
   });
 }
 
+function updateAvatarControls(profile: ProfilePublic): void {
+  const avatarRemove = document.getElementById("pc-avatar-remove");
+  if (avatarRemove) {
+    avatarRemove.style.display =
+      profile.has_custom_avatar || profile.avatar_status === "pending"
+        ? ""
+        : "none";
+  }
+
+  const avatarStatus = document.getElementById("pc-avatar-status");
+  if (avatarStatus) {
+    avatarStatus.textContent =
+      profile.avatar_status === "pending"
+        ? "Новый аватар появится после проверки модератором"
+        : profile.avatar_status === "rejected"
+          ? "Аватар отклонён модератором"
+          : "";
+    avatarStatus.style.display = avatarStatus.textContent ? "" : "none";
+  }
+}
+
 function initProfileInteractions(profile: any): void {
   const avatarWrapper = document.getElementById("pc-avatar-img")?.parentElement;
   const avatarInput = document.getElementById(
     "pc-avatar-input",
   ) as HTMLInputElement;
+  const avatarRemove = document.getElementById(
+    "pc-avatar-remove",
+  ) as HTMLButtonElement | null;
   const nameText = document.getElementById("pc-name-text");
   const nameEdit = document.getElementById("pc-name-edit");
   const nameInput = document.getElementById(
@@ -519,8 +977,8 @@ function initProfileInteractions(profile: any): void {
     const file = avatarInput.files?.[0];
     if (!file) return;
 
-    if (file.size > 1024 * 1024) {
-      alert("Файл слишком большой (максимум 1 МБ)");
+    if (file.size > AVATAR_MAX_SIZE) {
+      alert("Файл слишком большой (максимум 5 МБ)");
       avatarInput.value = "";
       return;
     }
@@ -541,6 +999,23 @@ function initProfileInteractions(profile: any): void {
     if (result && avatarImg) {
       avatarImg.src = profileManager.getAvatarUrl(result);
     }
+    if (result) updateAvatarControls(result);
+  });
+
+  updateAvatarControls(profile);
+
+  avatarRemove?.addEventListener("click", async () => {
+    if (!confirm("Удалить аватар?")) return;
+    avatarRemove.disabled = true;
+    const result = await profileManager.removeAvatar();
+    avatarRemove.disabled = false;
+    if (!result) return;
+
+    updateAvatarControls(result);
+    const avatarImg = document.getElementById(
+      "pc-avatar-img",
+    ) as HTMLImageElement | null;
+    if (avatarImg) avatarImg.src = profileManager.getAvatarUrl(result);
   });
 
   nameEdit?.addEventListener("click", () => {
@@ -603,6 +1078,39 @@ function initProfileInteractions(profile: any): void {
     nameEdit.style.display = "inline-flex";
   }
 
+  const publicLink = document.getElementById(
+    "pc-public-link",
+  ) as HTMLAnchorElement | null;
+  const publicToggle = document.getElementById(
+    "pc-public-toggle",
+  ) as HTMLInputElement | null;
+
+  if (publicLink) publicLink.href = `/user/${profile.id}`;
+  if (publicToggle) {
+    publicToggle.checked = profile.is_public;
+    publicToggle.addEventListener("change", async () => {
+      publicToggle.disabled = true;
+      const result = await profileManager.updatePrivacy(publicToggle.checked);
+      publicToggle.disabled = false;
+      if (result) {
+        profile.is_public = result.is_public;
+      }
+      publicToggle.checked = profile.is_public;
+    });
+  }
+
+  loadSessions();
+  loadBlockedUsers();
+
+  document
+    .getElementById("pc-revoke-others")
+    ?.addEventListener("click", async () => {
+      if (!confirm("Завершить все сеансы, кроме текущего?")) return;
+      if (await profileManager.revokeOtherSessions()) {
+        loadSessions();
+      }
+    });
+
   document
     .getElementById("pc-get-code")
     ?.addEventListener("click", async () => {
@@ -610,7 +1118,12 @@ function initProfileInteractions(profile: any): void {
       btn.disabled = true;
       btn.textContent = "Генерация...";
 
-      const result = await profileManager.generateSyncCode();
+      const confirmToggle = document.getElementById(
+        "pc-confirm-toggle",
+      ) as HTMLInputElement | null;
+      const result = await profileManager.generateSyncCode(
+        confirmToggle?.checked ?? false,
+      );
       if (result) {
         const area = document.getElementById("pc-code-area");
         if (area) {
@@ -620,12 +1133,38 @@ function initProfileInteractions(profile: any): void {
           area.appendChild(codeEl);
         }
         btn.style.display = "none";
+        const confirmWrap = document.getElementById("pc-confirm-wrap");
+        if (confirmWrap) confirmWrap.style.display = "none";
+        if (result.requires_confirmation) {
+          pollLoginRequests(new Date(result.expires_at).getTime());
+        }
       } else {
         btn.disabled = false;
         btn.textContent = "Получить код";
       }
     });
 
+  (["json", "zip"] as const).forEach((format) => {
+    const btn = document.getElementById(
+      `pc-export-${format}`,
+    ) as HTMLButtonElement | null;
+    btn?.addEventListener("click", async () => {
+      btn.disabled = true;
+      const blob = await profileManager.exportProfile(format);
+      btn.disabled = false;
+      if (!blob) {
+        if (!profileManager.isLoggedIn()) renderGuestView();
+        return;
+      }
+      const url = URL.createObjectURL(blob);
+      const link = document.createElement("a");
+      link.href = url;
+      link.download = `kappalib-${profileManager.getProfileId()}.${format}`;
+      link.click();
+      setTimeout(() => URL.revokeObjectURL(url), 1000);
+    });
+  });
+
   document.getElementById("pc-logout")?.addEventListener("click", () => {
     profileManager.logout();
     renderGuestView();
@@ -640,6 +1179,172 @@ function initProfileInteractions(profile: any): void {
   });
 }
 
+async function pollLoginRequests(codeExpiresAt: number): Promise<void> {
+  const list = document.getElementById("pc-login-requests");
+  if (!list) return;
+
+  const requests = await profileManager.getLoginRequests();
+  if (!profileManager.isLoggedIn()) {
+    renderGuestView();
+    return;
+  }
+
+  if (requests) {
+    list.innerHTML = "";
+    requests.forEach((request) => {
+      const row = document.createElement("div");
+      row.className = "pc-session";
+
+      const info = document.createElement("div");
+      info.className = "pc-session-info";
+      const label = document.createElement("span");
+      label.className = "pc-session-label";
+      label.textContent = `Вход: ${request.device_label}`;
+      const meta = document.createElement("span");
+      meta.className = "pc-session-meta";
+      meta.textContent = request.ip;
+      info.append(label, meta);
+
+      const actions = document.createElement("div");
+      actions.className = "pc-login-request-actions";
+      const approve = document.createElement("button");
+      approve.className = "pc-btn pc-btn-outline";
+      approve.textContent = "Разрешить";
+      const deny = document.createElement("button");
+      deny.className = "pc-btn pc-btn-danger-text";
+      deny.textContent = "Отклонить";
+      [approve, deny].forEach((btn) =>
+        btn.addEventListener("click", async () => {
+          approve.disabled = deny.disabled = true;
+          if (
+            await profileManager.resolveLoginRequest(
+              request.id,
+              btn === approve,
+            )
+          ) {
+            row.remove();
+            if (btn === approve) loadSessions();
+          } else {
+            approve.disabled = deny.disabled = false;
+          }
+        }),
+      );
+      actions.append(approve, deny);
+
+      row.append(info, actions);
+      list.appendChild(row);
+    });
+  }
+
+  const pending = requests?.length ?? 0;
+  if (Date.now() < codeExpiresAt || pending > 0) {
+    setTimeout(() => {
+      if (document.body.contains(list)) pollLoginRequests(codeExpiresAt);
+    }, LOGIN_REQUESTS_POLL_INTERVAL);
+  }
+}
+
+async function loadSessions(): Promise<void> {
+  const list = document.getElementById("pc-sessions");
+  const revokeOthers = document.getElementById("pc-revoke-others");
+  if (!list) return;
+
+  const sessions = await profileManager.getSessions();
+  if (!profileManager.isLoggedIn()) {
+    renderGuestView();
+    return;
+  }
+  if (!sessions) return;
+
+  list.innerHTML = "";
+  sessions.forEach((session) => {
+    const row = document.createElement("div");
+    row.className = "pc-session";
+
+    const info = document.createElement("div");
+    info.className = "pc-session-info";
+    const label = document.createElement("span");
+    label.className = "pc-session-label";
+    label.textContent = session.device_label;
+    const meta = document.createElement("span");
+    meta.className = "pc-session-meta";
+    meta.textContent = session.current
+      ? "Это устройство"
+      : `Активность: ${formatDate(session.last_seen_at)}`;
+    info.append(label, meta);
+    row.appendChild(info);
+
+    if (!session.current) {
+      const btn = document.createElement("button");
+      btn.className = "pc-btn pc-btn-danger-text";
+      btn.textContent = "Завершить";
+      btn.addEventListener("click", async () => {
+        btn.disabled = true;
+        if (await profileManager.revokeSession(session.id)) {
+          row.remove();
+          if (revokeOthers && list.children.length <= 1) {
+            revokeOthers.style.display = "none";
+          }
+        } else {
+          btn.disabled = false;
+        }
+      });
+      row.appendChild(btn);
+    }
+
+    list.appendChild(row);
+  });
+
+  if (revokeOthers) {
+    revokeOthers.style.display = sessions.length > 1 ? "" : "none";
+  }
+}
+
+async function loadBlockedUsers(): Promise<void> {
+  const section = document.getElementById("pc-blocks-section");
+  const list = document.getElementById("pc-blocks");
+  if (!section || !list) return;
+
+  const blocked = await profileManager.getBlockedUsers();
+  if (!blocked) return;
+
+  list.innerHTML = "";
+  blocked.forEach((user) => {
+    const row = document.createElement("div");
+    row.className = "pc-session";
+
+    const info = document.createElement("div");
+    info.className = "pc-session-info";
+    const label = document.createElement("a");
+    label.className = "pc-session-label";
+    label.href = `/user/${user.user_id}`;
+    label.textContent = user.display_name;
+    const meta = document.createElement("span");
+    meta.className = "pc-session-meta";
+    meta.textContent = `${user.kind === "block" ? "Заблокирован" : "Скрыт"} ${formatDate(user.created_at)}`;
+    info.append(label, meta);
+    row.appendChild(info);
+
+    const btn = document.createElement("button");
+    btn.className = "pc-btn pc-btn-danger-text";
+    btn.textContent = "Вернуть";
+    btn.addEventListener("click", async () => {
+      btn.disabled = true;
+      if (await profileManager.unblockUser(user.user_id)) {
+        row.remove();
+        if (list.children.length === 0) section.style.display = "none";
+      } else {
+        btn.disabled = false;
+      }
+    });
+    row.appendChild(btn);
+
+    list.appendChild(row);
+  });
+
+  section.style.display = blocked.length > 0 ? "" : "none";
+}
+
 function loadTurnstile(): void {
   const container = document.getElementById("turnstile-container");
   const createBtn = document.getElementById("pc-create") as HTMLButtonElement;
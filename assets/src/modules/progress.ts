@@ -1,4 +1,7 @@
-import { setKappalibCookie } from "./profile";
+import { profileManager, setKappalibCookie } from "./profile";
+
+const API_URL = process.env.API_URL;
+const POSITION_REPORT_INTERVAL = 15000;
 
 interface HistoryItem {
   id: string;
@@ -10,6 +13,169 @@ interface ProgressHistory {
   [novelId: string]: HistoryItem;
 }
 
+interface ServerProgress {
+  novel_id: string;
+  chapter_id: string;
+  chapter_num: number;
+  paragraph: number;
+  scroll_percent: number;
+  total_chapters: number;
+  updated_at: string;
+}
+
+function authHeaders(): Record<string, string> {
+  return {
+    "X-Profile-ID": profileManager.getProfileId() || "",
+    "X-Secret-Token": profileManager.getSecretToken() || "",
+  };
+}
+
+async function fetchServerProgress(
+  novelId: string,
+): Promise<ServerProgress | null> {
+  try {
+    const res = await fetch(`${API_URL}/progress/${novelId}`, {
+      headers: authHeaders(),
+    });
+    if (!res.ok) return null;
+    return await res.json();
+  } catch (err) {
+    console.error("Failed to fetch reading progress", err);
+    return null;
+  }
+}
+
+function reportPosition(
+  novelId: string,
+  chapterId: string,
+  paragraph: number,
+  scrollPercent: number,
+): void {
+  fetch(`${API_URL}/progress/${novelId}`, {
+    method: "PUT",
+    keepalive: true,
+    headers: { ...authHeaders(), "Content-Type": "application/json" },
+    body: JSON.stringify({
+      chapter_id: chapterId,
+      paragraph,
+      scroll_percent: Math.round(scrollPercent * 10) / 10,
+    }),
+  }).catch((err) => console.error("Failed to report reading position", err));
+}
+
+function getParagraphs(content: HTMLElement): HTMLElement[] {
+  return Array.from(content.children) as HTMLElement[];
+}
+
+function currentParagraph(paragraphs: HTMLElement[]): number {
+  for (let i = 0; i < paragraphs.length; i++) {
+    if (paragraphs[i].getBoundingClientRect().bottom > 0) return i;
+  }
+  return Math.max(paragraphs.length - 1, 0);
+}
+
+function currentScrollPercent(content: HTMLElement): number {
+  const rect = content.getBoundingClientRect();
+  if (rect.height <= 0) return 0;
+  const read = (window.innerHeight - rect.top) / rect.height;
+  return Math.min(Math.max(read * 100, 0), 100);
+}
+
+function restorePosition(content: HTMLElement, paragraph: number): void {
+  const paragraphs = getParagraphs(content);
+  const target = paragraphs[Math.min(paragraph, paragraphs.length - 1)];
+  if (!target) return;
+  target.scrollIntoView({ block: "start" });
+}
+
+function parseParagraphHash(): number | null {
+  const match = window.location.hash.match(/^#p-(\d+)$/);
+  return match ? parseInt(match[1], 10) : null;
+}
+
+function initPositionTracker(novelId: string, chapterId: string): void {
+  const content = document.getElementById("chapter-content");
+  if (!content) return;
+
+  const hashParagraph = parseParagraphHash();
+  if (hashParagraph !== null) {
+    restorePosition(content, hashParagraph);
+  }
+
+  if (!profileManager.isLoggedIn()) return;
+
+  if (hashParagraph === null) {
+    fetchServerProgress(novelId).then((progress) => {
+      if (!progress || progress.chapter_id !== chapterId) return;
+      if (progress.paragraph > 0 && window.scrollY < 100) {
+        restorePosition(content, progress.paragraph);
+      }
+    });
+  }
+
+  let lastReported = -1;
+  let dirty = false;
+
+  const flush = (): void => {
+    if (!dirty) return;
+    const paragraph = currentParagraph(getParagraphs(content));
+    if (paragraph === lastReported) {
+      dirty = false;
+      return;
+    }
+    reportPosition(novelId, chapterId, paragraph, currentScrollPercent(content));
+    lastReported = paragraph;
+    dirty = false;
+  };
+
+  window.addEventListener(
+    "scroll",
+    () => {
+      dirty = true;
+    },
+    { passive: true },
+  );
+
+  window.setInterval(flush, POSITION_REPORT_INTERVAL);
+
+  document.addEventListener("visibilitychange", () => {
+    if (document.visibilityState === "hidden") flush();
+  });
+}
+
+export function initServerProgressLinks(): void {
+  if (!profileManager.isLoggedIn()) return;
+
+  const buttons = document.querySelectorAll<HTMLAnchorElement>(
+    "a.js-reading-btn[data-novel-id]",
+  );
+  const novelIds = new Set(
+    Array.from(buttons).map((btn) => btn.dataset.novelId || ""),
+  );
+
+  novelIds.forEach((novelId) => {
+    if (!novelId) return;
+    fetchServerProgress(novelId).then((progress) => {
+      if (!progress) return;
+
+      document
+        .querySelectorAll<HTMLAnchorElement>(
+          `a.js-reading-btn[data-novel-id="${novelId}"]`,
+        )
+        .forEach((btn) => {
+          const hash =
+            progress.paragraph > 0 ? `#p-${progress.paragraph}` : "";
+          btn.href = `/${novelId}/chapter/${progress.chapter_id}${hash}`;
+          if (btn.classList.contains("btn-start")) {
+            btn.classList.replace("btn-start", "btn-continue");
+            btn.classList.add("btn-primary");
+            btn.textContent = "Продолжить";
+          }
+        });
+    });
+  });
+}
+
 export function initReadingProgressSaver(): void {
   const STORAGE_KEY = "kappalib_progress";
   const tracker = document.getElementById("reading-tracker");
@@ -25,6 +191,8 @@ export function initReadingProgressSaver(): void {
 
   const currentChapterNum = parseInt(currentChapterNumStr || "0", 10);
 
+  initPositionTracker(novelId, currentChapterId);
+
   const saveProgress = (
     targetChapterId: string,
     targetChapterNum: number,
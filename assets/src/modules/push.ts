@@ -0,0 +1,117 @@
+import { profileManager } from "./profile";
+
+const API_URL = process.env.API_URL;
+
+function authHeaders(): Record<string, string> {
+  return {
+    "X-Profile-ID": profileManager.getProfileId() || "",
+    "X-Secret-Token": profileManager.getSecretToken() || "",
+  };
+}
+
+function urlBase64ToUint8Array(base64: string): Uint8Array {
+  const padding = "=".repeat((4 - (base64.length % 4)) % 4);
+  const raw = atob((base64 + padding).replace(/-/g, "+").replace(/_/g, "/"));
+  return Uint8Array.from(raw, (c) => c.charCodeAt(0));
+}
+
+function isPushSupported(): boolean {
+  return (
+    "serviceWorker" in navigator &&
+    "PushManager" in window &&
+    "Notification" in window
+  );
+}
+
+async function fetchPublicKey(): Promise<string | null> {
+  try {
+    const res = await fetch(`${API_URL}/push/public-key`);
+    if (!res.ok) return null;
+    const data: { public_key: string } = await res.json();
+    return data.public_key;
+  } catch {
+    return null;
+  }
+}
+
+async function subscribe(publicKey: string): Promise<boolean> {
+  const permission = await Notification.requestPermission();
+  if (permission !== "granted") return false;
+
+  const registration = await navigator.serviceWorker.register("/sw.js");
+  await navigator.serviceWorker.ready;
+
+  const subscription = await registration.pushManager.subscribe({
+    userVisibleOnly: true,
+    applicationServerKey: urlBase64ToUint8Array(publicKey),
+  });
+
+  const res = await fetch(`${API_URL}/push/subscriptions`, {
+    method: "POST",
+    headers: { ...authHeaders(), "Content-Type": "application/json" },
+    body: JSON.stringify(subscription.toJSON()),
+  });
+  if (!res.ok) {
+    await subscription.unsubscribe();
+    return false;
+  }
+  return true;
+}
+
+async function unsubscribe(subscription: PushSubscription): Promise<void> {
+  await fetch(`${API_URL}/push/subscriptions`, {
+    method: "DELETE",
+    headers: { ...authHeaders(), "Content-Type": "application/json" },
+    body: JSON.stringify({ endpoint: subscription.endpoint }),
+  }).catch((err) => console.error("Failed to remove push subscription", err));
+  await subscription.unsubscribe();
+}
+
+async function currentSubscription(): Promise<PushSubscription | null> {
+  const registration = await navigator.serviceWorker.getRegistration("/");
+  if (!registration) return null;
+  return registration.pushManager.getSubscription();
+}
+
+export async function initPushToggle(): Promise<void> {
+  const section = document.getElementById("push-settings");
+  const toggle = document.getElementById(
+    "push-toggle",
+  ) as HTMLButtonElement | null;
+  const status = document.getElementById("push-status");
+  if (!section || !toggle || !status) return;
+  if (!profileManager.isLoggedIn() || !isPushSupported()) return;
+
+  const publicKey = await fetchPublicKey();
+  if (!publicKey) return;
+
+  section.style.display = "";
+
+  const render = (enabled: boolean): void => {
+    toggle.textContent = enabled ? "Отключить" : "Включить";
+    status.textContent = enabled
+      ? "Вы получаете уведомления о новых главах на этом устройстве."
+      : Notification.permission === "denied"
+        ? "Уведомления заблокированы в настройках браузера."
+        : "Получайте уведомления о новых главах, даже когда сайт закрыт.";
+    toggle.disabled = !enabled && Notification.permission === "denied";
+  };
+
+  render((await currentSubscription()) !== null);
+
+  toggle.addEventListener("click", async () => {
+    toggle.disabled = true;
+    try {
+      const existing = await currentSubscription();
+      if (existing) {
+        await unsubscribe(existing);
+        render(false);
+      } else {
+        render(await subscribe(publicKey));
+      }
+    } catch (err) {
+      console.error("Failed to toggle push notifications", err);
+      render((await currentSubscription()) !== null);
+    }
+  });
+}
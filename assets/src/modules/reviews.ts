@@ -0,0 +1,366 @@
+import { profileManager, getAvatarUrl } from "./profile";
+import {
+  formatRelativeTime,
+  initTurnstileForComments,
+  getTurnstileToken,
+} from "./comments";
+
+const API_URL = process.env.API_URL;
+const MIN_REVIEW_LENGTH = 50;
+const MAX_REVIEW_LENGTH = 5000;
+
+interface RatingSummary {
+  novel_id: string;
+  average: number;
+  count: number;
+  distribution: number[];
+}
+
+interface Review {
+  id: string;
+  novel_id: string;
+  user_id: string;
+  content_html: string;
+  status: string;
+  score: number | null;
+  created_at: string;
+  updated_at: string;
+  user_display_name: string;
+  user_avatar_seed: string;
+  user_has_custom_avatar: boolean;
+}
+
+interface ReviewsPage {
+  reviews: Review[];
+  page: number;
+  page_size: number;
+  total_count: number;
+  total_pages: number;
+}
+
+interface UserRating {
+  novel_id: string;
+  score: number | null;
+  review: Review | null;
+}
+
+function authHeaders(): Record<string, string> {
+  return {
+    "X-Profile-ID": profileManager.getProfileId() || "",
+    "X-Secret-Token": profileManager.getSecretToken() || "",
+  };
+}
+
+function formatAverage(avg: number): string {
+  return avg.toFixed(1).replace(".", ",");
+}
+
+function pluralizeRatings(n: number): string {
+  const n100 = Math.abs(n) % 100;
+  const n10 = n100 % 10;
+  let word = "оценок";
+  if (n100 < 11 || n100 > 19) {
+    if (n10 === 1) word = "оценка";
+    else if (n10 > 1 && n10 < 5) word = "оценки";
+  }
+  return `${n} ${word}`;
+}
+
+function reviewStatusLabel(status: string): string {
+  switch (status) {
+    case "pending":
+      return "На модерации";
+    case "rejected":
+      return "Отклонён";
+    default:
+      return "";
+  }
+}
+
+function createReviewHTML(review: Review): string {
+  const avatarUrl = getAvatarUrl(
+    review.user_id,
+    review.user_has_custom_avatar,
+    review.user_avatar_seed,
+  );
+  const score = review.score
+    ? `<span class="review-score">★ ${review.score}</span>`
+    : "";
+
+  return `
+    <div class="comment-item review-item" data-review-id="${review.id}">
+      <img src="${avatarUrl}" alt="${review.user_display_name}" class="comment-avatar" loading="lazy"/>
+      <div class="comment-body">
+        <div class="comment-header">
+          <a href="/user/${review.user_id}" class="comment-author">${review.user_display_name}</a>
+          ${score}
+          <span class="comment-date">${formatRelativeTime(review.updated_at)}</span>
+        </div>
+        <div class="comment-content">${review.content_html}</div>
+      </div>
+    </div>
+  `;
+}
+
+function renderSummary(summary: RatingSummary): void {
+  const avgEl = document.getElementById("rating-average");
+  const countEl = document.getElementById("rating-count");
+  if (avgEl) {
+    avgEl.textContent = summary.count > 0 ? formatAverage(summary.average) : "—";
+  }
+  if (countEl) {
+    countEl.textContent =
+      summary.count > 0 ? pluralizeRatings(summary.count) : "Оценок пока нет";
+  }
+}
+
+async function refreshSummary(novelId: string): Promise<void> {
+  try {
+    const res = await fetch(`${API_URL}/novels/${novelId}/rating`, {
+      cache: "no-store",
+    });
+    if (!res.ok) return;
+    renderSummary(await res.json());
+  } catch (err) {
+    console.error("Failed to refresh rating summary", err);
+  }
+}
+
+function setSelectedScore(control: HTMLElement, score: number | null): void {
+  control.querySelectorAll<HTMLElement>(".rating-star").forEach((btn) => {
+    const value = parseInt(btn.dataset.score || "0", 10);
+    btn.classList.toggle("active", score !== null && value <= score);
+  });
+  const removeBtn = document.getElementById("rating-remove");
+  if (removeBtn) removeBtn.style.display = score ? "" : "none";
+}
+
+async function loadReviews(
+  section: HTMLElement,
+  novelId: string,
+  page: number,
+): Promise<void> {
+  const listEl = section.querySelector<HTMLElement>("#reviews-list");
+  const moreBtn = document.getElementById("reviews-more");
+  if (!listEl) return;
+
+  try {
+    const res = await fetch(`${API_URL}/novels/${novelId}/reviews?page=${page}`);
+    if (!res.ok) throw new Error("Failed to load reviews");
+    const data: ReviewsPage = await res.json();
+
+    const html = data.reviews.map(createReviewHTML).join("");
+    if (page === 1) {
+      listEl.innerHTML =
+        html || '<div class="comments-empty">Отзывов пока нет.</div>';
+    } else {
+      listEl.insertAdjacentHTML("beforeend", html);
+    }
+
+    if (moreBtn) {
+      moreBtn.style.display = data.page < data.total_pages ? "" : "none";
+      moreBtn.dataset.page = String(data.page + 1);
+    }
+  } catch (err) {
+    console.error("Failed to load reviews", err);
+    if (page === 1) {
+      listEl.innerHTML =
+        '<div class="comments-error">Не удалось загрузить отзывы</div>';
+    }
+  }
+}
+
+function renderReviewForm(section: HTMLElement, own: Review | null): void {
+  const wrapper = section.querySelector<HTMLElement>(".review-form-wrapper");
+  if (!wrapper) return;
+
+  if (!profileManager.isLoggedIn()) {
+    wrapper.innerHTML = `
+      <div class="comment-form comment-form-guest">
+        <p class="comment-guest-message">Войдите или создайте аккаунт, чтобы оценивать и писать отзывы</p>
+      </div>
+    `;
+    return;
+  }
+
+  const status = own ? reviewStatusLabel(own.status) : "";
+  wrapper.innerHTML = `
+    <div class="comment-form">
+      <textarea
+        id="review-textarea"
+        class="comment-textarea"
+        placeholder="Поделитесь впечатлениями о новелле..."
+        maxlength="${MAX_REVIEW_LENGTH}"
+        rows="3"
+      ></textarea>
+      <div class="comment-form-footer">
+        <span id="review-char-counter" class="comment-char-counter">0/${MAX_REVIEW_LENGTH}</span>
+        ${status ? `<span class="comment-moderation-badge">${status}</span>` : ""}
+        <div id="comments-turnstile-container"></div>
+        ${own ? '<button id="review-delete" class="history-clear" type="button">Удалить</button>' : ""}
+        <button id="review-submit" class="action-btn btn-primary comment-submit-btn">${own ? "Обновить отзыв" : "Отправить отзыв"}</button>
+      </div>
+    </div>
+  `;
+
+  const textarea = wrapper.querySelector<HTMLTextAreaElement>("#review-textarea");
+  const counter = wrapper.querySelector<HTMLElement>("#review-char-counter");
+  const submitBtn = wrapper.querySelector<HTMLButtonElement>("#review-submit");
+  const deleteBtn = wrapper.querySelector<HTMLButtonElement>("#review-delete");
+  const novelId = section.dataset.novelId || "";
+  if (!textarea || !submitBtn) return;
+
+  const updateCounter = () => {
+    if (!counter) return;
+    const len = textarea.value.trim().length;
+    counter.textContent = `${len}/${MAX_REVIEW_LENGTH}`;
+    counter.classList.toggle("warning", len > 0 && len < MIN_REVIEW_LENGTH);
+    counter.classList.toggle("error", len >= MAX_REVIEW_LENGTH);
+  };
+
+  if (own) {
+    const tmp = document.createElement("div");
+    tmp.innerHTML = own.content_html;
+    textarea.value = tmp.innerText.trim();
+    updateCounter();
+  }
+
+  textarea.addEventListener("input", updateCounter);
+  textarea.addEventListener("focus", () => initTurnstileForComments(wrapper));
+
+  submitBtn.addEventListener("click", async () => {
+    const content = textarea.value.trim();
+    if (content.length < MIN_REVIEW_LENGTH) {
+      alert(`Отзыв должен содержать не меньше ${MIN_REVIEW_LENGTH} символов`);
+      return;
+    }
+
+    const label = submitBtn.textContent;
+    submitBtn.disabled = true;
+    submitBtn.textContent = "Проверка...";
+
+    const token = await getTurnstileToken();
+    if (!token) {
+      alert("Не удалось пройти проверку. Попробуйте ещё раз.");
+      submitBtn.disabled = false;
+      submitBtn.textContent = label;
+      return;
+    }
+
+    submitBtn.textContent = "Отправка...";
+
+    try {
+      const res = await fetch(`${API_URL}/novels/${novelId}/reviews`, {
+        method: "POST",
+        headers: { ...authHeaders(), "Content-Type": "application/json" },
+        body: JSON.stringify({ content, turnstile_token: token }),
+      });
+      if (!res.ok) {
+        const err = await res.json();
+        throw new Error(err.detail || "Failed to save review");
+      }
+      const review: Review = await res.json();
+      renderReviewForm(section, review);
+    } catch (err) {
+      console.error("Failed to submit review", err);
+      alert("Не удалось отправить отзыв. Попробуйте ещё раз.");
+      submitBtn.disabled = false;
+      submitBtn.textContent = label;
+    }
+  });
+
+  deleteBtn?.addEventListener("click", async () => {
+    if (!confirm("Удалить ваш отзыв?")) return;
+    try {
+      const res = await fetch(`${API_URL}/novels/${novelId}/reviews`, {
+        method: "DELETE",
+        headers: authHeaders(),
+      });
+      if (!res.ok && res.status !== 404) throw new Error("Failed to delete review");
+      renderReviewForm(section, null);
+      loadReviews(section, novelId, 1);
+    } catch (err) {
+      console.error("Failed to delete review", err);
+    }
+  });
+}
+
+async function initRatingControl(
+  section: HTMLElement,
+  novelId: string,
+): Promise<void> {
+  const control = document.getElementById("rating-control");
+  if (!control) return;
+
+  let own: UserRating | null = null;
+  try {
+    const res = await fetch(`${API_URL}/novels/${novelId}/rating/me`, {
+      headers: authHeaders(),
+    });
+    if (res.ok) own = await res.json();
+  } catch (err) {
+    console.error("Failed to load own rating", err);
+  }
+
+  control.style.display = "";
+  setSelectedScore(control, own?.score ?? null);
+  renderReviewForm(section, own?.review ?? null);
+
+  control.addEventListener("click", async (e) => {
+    const btn = (e.target as HTMLElement).closest<HTMLElement>(".rating-star");
+    if (!btn) return;
+    const score = parseInt(btn.dataset.score || "0", 10);
+
+    try {
+      const res = await fetch(`${API_URL}/novels/${novelId}/rating`, {
+        method: "PUT",
+        headers: { ...authHeaders(), "Content-Type": "application/json" },
+        body: JSON.stringify({ score }),
+      });
+      if (!res.ok) throw new Error("Failed to save rating");
+      setSelectedScore(control, score);
+      refreshSummary(novelId);
+    } catch (err) {
+      console.error("Failed to save rating", err);
+    }
+  });
+
+  document
+    .getElementById("rating-remove")
+    ?.addEventListener("click", async () => {
+      try {
+        const res = await fetch(`${API_URL}/novels/${novelId}/rating`, {
+          method: "DELETE",
+          headers: authHeaders(),
+        });
+        if (!res.ok && res.status !== 404) {
+          throw new Error("Failed to remove rating");
+        }
+        setSelectedScore(control, null);
+        refreshSummary(novelId);
+      } catch (err) {
+        console.error("Failed to remove rating", err);
+      }
+    });
+}
+
+export function initRatings(): void {
+  const section = document.getElementById("rating-section");
+  if (!section) return;
+
+  const novelId = section.dataset.novelId;
+  if (!novelId) return;
+
+  loadReviews(section, novelId, 1);
+
+  if (profileManager.isLoggedIn()) {
+    initRatingControl(section, novelId);
+  } else {
+    renderReviewForm(section, null);
+  }
+
+  document.getElementById("reviews-more")?.addEventListener("click", (e) => {
+    const page = parseInt((e.currentTarget as HTMLElement).dataset.page || "2", 10);
+    loadReviews(section, novelId, page);
+  });
+}
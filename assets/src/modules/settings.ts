@@ -1,15 +1,32 @@
 import Dropdown from "./dropdown";
-import { setKappalibCookie } from "./profile";
+import { profileManager, setKappalibCookie } from "./profile";
 
 const SETTINGS_COOKIE_KEY = "kappalib_reader_settings";
+const PROFILE_SAVE_DELAY = 500;
+const CUSTOM_THEME_PREFIX = "custom:";
+const MAX_CUSTOM_THEMES = 3;
+const MIN_TEXT_CONTRAST = 4.5;
+const MIN_LINK_CONTRAST = 3;
+const CUSTOM_THEME_VARS = ["--bg-primary", "--primary", "--reader-link"];
+
+interface ReaderTheme {
+  background: string;
+  text: string;
+  link: string;
+}
 
 interface ReaderSettings {
-  theme: "auto" | "light" | "dark";
+  version?: number;
+  theme: string;
   fontSize: number;
   fontFamily: string;
   indent: number;
   density: "compact" | "normal" | "relaxed";
   justify: boolean;
+  lineHeight?: number;
+  paragraphSpacing?: number;
+  maxWidth?: number;
+  customThemes?: ReaderTheme[];
 }
 
 const DEFAULT_SETTINGS: ReaderSettings = {
@@ -19,6 +36,20 @@ const DEFAULT_SETTINGS: ReaderSettings = {
   indent: 0,
   density: "normal",
   justify: false,
+  lineHeight: 0,
+  paragraphSpacing: 0,
+  maxWidth: 0,
+  customThemes: [],
+};
+
+const LINE_HEIGHT_OPTIONS = [0, 1.4, 1.7, 2];
+const PARAGRAPH_SPACING_OPTIONS = [0, 0.5, 1, 1.5];
+const MAX_WIDTH_OPTIONS = [0, 60, 75, 90];
+
+const NEW_CUSTOM_THEME: ReaderTheme = {
+  background: "#f4ecd8",
+  text: "#3b2f2f",
+  link: "#8b4513",
 };
 
 const FONT_OPTIONS: { value: string; label: string; family: string }[] = [
@@ -77,7 +108,21 @@ function getCookie(name: string): string | null {
   return match ? decodeURIComponent(match[2]) : null;
 }
 
+function getServerSettings(): ReaderSettings | null {
+  const meta = document.querySelector(
+    'meta[name="reader-settings"]',
+  ) as HTMLMetaElement | null;
+  if (!meta?.content) return null;
+  try {
+    return { ...DEFAULT_SETTINGS, ...JSON.parse(meta.content) };
+  } catch {
+    return null;
+  }
+}
+
 function getSettings(): ReaderSettings {
+  const serverSettings = getServerSettings();
+  if (serverSettings) return serverSettings;
   try {
     const raw = getCookie(SETTINGS_COOKIE_KEY);
     if (raw) {
@@ -90,9 +135,17 @@ function getSettings(): ReaderSettings {
   return { ...DEFAULT_SETTINGS };
 }
 
+let profileSaveTimer: ReturnType<typeof setTimeout> | null = null;
+
 function saveSettings(settings: ReaderSettings): void {
   setKappalibCookie(SETTINGS_COOKIE_KEY, JSON.stringify(settings));
   applySettings(settings);
+
+  if (!profileManager.isLoggedIn()) return;
+  if (profileSaveTimer) clearTimeout(profileSaveTimer);
+  profileSaveTimer = setTimeout(() => {
+    profileManager.updateReaderSettings(settings);
+  }, PROFILE_SAVE_DELAY);
 }
 
 function enableThemeTransition(): void {
@@ -110,14 +163,74 @@ function enableThemeTransition(): void {
   }, 100);
 }
 
-function applySettings(settings: ReaderSettings): void {
+function relativeLuminance(hex: string): number {
+  const rgb = parseInt(hex.slice(1), 16);
+  const channel = (v: number) => {
+    const c = v / 255;
+    return c <= 0.03928 ? c / 12.92 : Math.pow((c + 0.055) / 1.055, 2.4);
+  };
+  return (
+    0.2126 * channel((rgb >> 16) & 0xff) +
+    0.7152 * channel((rgb >> 8) & 0xff) +
+    0.0722 * channel(rgb & 0xff)
+  );
+}
+
+function contrastRatio(a: string, b: string): number {
+  const la = relativeLuminance(a);
+  const lb = relativeLuminance(b);
+  return (Math.max(la, lb) + 0.05) / (Math.min(la, lb) + 0.05);
+}
+
+function hasSufficientContrast(theme: ReaderTheme): boolean {
+  return (
+    contrastRatio(theme.text, theme.background) >= MIN_TEXT_CONTRAST &&
+    contrastRatio(theme.link, theme.background) >= MIN_LINK_CONTRAST
+  );
+}
+
+function getCustomTheme(settings: ReaderSettings): ReaderTheme | null {
+  if (!settings.theme.startsWith(CUSTOM_THEME_PREFIX)) return null;
+  const idx = parseInt(settings.theme.slice(CUSTOM_THEME_PREFIX.length), 10);
+  return settings.customThemes?.[idx] ?? null;
+}
+
+function applyTheme(settings: ReaderSettings): void {
   const root = document.documentElement;
+  const customTheme = getCustomTheme(settings);
+
+  CUSTOM_THEME_VARS.forEach((name) => root.style.removeProperty(name));
 
-  if (settings.theme === "auto") {
+  if (customTheme) {
+    root.setAttribute(
+      "data-theme",
+      relativeLuminance(customTheme.background) < 0.179 ? "dark" : "light",
+    );
+    root.style.setProperty("--bg-primary", customTheme.background);
+    root.style.setProperty("--primary", customTheme.text);
+    root.style.setProperty("--reader-link", customTheme.link);
+  } else if (settings.theme === "light" || settings.theme === "dark") {
+    root.setAttribute("data-theme", settings.theme);
+  } else {
     root.removeAttribute("data-theme");
+  }
+}
+
+function setOptionalProperty(
+  el: HTMLElement,
+  name: string,
+  value: number | undefined,
+  unit = "",
+): void {
+  if (value) {
+    el.style.setProperty(name, `${value}${unit}`);
   } else {
-    root.setAttribute("data-theme", settings.theme);
+    el.style.removeProperty(name);
   }
+}
+
+function applySettings(settings: ReaderSettings): void {
+  applyTheme(settings);
 
   const chapterContent = document.querySelector(
     ".chapter-content",
@@ -156,6 +269,24 @@ function applySettings(settings: ReaderSettings): void {
     );
     chapterContent.classList.add(`density-${settings.density}`);
     chapterContent.classList.toggle("justify-text", settings.justify);
+
+    setOptionalProperty(
+      chapterContent,
+      "--reader-line-height",
+      settings.lineHeight,
+    );
+    setOptionalProperty(
+      chapterContent,
+      "--reader-paragraph-spacing",
+      settings.paragraphSpacing,
+      "em",
+    );
+    setOptionalProperty(
+      chapterContent,
+      "--reader-max-width",
+      settings.maxWidth,
+      "ch",
+    );
   }
 
   if (chapterTitle) {
@@ -175,14 +306,7 @@ function applySettings(settings: ReaderSettings): void {
 }
 
 function applyGlobalSettings(): void {
-  const settings = getSettings();
-  const root = document.documentElement;
-
-  if (settings.theme === "auto") {
-    root.removeAttribute("data-theme");
-  } else {
-    root.setAttribute("data-theme", settings.theme);
-  }
+  applyTheme(getSettings());
 }
 
 class SettingsManager {
@@ -196,6 +320,33 @@ class SettingsManager {
     return { ...this.settings };
   }
 
+  saveCustomTheme(index: number, theme: ReaderTheme): void {
+    const themes = [...(this.settings.customThemes ?? [])];
+    themes[index] = theme;
+    this.settings.customThemes = themes;
+    this.settings.theme = `${CUSTOM_THEME_PREFIX}${index}`;
+    saveSettings(this.settings);
+  }
+
+  deleteCustomTheme(index: number): void {
+    const themes = [...(this.settings.customThemes ?? [])];
+    themes.splice(index, 1);
+    this.settings.customThemes = themes;
+
+    if (this.settings.theme.startsWith(CUSTOM_THEME_PREFIX)) {
+      const activeIdx = parseInt(
+        this.settings.theme.slice(CUSTOM_THEME_PREFIX.length),
+        10,
+      );
+      if (activeIdx === index) {
+        this.settings.theme = "auto";
+      } else if (activeIdx > index) {
+        this.settings.theme = `${CUSTOM_THEME_PREFIX}${activeIdx - 1}`;
+      }
+    }
+    saveSettings(this.settings);
+  }
+
   updateSetting<K extends keyof ReaderSettings>(
     key: K,
     value: ReaderSettings[K],
@@ -324,6 +475,124 @@ function createToggleButton(
   return btn;
 }
 
+function appendOptionToggles(
+  id: string,
+  options: number[],
+  current: number | undefined,
+  label: (value: number) => string,
+): void {
+  const toggle = document.getElementById(id);
+  if (!toggle) return;
+  options.forEach((value) => {
+    toggle.appendChild(
+      createToggleButton(
+        String(value),
+        value ? label(value) : "Авто",
+        (current ?? 0) === value,
+      ),
+    );
+  });
+}
+
+let editingThemeIndex: number | null = null;
+
+function renderCustomThemes(): void {
+  const container = document.getElementById("custom-themes");
+  if (!container) return;
+
+  const settings = settingsManager.getSettings();
+  const themes = settings.customThemes ?? [];
+
+  container.innerHTML = "";
+  themes.forEach((theme, i) => {
+    const btn = document.createElement("button");
+    btn.className =
+      "settings-theme-swatch" +
+      (settings.theme === `${CUSTOM_THEME_PREFIX}${i}` ? " active" : "");
+    btn.dataset.index = String(i);
+    btn.textContent = "Аа";
+    btn.title = "Нажмите ещё раз, чтобы изменить";
+    btn.style.background = theme.background;
+    btn.style.color = theme.text;
+    container.appendChild(btn);
+  });
+
+  if (themes.length < MAX_CUSTOM_THEMES) {
+    const addBtn = document.createElement("button");
+    addBtn.className = "settings-theme-swatch";
+    addBtn.dataset.index = String(themes.length);
+    addBtn.textContent = "+";
+    addBtn.title = "Создать тему";
+    container.appendChild(addBtn);
+  }
+}
+
+function readEditorTheme(editor: HTMLElement): ReaderTheme {
+  const color = (key: keyof ReaderTheme) =>
+    (editor.querySelector(`[data-color="${key}"]`) as HTMLInputElement).value;
+  return {
+    background: color("background"),
+    text: color("text"),
+    link: color("link"),
+  };
+}
+
+function updateThemeEditor(editor: HTMLElement): void {
+  const theme = readEditorTheme(editor);
+  const isReadable = hasSufficientContrast(theme);
+
+  const preview = document.getElementById("custom-theme-preview");
+  if (preview) {
+    preview.style.background = theme.background;
+    preview.style.color = theme.text;
+    const link = preview.querySelector("a");
+    if (link) link.style.color = theme.link;
+  }
+
+  const hint = document.getElementById("custom-theme-hint");
+  if (hint) {
+    hint.textContent = isReadable
+      ? `Контраст текста ${contrastRatio(theme.text, theme.background).toFixed(1)}:1`
+      : "Недостаточный контраст: текст и ссылки плохо читаются на этом фоне";
+    hint.classList.toggle("error", !isReadable);
+  }
+
+  const saveBtn = document.getElementById(
+    "custom-theme-save",
+  ) as HTMLButtonElement | null;
+  if (saveBtn) saveBtn.disabled = !isReadable;
+}
+
+function openThemeEditor(index: number): void {
+  const editor = document.getElementById("custom-theme-editor");
+  if (!editor) return;
+
+  const existing = settingsManager.getSettings().customThemes?.[index];
+  const theme = existing ?? NEW_CUSTOM_THEME;
+  editingThemeIndex = index;
+
+  (Object.keys(theme) as (keyof ReaderTheme)[]).forEach((key) => {
+    const input = editor.querySelector(
+      `[data-color="${key}"]`,
+    ) as HTMLInputElement | null;
+    if (input) input.value = theme[key];
+  });
+
+  const deleteBtn = document.getElementById(
+    "custom-theme-delete",
+  ) as HTMLButtonElement | null;
+  if (deleteBtn) deleteBtn.disabled = !existing;
+
+  editor.hidden = false;
+  updateThemeEditor(editor);
+}
+
+function closeThemeEditor(): void {
+  editingThemeIndex = null;
+  const editor = document.getElementById("custom-theme-editor");
+  if (editor) editor.hidden = true;
+}
+
 function renderSettingsView(): void {
   const content = document.getElementById("settings-card");
   if (!content) return;
@@ -422,6 +691,27 @@ function renderSettingsView(): void {
     );
   }
 
+  appendOptionToggles(
+    "line-height-toggle",
+    LINE_HEIGHT_OPTIONS,
+    settings.lineHeight,
+    (v) => String(v).replace(".", ","),
+  );
+  appendOptionToggles(
+    "paragraph-spacing-toggle",
+    PARAGRAPH_SPACING_OPTIONS,
+    settings.paragraphSpacing,
+    (v) => String(v).replace(".", ","),
+  );
+  appendOptionToggles(
+    "max-width-toggle",
+    MAX_WIDTH_OPTIONS,
+    settings.maxWidth,
+    (v) => ({ 60: "Узкая", 75: "Средняя", 90: "Широкая" })[v] ?? String(v),
+  );
+
+  editingThemeIndex = null;
+  renderCustomThemes();
   updateFontSizeButtons(settings.fontSize);
   initSettingsInteractions();
 }
@@ -436,7 +726,9 @@ function initSettingsInteractions(): void {
     });
   }
 
-  const themeToggle = document.querySelector('[data-setting="theme"]');
+  const themeToggle = document.querySelector(
+    '[data-setting="theme"]',
+  ) as HTMLElement | null;
   themeToggle?.addEventListener("click", (e) => {
     const btn = (e.target as HTMLElement).closest(
       ".settings-toggle-btn",
@@ -447,10 +739,78 @@ function initSettingsInteractions(): void {
     if (value !== currentTheme) {
       enableThemeTransition();
       settingsManager.updateSetting("theme", value);
-      updateActiveToggle(themeToggle as HTMLElement, value);
+      updateActiveToggle(themeToggle, value);
+      renderCustomThemes();
     }
   });
 
+  const customThemes = document.getElementById("custom-themes");
+  customThemes?.addEventListener("click", (e) => {
+    const btn = (e.target as HTMLElement).closest(
+      ".settings-theme-swatch",
+    ) as HTMLElement;
+    if (!btn) return;
+    const index = parseInt(btn.dataset.index || "0", 10);
+    const value = `${CUSTOM_THEME_PREFIX}${index}`;
+    const settings = settingsManager.getSettings();
+    if (settings.customThemes?.[index] && settings.theme !== value) {
+      enableThemeTransition();
+      settingsManager.updateSetting("theme", value);
+      if (themeToggle) updateActiveToggle(themeToggle, value);
+      closeThemeEditor();
+      renderCustomThemes();
+    } else {
+      openThemeEditor(index);
+    }
+  });
+
+  const themeEditor = document.getElementById("custom-theme-editor");
+  themeEditor?.addEventListener("input", () => {
+    if (themeEditor) updateThemeEditor(themeEditor);
+  });
+
+  document
+    .getElementById("custom-theme-save")
+    ?.addEventListener("click", () => {
+      if (!themeEditor || editingThemeIndex === null) return;
+      const theme = readEditorTheme(themeEditor);
+      if (!hasSufficientContrast(theme)) return;
+      enableThemeTransition();
+      settingsManager.saveCustomTheme(editingThemeIndex, theme);
+      if (themeToggle) {
+        updateActiveToggle(themeToggle, settingsManager.getSettings().theme);
+      }
+      closeThemeEditor();
+      renderCustomThemes();
+    });
+
+  document
+    .getElementById("custom-theme-delete")
+    ?.addEventListener("click", () => {
+      if (editingThemeIndex === null) return;
+      enableThemeTransition();
+      settingsManager.deleteCustomTheme(editingThemeIndex);
+      if (themeToggle) {
+        updateActiveToggle(themeToggle, settingsManager.getSettings().theme);
+      }
+      closeThemeEditor();
+      renderCustomThemes();
+    });
+
+  (["lineHeight", "paragraphSpacing", "maxWidth"] as const).forEach((key) => {
+    const toggle = document.querySelector(
+      `[data-setting="${key}"]`,
+    ) as HTMLElement | null;
+    toggle?.addEventListener("click", (e) => {
+      const btn = (e.target as HTMLElement).closest(
+        ".settings-toggle-btn",
+      ) as HTMLElement;
+      if (!btn) return;
+      settingsManager.updateSetting(key, parseFloat(btn.dataset.value || "0"));
+      updateActiveToggle(toggle, btn.dataset.value!);
+    });
+  });
+
   const justifyToggle = document.querySelector('[data-setting="justify"]');
   justifyToggle?.addEventListener("click", (e) => {
     const btn = (e.target as HTMLElement).closest(
@@ -0,0 +1,58 @@
+interface PushMessage {
+  title: string;
+  body: string;
+  url: string;
+  tag: string;
+}
+
+const sw = self as any;
+
+sw.addEventListener("install", () => {
+  sw.skipWaiting();
+});
+
+sw.addEventListener("activate", (event: any) => {
+  event.waitUntil(sw.clients.claim());
+});
+
+sw.addEventListener("push", (event: any) => {
+  let message: PushMessage = {
+    title: "kappalib",
+    body: "",
+    url: "/notifications",
+    tag: "",
+  };
+
+  try {
+    if (event.data) message = { ...message, ...event.data.json() };
+  } catch (err) {
+    console.error("Failed to parse push payload", err);
+  }
+
+  event.waitUntil(
+    sw.registration.showNotification(message.title, {
+      body: message.body,
+      tag: message.tag || undefined,
+      icon: "/assets/icons/favicon-32x32.png",
+      badge: "/assets/icons/favicon-32x32.png",
+      data: { url: message.url },
+    }),
+  );
+});
+
+sw.addEventListener("notificationclick", (event: any) => {
+  event.notification.close();
+  const url = new URL(event.notification.data?.url || "/", sw.location.origin)
+    .href;
+
+  event.waitUntil(
+    sw.clients
+      .matchAll({ type: "window", includeUncontrolled: true })
+      .then((windows: any[]) => {
+        for (const client of windows) {
+          if (client.url === url && "focus" in client) return client.focus();
+        }
+        return sw.clients.openWindow(url);
+      }),
+  );
+});
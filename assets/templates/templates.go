@@ -107,12 +107,14 @@ type SchemaNovelData struct {
 }
 
 type SchemaNovel struct {
-	ID       string
-	Title    string
-	TitleEn  string
-	Author   string
-	Status   string
-	CoverURL string
+	ID          string
+	Title       string
+	TitleEn     string
+	Author      string
+	Status      string
+	CoverURL    string
+	RatingValue string
+	RatingCount int
 }
 
 func RenderSchemaNovel(data SchemaNovelData) (string, error) {
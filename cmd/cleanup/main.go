@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ch1kulya/kappalib/internal/data"
+	"github.com/ch1kulya/kappalib/internal/database"
+
+	_ "github.com/joho/godotenv/autoload"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage:")
+	fmt.Fprintln(os.Stderr, "  cleanup profiles [flags]     remove abandoned anonymous profiles")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "profiles":
+		cleanupProfiles(os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func cleanupProfiles(args []string) {
+	fs := flag.NewFlagSet("profiles", flag.ExitOnError)
+	days := fs.Int("days", data.ProfileRetentionDays(), "delete profiles inactive for more than N days")
+	dryRun := fs.Bool("dry-run", false, "only report what would be deleted")
+	fs.Parse(args)
+
+	if *days <= 0 {
+		fmt.Fprintln(os.Stderr, "Retention period must be positive")
+		os.Exit(2)
+	}
+
+	if err := database.Init(); err != nil {
+		fmt.Fprintf(os.Stderr, "Database initialization failed: %v\n", err)
+		os.Exit(1)
+	}
+	defer database.Close()
+
+	result, err := data.CleanupAbandonedProfiles(context.Background(), *days, *dryRun)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cleanup failed: %v\n", err)
+		if result == nil {
+			os.Exit(1)
+		}
+	}
+
+	if *dryRun {
+		fmt.Printf("Would delete %d profiles inactive for over %d days (%d with custom avatars)\n",
+			result.Profiles, *days, result.Avatars)
+		return
+	}
+	fmt.Printf("Deleted %d profiles inactive for over %d days, removed %d avatars\n",
+		result.Profiles, *days, result.Avatars)
+	if err != nil {
+		os.Exit(1)
+	}
+}
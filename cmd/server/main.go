@@ -72,6 +72,7 @@ func buildAssets() {
 	result := esbuild.Build(esbuild.BuildOptions{
 		EntryPoints: []string{
 			"./assets/src/app.ts",
+			"./assets/src/sw.ts",
 			"./assets/src/styles/main.css",
 		},
 		Outdir:            "./assets/static/dist",
@@ -120,6 +121,16 @@ func main() {
 	}
 	logger.Info("Templates initialized")
 
+	if err := data.InitViews(); err != nil {
+		logger.Error("View counter initialization failed: %v", err)
+		os.Exit(1)
+	}
+
+	if err := data.InitSessions(); err != nil {
+		logger.Error("Session initialization failed: %v", err)
+		os.Exit(1)
+	}
+
 	runMigrations()
 
 	if err := database.Init(); err != nil {
@@ -150,12 +161,18 @@ func main() {
 	r.Handle("/assets/*", http.StripPrefix("/assets", web.StaticCacheMiddleware(fileServer)))
 
 	r.Get("/robots.txt", h.RobotsTxt)
+	r.Get("/sw.js", h.ServiceWorker)
 	r.Get("/sitemap.xml", h.Sitemap)
 	r.Get("/", h.Home)
 	r.Get("/dmca", h.StaticPage("dmca", "DMCA"))
 	r.Get("/privacy", h.StaticPage("privacy", "Политика конфиденциальности"))
 	r.Get("/copyright", h.StaticPage("copyright", "Правообладателям"))
 	r.Get("/license", h.StaticPage("license", "Лицензия MIT"))
+	r.Get("/updates", h.Updates)
+	r.Get("/library", h.Library)
+	r.Get("/history", h.History)
+	r.Get("/notifications", h.Notifications)
+	r.Get("/user/{id}", h.User)
 	r.Get("/{id}", h.Novel)
 	r.Get("/{id}/chapter/{chapterId}", h.Chapter)
 	r.Get("/status", h.GetStatus)
@@ -168,6 +185,7 @@ func main() {
 		r.Use(api.CorsMiddleware)
 		r.Use(api.RateLimitMiddleware(apiRateLimiter))
 		r.Use(api.CacheMiddleware)
+		r.Use(api.ClientIPMiddleware)
 
 		config := huma.DefaultConfig("kappalib", "stable")
 		config.Info.Description = "Public API for accessing kappalib services."
@@ -195,6 +213,13 @@ func main() {
 			Summary:     "List novels",
 		}, api.HandleGetNovels)
 
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "get-updates",
+			Method:      http.MethodGet,
+			Path:        "/updates",
+			Summary:     "List recently added chapters",
+		}, api.HandleGetUpdates)
+
 		huma.Register(humaApi, huma.Operation{
 			OperationID: "get-sitemap",
 			Method:      http.MethodGet,
@@ -223,6 +248,13 @@ func main() {
 			Summary:     "List chapters for novel",
 		}, api.HandleGetChaptersList)
 
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "get-similar-novels",
+			Method:      http.MethodGet,
+			Path:        "/novels/{id}/similar",
+			Summary:     "List similar novels",
+		}, api.HandleGetSimilarNovels)
+
 		huma.Register(humaApi, huma.Operation{
 			OperationID: "get-chapter",
 			Method:      http.MethodGet,
@@ -265,12 +297,126 @@ func main() {
 			Summary:     "Login with sync code",
 		}, api.HandleLogin)
 
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "complete-login-request",
+			Method:      http.MethodPost,
+			Path:        "/profile/login/{requestId}",
+			Summary:     "Complete confirmed sync code login",
+		}, api.HandleCompleteLoginRequest)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "get-login-requests",
+			Method:      http.MethodGet,
+			Path:        "/profile/{id}/login-requests",
+			Summary:     "List pending login requests",
+		}, api.HandleGetLoginRequests)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "resolve-login-request",
+			Method:      http.MethodPost,
+			Path:        "/profile/{id}/login-requests/{requestId}",
+			Summary:     "Approve or deny login request",
+		}, api.HandleResolveLoginRequest)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "export-profile",
+			Method:      http.MethodGet,
+			Path:        "/profile/{id}/export",
+			Summary:     "Export profile data as JSON",
+		}, api.HandleExportProfile)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "export-profile-archive",
+			Method:      http.MethodGet,
+			Path:        "/profile/{id}/export/archive",
+			Summary:     "Export profile data as ZIP archive",
+		}, api.HandleExportProfileArchive)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID:  "import-profile",
+			Method:       http.MethodPost,
+			Path:         "/profile/import",
+			Summary:      "Import profile archive into a new profile",
+			MaxBodyBytes: 16 << 20,
+		}, api.HandleImportProfile)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "get-reader-settings",
+			Method:      http.MethodGet,
+			Path:        "/profile/{id}/reader-settings",
+			Summary:     "Get reader settings",
+		}, api.HandleGetReaderSettings)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "update-reader-settings",
+			Method:      http.MethodPut,
+			Path:        "/profile/{id}/reader-settings",
+			Summary:     "Update reader settings",
+		}, api.HandleUpdateReaderSettings)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "issue-reader-settings-cookie",
+			Method:      http.MethodPut,
+			Path:        "/profile/{id}/reader-settings/cookie",
+			Summary:     "Issue page rendering cookie for reader settings",
+		}, api.HandleIssueReaderSettingsCookie)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "clear-reader-settings-cookie",
+			Method:      http.MethodDelete,
+			Path:        "/profile/{id}/reader-settings/cookie",
+			Summary:     "Clear reader settings cookie",
+		}, api.HandleClearReaderSettingsCookie)
+
 		huma.Register(humaApi, huma.Operation{
 			OperationID: "sync-cookies",
 			Method:      http.MethodPost,
 			Path:        "/profile/sync-cookies",
 			Summary:     "Sync cookies",
 		}, api.HandleSyncCookies)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "get-sessions",
+			Method:      http.MethodGet,
+			Path:        "/profile/{id}/sessions",
+			Summary:     "List active sessions",
+		}, api.HandleGetSessions)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "revoke-other-sessions",
+			Method:      http.MethodDelete,
+			Path:        "/profile/{id}/sessions",
+			Summary:     "Revoke all other sessions",
+		}, api.HandleRevokeOtherSessions)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "revoke-session",
+			Method:      http.MethodDelete,
+			Path:        "/profile/{id}/sessions/{sessionId}",
+			Summary:     "Revoke session",
+		}, api.HandleRevokeSession)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "get-blocked-users",
+			Method:      http.MethodGet,
+			Path:        "/profile/{id}/blocks",
+			Summary:     "List muted and blocked users",
+		}, api.HandleGetBlockedUsers)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "block-user",
+			Method:      http.MethodPut,
+			Path:        "/profile/{id}/blocks/{userId}",
+			Summary:     "Mute or block user",
+		}, api.HandleBlockUser)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "unblock-user",
+			Method:      http.MethodDelete,
+			Path:        "/profile/{id}/blocks/{userId}",
+			Summary:     "Unblock user",
+		}, api.HandleUnblockUser)
+
 		huma.Register(humaApi, huma.Operation{
 			OperationID: "get-comments",
 			Method:      http.MethodGet,
@@ -278,6 +424,13 @@ func main() {
 			Summary:     "Get chapter comments",
 		}, api.HandleGetComments)
 
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "get-comment-replies",
+			Method:      http.MethodGet,
+			Path:        "/comments/{commentId}/replies",
+			Summary:     "Get all replies in a comment thread",
+		}, api.HandleGetCommentReplies)
+
 		huma.Register(humaApi, huma.Operation{
 			OperationID: "create-comment",
 			Method:      http.MethodPost,
@@ -285,6 +438,48 @@ func main() {
 			Summary:     "Create comment",
 		}, api.HandleCreateComment)
 
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "edit-comment",
+			Method:      http.MethodPatch,
+			Path:        "/comments/{commentId}",
+			Summary:     "Edit own comment",
+		}, api.HandleEditComment)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "delete-comment",
+			Method:      http.MethodDelete,
+			Path:        "/comments/{commentId}",
+			Summary:     "Delete own comment",
+		}, api.HandleDeleteComment)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "get-chapter-reactions",
+			Method:      http.MethodGet,
+			Path:        "/chapters/{chapterId}/reactions",
+			Summary:     "Get own comment reactions for chapter",
+		}, api.HandleGetChapterReactions)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "set-comment-reaction",
+			Method:      http.MethodPut,
+			Path:        "/comments/{commentId}/reaction",
+			Summary:     "Set reaction on comment",
+		}, api.HandleSetCommentReaction)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "delete-comment-reaction",
+			Method:      http.MethodDelete,
+			Path:        "/comments/{commentId}/reaction",
+			Summary:     "Remove reaction from comment",
+		}, api.HandleDeleteCommentReaction)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "report-comment",
+			Method:      http.MethodPost,
+			Path:        "/comments/{commentId}/report",
+			Summary:     "Report comment",
+		}, api.HandleReportComment)
+
 		huma.Register(humaApi, huma.Operation{
 			OperationID: "telegram-webhook",
 			Method:      http.MethodPost,
@@ -300,13 +495,229 @@ func main() {
 		}, api.HandleUpdateDisplayName)
 
 		huma.Register(humaApi, huma.Operation{
-			OperationID: "upload-avatar",
-			Method:      http.MethodPost,
-			Path:        "/profile/{id}/avatar",
-			Summary:     "Upload avatar",
+			OperationID: "update-privacy",
+			Method:      http.MethodPatch,
+			Path:        "/profile/{id}/privacy",
+			Summary:     "Update profile visibility",
+		}, api.HandleUpdatePrivacy)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID:  "upload-avatar",
+			Method:       http.MethodPost,
+			Path:         "/profile/{id}/avatar",
+			Summary:      "Upload avatar",
+			MaxBodyBytes: data.AvatarMaxUploadSize + 1<<20,
 		}, api.HandleUploadAvatar)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "remove-avatar",
+			Method:      http.MethodDelete,
+			Path:        "/profile/{id}/avatar",
+			Summary:     "Remove custom avatar",
+		}, api.HandleRemoveAvatar)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "get-library",
+			Method:      http.MethodGet,
+			Path:        "/library",
+			Summary:     "List library entries",
+		}, api.HandleGetLibrary)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "get-library-entry",
+			Method:      http.MethodGet,
+			Path:        "/library/{novelId}",
+			Summary:     "Get library entry for novel",
+		}, api.HandleGetLibraryEntry)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "set-library-entry",
+			Method:      http.MethodPut,
+			Path:        "/library/{novelId}",
+			Summary:     "Add or move novel in library",
+		}, api.HandleSetLibraryEntry)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "delete-library-entry",
+			Method:      http.MethodDelete,
+			Path:        "/library/{novelId}",
+			Summary:     "Remove novel from library",
+		}, api.HandleDeleteLibraryEntry)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "get-reading-progress-list",
+			Method:      http.MethodGet,
+			Path:        "/progress",
+			Summary:     "List reading progress",
+		}, api.HandleGetReadingProgressList)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "get-reading-progress",
+			Method:      http.MethodGet,
+			Path:        "/progress/{novelId}",
+			Summary:     "Get reading progress for novel",
+		}, api.HandleGetReadingProgress)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "save-reading-progress",
+			Method:      http.MethodPut,
+			Path:        "/progress/{novelId}",
+			Summary:     "Save reading position",
+		}, api.HandleSaveReadingProgress)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "get-history",
+			Method:      http.MethodGet,
+			Path:        "/history",
+			Summary:     "List reading history",
+		}, api.HandleGetHistory)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "record-history",
+			Method:      http.MethodPost,
+			Path:        "/history",
+			Summary:     "Record chapter visit",
+		}, api.HandleRecordHistory)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "clear-history",
+			Method:      http.MethodDelete,
+			Path:        "/history",
+			Summary:     "Clear reading history",
+		}, api.HandleClearHistory)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "get-follow-status",
+			Method:      http.MethodGet,
+			Path:        "/novels/{id}/follow",
+			Summary:     "Get follow status for novel",
+		}, api.HandleGetFollowStatus)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "follow-novel",
+			Method:      http.MethodPut,
+			Path:        "/novels/{id}/follow",
+			Summary:     "Follow novel",
+		}, api.HandleFollowNovel)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "unfollow-novel",
+			Method:      http.MethodDelete,
+			Path:        "/novels/{id}/follow",
+			Summary:     "Unfollow novel",
+		}, api.HandleUnfollowNovel)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "get-novel-rating",
+			Method:      http.MethodGet,
+			Path:        "/novels/{id}/rating",
+			Summary:     "Get novel rating summary",
+		}, api.HandleGetRatingSummary)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "get-my-rating",
+			Method:      http.MethodGet,
+			Path:        "/novels/{id}/rating/me",
+			Summary:     "Get own rating and review for novel",
+		}, api.HandleGetUserRating)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "rate-novel",
+			Method:      http.MethodPut,
+			Path:        "/novels/{id}/rating",
+			Summary:     "Rate novel",
+		}, api.HandleRateNovel)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "unrate-novel",
+			Method:      http.MethodDelete,
+			Path:        "/novels/{id}/rating",
+			Summary:     "Remove novel rating",
+		}, api.HandleUnrateNovel)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "get-reviews",
+			Method:      http.MethodGet,
+			Path:        "/novels/{id}/reviews",
+			Summary:     "Get novel reviews",
+		}, api.HandleGetReviews)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "create-review",
+			Method:      http.MethodPost,
+			Path:        "/novels/{id}/reviews",
+			Summary:     "Create or update own novel review",
+		}, api.HandleCreateReview)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "delete-review",
+			Method:      http.MethodDelete,
+			Path:        "/novels/{id}/reviews",
+			Summary:     "Delete own novel review",
+		}, api.HandleDeleteReview)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "get-notifications",
+			Method:      http.MethodGet,
+			Path:        "/notifications",
+			Summary:     "List notifications",
+		}, api.HandleGetNotifications)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "get-unread-notifications-count",
+			Method:      http.MethodGet,
+			Path:        "/notifications/unread-count",
+			Summary:     "Count unread notifications",
+		}, api.HandleGetUnreadNotificationsCount)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "mark-notifications-read",
+			Method:      http.MethodPost,
+			Path:        "/notifications/read",
+			Summary:     "Mark notifications as read",
+		}, api.HandleMarkNotificationsRead)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "get-push-public-key",
+			Method:      http.MethodGet,
+			Path:        "/push/public-key",
+			Summary:     "Get VAPID public key",
+		}, api.HandleGetPushPublicKey)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "save-push-subscription",
+			Method:      http.MethodPost,
+			Path:        "/push/subscriptions",
+			Summary:     "Register push subscription",
+		}, api.HandleSavePushSubscription)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "delete-push-subscription",
+			Method:      http.MethodDelete,
+			Path:        "/push/subscriptions",
+			Summary:     "Remove push subscription",
+		}, api.HandleDeletePushSubscription)
+
+		huma.Register(humaApi, huma.Operation{
+			OperationID: "test-push",
+			Method:      http.MethodPost,
+			Path:        "/push/test",
+			Summary:     "Send test push to own devices",
+		}, api.HandleTestPush)
 	})
 
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	go data.StartViewCounter(jobsCtx)
+	go data.StartRankingsRefresher(jobsCtx)
+	go data.StartSimilarRefresher(jobsCtx)
+	go data.StartHistoryCleaner(jobsCtx)
+	go data.StartNotificationsCleaner(jobsCtx)
+	go data.StartLoginAttemptsCleaner(jobsCtx)
+	go data.StartProfileCleaner(jobsCtx)
+	go data.StartPushDelivery(jobsCtx)
+
 	go func() {
 		logger.Info("Warming up sitemap cache...")
 		if _, err := data.GetSitemapData(context.Background()); err != nil {
@@ -346,5 +757,10 @@ func main() {
 		logger.Error("Server forced to shutdown: %v", err)
 	}
 
+	stopJobs()
+	if err := data.FlushViews(ctx); err != nil {
+		logger.Warn("Failed to flush pending views: %v", err)
+	}
+
 	logger.Info("Server exited properly")
 }
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/ch1kulya/kappalib/internal/webpush"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage:")
+	fmt.Fprintln(os.Stderr, "  webpush keys                 generate a VAPID key pair")
+	fmt.Fprintln(os.Stderr, "  webpush stub [flags]         run a local push service stand-in")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "keys":
+		generateKeys()
+	case "stub":
+		runStub(os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func generateKeys() {
+	publicKey, privateKey, err := webpush.GenerateVAPIDKeys()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to generate keys: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("VAPID_PUBLIC_KEY=%s\n", publicKey)
+	fmt.Printf("VAPID_PRIVATE_KEY=%s\n", privateKey)
+}
+
+func runStub(args []string) {
+	fs := flag.NewFlagSet("stub", flag.ExitOnError)
+	addr := fs.String("addr", "127.0.0.1:8099", "listen address")
+	failFirst := fs.Int("fail", 0, "respond 503 to the first N deliveries")
+	gone := fs.Bool("gone", false, "respond 410 Gone to every delivery")
+	fs.Parse(args)
+
+	uaKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to generate subscription key: %v\n", err)
+		os.Exit(1)
+	}
+	authSecret := make([]byte, 16)
+	rand.Read(authSecret)
+
+	token := make([]byte, 12)
+	rand.Read(token)
+	path := "/push/" + base64.RawURLEncoding.EncodeToString(token)
+	endpoint := "http://" + *addr + path
+
+	subscription, _ := json.Marshal(map[string]any{
+		"endpoint": endpoint,
+		"keys": map[string]string{
+			"p256dh": base64.RawURLEncoding.EncodeToString(uaKey.PublicKey().Bytes()),
+			"auth":   base64.RawURLEncoding.EncodeToString(authSecret),
+		},
+	})
+
+	fmt.Println("Push service stand-in is running. Register this subscription")
+	fmt.Println("(the server needs WEBPUSH_ALLOW_INSECURE_ENDPOINTS=true):")
+	fmt.Println()
+	fmt.Printf("curl -X POST http://localhost:8080/api/push/subscriptions \\\n")
+	fmt.Printf("  -H 'Content-Type: application/json' \\\n")
+	fmt.Printf("  -H 'X-Profile-ID: <profile id>' -H 'X-Secret-Token: <secret token>' \\\n")
+	fmt.Printf("  -d '%s'\n\n", subscription)
+
+	var deliveries atomic.Int64
+
+	http.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		n := deliveries.Add(1)
+
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if err := webpush.VerifyAuthorization(r.Header.Get("Authorization"), endpoint, time.Now()); err != nil {
+			fmt.Printf("#%d rejected: %v\n", n, err)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("Content-Encoding") != "aes128gcm" {
+			fmt.Printf("#%d rejected: unexpected Content-Encoding %q\n", n, r.Header.Get("Content-Encoding"))
+			http.Error(w, "unsupported encoding", http.StatusUnsupportedMediaType)
+			return
+		}
+		if r.Header.Get("TTL") == "" {
+			fmt.Printf("#%d rejected: missing TTL header\n", n)
+			http.Error(w, "missing TTL", http.StatusBadRequest)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, 4096+1))
+		if err != nil || len(body) > 4096 {
+			fmt.Printf("#%d rejected: payload too large\n", n)
+			http.Error(w, "payload too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		payload, err := webpush.Decrypt(body, uaKey, authSecret)
+		if err != nil {
+			fmt.Printf("#%d rejected: decryption failed: %v\n", n, err)
+			http.Error(w, "decryption failed", http.StatusBadRequest)
+			return
+		}
+
+		if *gone {
+			fmt.Printf("#%d answering 410 Gone: %s\n", n, payload)
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+		if n <= int64(*failFirst) {
+			fmt.Printf("#%d answering 503: %s\n", n, payload)
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		fmt.Printf("#%d delivered (TTL=%s, Urgency=%s, Topic=%s): %s\n",
+			n, r.Header.Get("TTL"), r.Header.Get("Urgency"), r.Header.Get("Topic"), payload)
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	if err := http.ListenAndServe(*addr, nil); err != nil {
+		fmt.Fprintf(os.Stderr, "Stand-in stopped: %v\n", err)
+		os.Exit(1)
+	}
+}
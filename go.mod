@@ -3,6 +3,7 @@ module github.com/ch1kulya/kappalib
 go 1.25.3
 
 require (
+	github.com/HugoSmits86/nativewebp v0.9.3
 	github.com/a-h/templ v0.3.960
 	github.com/ch1kulya/logger v1.0.4
 	github.com/danielgtaylor/huma/v2 v2.34.1
@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"os"
@@ -20,7 +21,11 @@ import (
 
 type GetNovelsInput struct {
 	Page int    `query:"page" default:"1" minimum:"1" maximum:"9999"`
-	Sort string `query:"sort" default:"oldest" enum:"newest,oldest,large,small,alphabet,created"`
+	Sort string `query:"sort" default:"oldest" enum:"newest,oldest,large,small,alphabet,created,trending,popular,rating"`
+}
+
+type GetUpdatesInput struct {
+	Page int `query:"page" default:"1" minimum:"1" maximum:"9999"`
 }
 
 type SearchNovelsInput struct {
@@ -32,17 +37,43 @@ type IDInput struct {
 }
 
 type CreateProfileInput struct {
-	Body struct {
+	UserAgent string `header:"User-Agent"`
+	Body      struct {
 		TurnstileToken string `json:"turnstile_token" minLength:"1"`
 	}
 }
 
 type LoginInput struct {
-	Body struct {
+	UserAgent string `header:"User-Agent"`
+	Body      struct {
 		SyncCode string `json:"sync_code" minLength:"8" maxLength:"8"`
 	}
 }
 
+type GenerateSyncCodeInput struct {
+	ProfileID   string `path:"id"`
+	SecretToken string `header:"X-Secret-Token" required:"true"`
+	Body        *struct {
+		RequireConfirmation bool `json:"require_confirmation"`
+	}
+}
+
+type CompleteLoginInput struct {
+	RequestID string `path:"requestId"`
+	Body      struct {
+		PollToken string `json:"poll_token" minLength:"1"`
+	}
+}
+
+type ResolveLoginRequestInput struct {
+	ProfileID   string `path:"id"`
+	RequestID   string `path:"requestId"`
+	SecretToken string `header:"X-Secret-Token" required:"true"`
+	Body        struct {
+		Approve bool `json:"approve"`
+	}
+}
+
 type SyncCookiesInput struct {
 	ProfileID   string `header:"X-Profile-ID" required:"true"`
 	SecretToken string `header:"X-Secret-Token" required:"true"`
@@ -60,14 +91,89 @@ type AuthenticatedProfileInput struct {
 	SecretToken string `header:"X-Secret-Token" required:"true"`
 }
 
+type RevokeSessionInput struct {
+	ProfileID   string `path:"id"`
+	SessionID   string `path:"sessionId"`
+	SecretToken string `header:"X-Secret-Token" required:"true"`
+}
+
+type BlockUserInput struct {
+	ProfileID   string `path:"id"`
+	UserID      string `path:"userId"`
+	SecretToken string `header:"X-Secret-Token" required:"true"`
+	Body        struct {
+		Kind string `json:"kind" enum:"mute,block" default:"block"`
+	}
+}
+
+type UnblockUserInput struct {
+	ProfileID   string `path:"id"`
+	UserID      string `path:"userId"`
+	SecretToken string `header:"X-Secret-Token" required:"true"`
+}
+
 type APIStatus struct {
 	Status   string `json:"status"`
 	Database string `json:"database"`
 }
 
 type GetCommentsInput struct {
-	ChapterID string `path:"chapterId"`
-	Page      int    `query:"page" default:"1" minimum:"1" maximum:"9999"`
+	ChapterID   string `path:"chapterId"`
+	Page        int    `query:"page" default:"1" minimum:"1" maximum:"9999"`
+	Sort        string `query:"sort" default:"newest" enum:"newest,oldest,top"`
+	ProfileID   string `header:"X-Profile-ID"`
+	SecretToken string `header:"X-Secret-Token"`
+}
+
+type GetCommentRepliesInput struct {
+	CommentID   string `path:"commentId"`
+	ProfileID   string `header:"X-Profile-ID"`
+	SecretToken string `header:"X-Secret-Token"`
+}
+
+type EditCommentInput struct {
+	CommentID   string `path:"commentId"`
+	ProfileID   string `header:"X-Profile-ID" required:"true"`
+	SecretToken string `header:"X-Secret-Token" required:"true"`
+	Body        struct {
+		Content string `json:"content" minLength:"1" maxLength:"1000"`
+	}
+}
+
+type DeleteCommentInput struct {
+	CommentID   string `path:"commentId"`
+	ProfileID   string `header:"X-Profile-ID" required:"true"`
+	SecretToken string `header:"X-Secret-Token" required:"true"`
+}
+
+type CommentReactionInput struct {
+	CommentID   string `path:"commentId"`
+	ProfileID   string `header:"X-Profile-ID" required:"true"`
+	SecretToken string `header:"X-Secret-Token" required:"true"`
+}
+
+type SetCommentReactionInput struct {
+	CommentID   string `path:"commentId"`
+	ProfileID   string `header:"X-Profile-ID" required:"true"`
+	SecretToken string `header:"X-Secret-Token" required:"true"`
+	Body        struct {
+		Reaction string `json:"reaction" enum:"like,dislike"`
+	}
+}
+
+type ReportCommentInput struct {
+	CommentID   string `path:"commentId"`
+	ProfileID   string `header:"X-Profile-ID" required:"true"`
+	SecretToken string `header:"X-Secret-Token" required:"true"`
+	Body        struct {
+		Reason string `json:"reason" enum:"spam,offensive,spoiler,other"`
+	}
+}
+
+type GetChapterReactionsInput struct {
+	ChapterID   string `path:"chapterId"`
+	ProfileID   string `header:"X-Profile-ID" required:"true"`
+	SecretToken string `header:"X-Secret-Token" required:"true"`
 }
 
 type CreateCommentAPIInput struct {
@@ -76,6 +182,7 @@ type CreateCommentAPIInput struct {
 	SecretToken string `header:"X-Secret-Token" required:"true"`
 	Body        struct {
 		Content        string `json:"content" minLength:"1" maxLength:"1000"`
+		ParentID       string `json:"parent_id,omitempty" maxLength:"20"`
 		TurnstileToken string `json:"turnstile_token" minLength:"1"`
 	}
 }
@@ -93,14 +200,191 @@ type UpdateDisplayNameInput struct {
 	}
 }
 
+type UpdatePrivacyInput struct {
+	ProfileID   string `path:"id"`
+	SecretToken string `header:"X-Secret-Token" required:"true"`
+	Body        struct {
+		IsPublic bool `json:"is_public"`
+	}
+}
+
 type UploadAvatarInput struct {
 	ProfileID   string `path:"id"`
 	SecretToken string `header:"X-Secret-Token" required:"true"`
+	RawBody     huma.MultipartFormFiles[struct {
+		Image huma.FormFile `form:"image" contentType:"image/jpeg,image/png,image/webp,image/gif" required:"true"`
+	}]
+}
+
+type ExportArchiveOutput struct {
+	ContentType        string `header:"Content-Type"`
+	ContentDisposition string `header:"Content-Disposition"`
+	Body               []byte
+}
+
+type ImportProfileInput struct {
+	UserAgent string `header:"User-Agent"`
+	Body      struct {
+		TurnstileToken string `json:"turnstile_token" minLength:"1"`
+		Archive        string `json:"archive" minLength:"1"`
+	}
+}
+
+type UpdateReaderSettingsInput struct {
+	ProfileID   string `path:"id"`
+	SecretToken string `header:"X-Secret-Token" required:"true"`
+	Body        models.ReaderSettings
+}
+
+type ReaderSettingsCookieOutput struct {
+	SetCookie http.Cookie `header:"Set-Cookie"`
+}
+
+type GetLibraryInput struct {
+	ProfileID   string `header:"X-Profile-ID" required:"true"`
+	SecretToken string `header:"X-Secret-Token" required:"true"`
+	Shelf       string `query:"shelf" maxLength:"20"`
+}
+
+type LibraryEntryInput struct {
+	NovelID     string `path:"novelId"`
+	ProfileID   string `header:"X-Profile-ID" required:"true"`
+	SecretToken string `header:"X-Secret-Token" required:"true"`
+}
+
+type SetLibraryEntryInput struct {
+	NovelID     string `path:"novelId"`
+	ProfileID   string `header:"X-Profile-ID" required:"true"`
+	SecretToken string `header:"X-Secret-Token" required:"true"`
+	Body        struct {
+		Shelf  string `json:"shelf" enum:"reading,planned,completed,dropped"`
+		Rating *int   `json:"rating,omitempty" minimum:"1" maximum:"10"`
+	}
+}
+
+type GetReadingProgressListInput struct {
+	ProfileID   string `header:"X-Profile-ID" required:"true"`
+	SecretToken string `header:"X-Secret-Token" required:"true"`
+	Limit       int    `query:"limit" default:"20" minimum:"1" maximum:"100"`
+}
+
+type ReadingProgressInput struct {
+	NovelID     string `path:"novelId"`
+	ProfileID   string `header:"X-Profile-ID" required:"true"`
+	SecretToken string `header:"X-Secret-Token" required:"true"`
+}
+
+type SaveReadingProgressInput struct {
+	NovelID     string `path:"novelId"`
+	ProfileID   string `header:"X-Profile-ID" required:"true"`
+	SecretToken string `header:"X-Secret-Token" required:"true"`
+	Body        struct {
+		ChapterID     string  `json:"chapter_id" minLength:"1" maxLength:"20"`
+		Paragraph     int     `json:"paragraph" minimum:"0"`
+		ScrollPercent float64 `json:"scroll_percent" minimum:"0" maximum:"100"`
+	}
+}
+
+type GetHistoryInput struct {
+	ProfileID   string `header:"X-Profile-ID" required:"true"`
+	SecretToken string `header:"X-Secret-Token" required:"true"`
+	Page        int    `query:"page" default:"1" minimum:"1"`
+}
+
+type RecordHistoryInput struct {
+	ProfileID   string `header:"X-Profile-ID" required:"true"`
+	SecretToken string `header:"X-Secret-Token" required:"true"`
+	Body        struct {
+		ChapterID string `json:"chapter_id" minLength:"1" maxLength:"20"`
+	}
+}
+
+type ClearHistoryInput struct {
+	ProfileID   string `header:"X-Profile-ID" required:"true"`
+	SecretToken string `header:"X-Secret-Token" required:"true"`
+}
+
+type FollowInput struct {
+	NovelID     string `path:"id"`
+	ProfileID   string `header:"X-Profile-ID" required:"true"`
+	SecretToken string `header:"X-Secret-Token" required:"true"`
+}
+
+type RatingInput struct {
+	NovelID     string `path:"id"`
+	ProfileID   string `header:"X-Profile-ID" required:"true"`
+	SecretToken string `header:"X-Secret-Token" required:"true"`
+}
+
+type RateNovelInput struct {
+	NovelID     string `path:"id"`
+	ProfileID   string `header:"X-Profile-ID" required:"true"`
+	SecretToken string `header:"X-Secret-Token" required:"true"`
+	Body        struct {
+		Score int `json:"score" minimum:"1" maximum:"10"`
+	}
+}
+
+type GetReviewsInput struct {
+	NovelID string `path:"id"`
+	Page    int    `query:"page" default:"1" minimum:"1" maximum:"9999"`
+}
+
+type CreateReviewAPIInput struct {
+	NovelID     string `path:"id"`
+	ProfileID   string `header:"X-Profile-ID" required:"true"`
+	SecretToken string `header:"X-Secret-Token" required:"true"`
+	Body        struct {
+		Content        string `json:"content" minLength:"1" maxLength:"20000"`
+		TurnstileToken string `json:"turnstile_token" minLength:"1"`
+	}
+}
+
+type GetNotificationsInput struct {
+	ProfileID   string `header:"X-Profile-ID" required:"true"`
+	SecretToken string `header:"X-Secret-Token" required:"true"`
+	Page        int    `query:"page" default:"1" minimum:"1"`
+}
+
+type UnreadNotificationsInput struct {
+	ProfileID   string `header:"X-Profile-ID" required:"true"`
+	SecretToken string `header:"X-Secret-Token" required:"true"`
+}
+
+type MarkNotificationsReadInput struct {
+	ProfileID   string `header:"X-Profile-ID" required:"true"`
+	SecretToken string `header:"X-Secret-Token" required:"true"`
+	Body        struct {
+		IDs []int64 `json:"ids,omitempty" maxItems:"100"`
+	}
+}
+
+type SavePushSubscriptionInput struct {
+	ProfileID   string `header:"X-Profile-ID" required:"true"`
+	SecretToken string `header:"X-Secret-Token" required:"true"`
+	UserAgent   string `header:"User-Agent"`
+	Body        struct {
+		Endpoint string `json:"endpoint" minLength:"1" maxLength:"2048"`
+		Keys     struct {
+			P256dh string `json:"p256dh" minLength:"1" maxLength:"200"`
+			Auth   string `json:"auth" minLength:"1" maxLength:"50"`
+		} `json:"keys"`
+	}
+}
+
+type DeletePushSubscriptionInput struct {
+	ProfileID   string `header:"X-Profile-ID" required:"true"`
+	SecretToken string `header:"X-Secret-Token" required:"true"`
 	Body        struct {
-		Image string `json:"image" minLength:"1"`
+		Endpoint string `json:"endpoint" minLength:"1" maxLength:"2048"`
 	}
 }
 
+type TestPushInput struct {
+	ProfileID   string `header:"X-Profile-ID" required:"true"`
+	SecretToken string `header:"X-Secret-Token" required:"true"`
+}
+
 func HandleStatus(ctx context.Context, input *struct{}) (*struct{ Body APIStatus }, error) {
 	dbStatus := "connected"
 	if err := database.DB.Ping(ctx); err != nil {
@@ -128,6 +412,14 @@ func HandleGetNovels(ctx context.Context, input *GetNovelsInput) (*struct{ Body
 	return &struct{ Body any }{Body: novels}, nil
 }
 
+func HandleGetUpdates(ctx context.Context, input *GetUpdatesInput) (*struct{ Body any }, error) {
+	updates, err := data.GetRecentUpdates(ctx, input.Page, 20)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("Failed to fetch updates")
+	}
+	return &struct{ Body any }{Body: updates}, nil
+}
+
 func HandleSearchNovels(ctx context.Context, input *SearchNovelsInput) (*struct{ Body any }, error) {
 	if input.Query == "" {
 		return nil, huma.Error400BadRequest("Search query is required")
@@ -154,6 +446,14 @@ func HandleGetNovel(ctx context.Context, input *IDInput) (*struct{ Body any }, e
 	return &struct{ Body any }{Body: novel}, nil
 }
 
+func HandleGetSimilarNovels(ctx context.Context, input *IDInput) (*struct{ Body any }, error) {
+	similar, err := data.GetSimilarNovels(ctx, input.ID, 12)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("Failed to fetch similar novels")
+	}
+	return &struct{ Body any }{Body: similar}, nil
+}
+
 func HandleGetChaptersList(ctx context.Context, input *IDInput) (*struct{ Body any }, error) {
 	chapters, err := data.GetChapters(ctx, input.ID)
 	if err != nil {
@@ -171,7 +471,7 @@ func HandleGetChapter(ctx context.Context, input *IDInput) (*struct{ Body any },
 }
 
 func HandleCreateProfile(ctx context.Context, input *CreateProfileInput) (*struct{ Body any }, error) {
-	profile, err := data.CreateProfile(ctx, input.Body.TurnstileToken)
+	profile, err := data.CreateProfile(ctx, input.Body.TurnstileToken, input.UserAgent)
 	if err != nil {
 		return nil, huma.Error400BadRequest("Captcha verification failed")
 	}
@@ -186,8 +486,9 @@ func HandleGetProfile(ctx context.Context, input *ProfileIDInput) (*struct{ Body
 	return &struct{ Body any }{Body: profile}, nil
 }
 
-func HandleGenerateSyncCode(ctx context.Context, input *AuthenticatedProfileInput) (*struct{ Body any }, error) {
-	result, err := data.GenerateSyncCode(ctx, input.ProfileID, input.SecretToken)
+func HandleGenerateSyncCode(ctx context.Context, input *GenerateSyncCodeInput) (*struct{ Body any }, error) {
+	requireConfirmation := input.Body != nil && input.Body.RequireConfirmation
+	result, err := data.GenerateSyncCode(ctx, input.ProfileID, input.SecretToken, requireConfirmation)
 	if err != nil {
 		return nil, huma.Error403Forbidden("Invalid secret token")
 	}
@@ -195,13 +496,59 @@ func HandleGenerateSyncCode(ctx context.Context, input *AuthenticatedProfileInpu
 }
 
 func HandleLogin(ctx context.Context, input *LoginInput) (*struct{ Body any }, error) {
-	result, err := data.LoginWithSyncCode(ctx, input.Body.SyncCode)
+	ip := clientIPFromContext(ctx)
+	result, err := data.LoginWithSyncCode(ctx, input.Body.SyncCode, input.UserAgent, ip)
+	if err != nil {
+		switch err.Error() {
+		case "too many attempts":
+			seconds := int(data.LoginRetryAfter(ip).Seconds()) + 1
+			return nil, huma.Error429TooManyRequests(fmt.Sprintf("Too many login attempts, retry in %d seconds", seconds))
+		case "invalid sync code format", "invalid or expired sync code":
+			return nil, huma.Error404NotFound("Invalid or expired sync code")
+		}
+		return nil, huma.Error500InternalServerError("Failed to login")
+	}
+	return &struct{ Body any }{Body: result}, nil
+}
+
+func HandleCompleteLoginRequest(ctx context.Context, input *CompleteLoginInput) (*struct{ Body any }, error) {
+	result, err := data.CompleteLoginRequest(ctx, input.RequestID, input.Body.PollToken)
 	if err != nil {
-		return nil, huma.Error404NotFound("Invalid or expired sync code")
+		switch err.Error() {
+		case "login denied":
+			return nil, huma.Error403Forbidden("Login denied")
+		case "login request not found":
+			return nil, huma.Error404NotFound("Login request not found")
+		}
+		return nil, huma.Error500InternalServerError("Failed to complete login")
 	}
 	return &struct{ Body any }{Body: result}, nil
 }
 
+func HandleGetLoginRequests(ctx context.Context, input *AuthenticatedProfileInput) (*struct{ Body any }, error) {
+	requests, err := data.GetLoginRequests(ctx, input.ProfileID, input.SecretToken)
+	if err != nil {
+		if err.Error() == "invalid secret token" {
+			return nil, huma.Error403Forbidden("Invalid secret token")
+		}
+		return nil, huma.Error500InternalServerError("Failed to fetch login requests")
+	}
+	return &struct{ Body any }{Body: requests}, nil
+}
+
+func HandleResolveLoginRequest(ctx context.Context, input *ResolveLoginRequestInput) (*struct{}, error) {
+	if err := data.ResolveLoginRequest(ctx, input.ProfileID, input.SecretToken, input.RequestID, input.Body.Approve); err != nil {
+		switch err.Error() {
+		case "invalid secret token":
+			return nil, huma.Error403Forbidden("Invalid secret token")
+		case "login request not found":
+			return nil, huma.Error404NotFound("Login request not found")
+		}
+		return nil, huma.Error500InternalServerError("Failed to resolve login request")
+	}
+	return &struct{}{}, nil
+}
+
 func HandleSyncCookies(ctx context.Context, input *SyncCookiesInput) (*struct{ Body any }, error) {
 	if input.ProfileID == "" {
 		return nil, huma.Error401Unauthorized("X-Profile-ID header required")
@@ -225,54 +572,239 @@ func HandleDeleteProfile(ctx context.Context, input *AuthenticatedProfileInput)
 	return &struct{}{}, nil
 }
 
-func HandleGetComments(ctx context.Context, input *GetCommentsInput) (*struct{ Body any }, error) {
-	comments, err := data.GetApprovedComments(ctx, input.ChapterID, input.Page)
+func HandleGetSessions(ctx context.Context, input *AuthenticatedProfileInput) (*struct{ Body any }, error) {
+	sessions, err := data.GetSessions(ctx, input.ProfileID, input.SecretToken)
 	if err != nil {
-		return nil, huma.Error500InternalServerError("Failed to fetch comments")
+		if err.Error() == "invalid secret token" {
+			return nil, huma.Error403Forbidden("Invalid secret token")
+		}
+		return nil, huma.Error500InternalServerError("Failed to fetch sessions")
 	}
-	return &struct{ Body any }{Body: comments}, nil
+	return &struct{ Body any }{Body: sessions}, nil
 }
 
-func HandleCreateComment(ctx context.Context, input *CreateCommentAPIInput) (*struct{ Body any }, error) {
-	commentInput := models.CreateCommentInput{
-		ChapterID:      input.ChapterID,
-		Content:        input.Body.Content,
-		TurnstileToken: input.Body.TurnstileToken,
-	}
-
-	comment, err := data.CreateComment(ctx, input.ProfileID, input.SecretToken, commentInput)
-	if err != nil {
+func HandleRevokeSession(ctx context.Context, input *RevokeSessionInput) (*struct{}, error) {
+	if err := data.RevokeSession(ctx, input.ProfileID, input.SecretToken, input.SessionID); err != nil {
 		switch err.Error() {
-		case "rate limit exceeded":
-			return nil, huma.Error429TooManyRequests("Подождите 30 секунд перед отправкой следующего комментария")
-		case "captcha verification failed":
-			return nil, huma.Error400BadRequest("Captcha verification failed")
 		case "invalid secret token":
-			return nil, huma.Error403Forbidden("Invalid credentials")
-		case "invalid content length":
-			return nil, huma.Error400BadRequest("Comment must be 1-1000 characters")
-		case "chapter not found":
-			return nil, huma.Error404NotFound("Chapter not found")
+			return nil, huma.Error403Forbidden("Invalid secret token")
+		case "session not found":
+			return nil, huma.Error404NotFound("Session not found")
 		default:
-			return nil, huma.Error500InternalServerError("Failed to create comment")
+			return nil, huma.Error500InternalServerError("Failed to revoke session")
 		}
 	}
-	return &struct{ Body any }{Body: comment}, nil
+	return &struct{}{}, nil
 }
 
-func HandleTelegramWebhook(ctx context.Context, input *TelegramWebhookInput) (*struct{}, error) {
-	expectedSecret := data.GetTelegramWebhookSecret()
-	if expectedSecret != "" && input.WebhookSecret != expectedSecret {
-		logger.Warn("Telegram Webhook: Invalid secret token. Got: %s", input.WebhookSecret)
-		return nil, huma.Error403Forbidden("Invalid webhook secret")
+func HandleRevokeOtherSessions(ctx context.Context, input *AuthenticatedProfileInput) (*struct{ Body any }, error) {
+	revoked, err := data.RevokeOtherSessions(ctx, input.ProfileID, input.SecretToken)
+	if err != nil {
+		if err.Error() == "invalid secret token" {
+			return nil, huma.Error403Forbidden("Invalid secret token")
+		}
+		return nil, huma.Error500InternalServerError("Failed to revoke sessions")
 	}
+	return &struct{ Body any }{Body: map[string]int64{"revoked": revoked}}, nil
+}
 
-	bodyStr := string(input.Body)
-	logger.Debug("Telegram Webhook Payload: %s", bodyStr)
+func HandleGetBlockedUsers(ctx context.Context, input *AuthenticatedProfileInput) (*struct{ Body any }, error) {
+	blocked, err := data.GetBlockedUsers(ctx, input.ProfileID, input.SecretToken)
+	if err != nil {
+		if err.Error() == "invalid secret token" {
+			return nil, huma.Error403Forbidden("Invalid secret token")
+		}
+		return nil, huma.Error500InternalServerError("Failed to fetch blocked users")
+	}
+	return &struct{ Body any }{Body: blocked}, nil
+}
 
-	type TelegramUpdate struct {
-		UpdateID      int64 `json:"update_id"`
-		CallbackQuery *struct {
+func HandleBlockUser(ctx context.Context, input *BlockUserInput) (*struct{}, error) {
+	if err := data.BlockUser(ctx, input.ProfileID, input.SecretToken, input.UserID, input.Body.Kind); err != nil {
+		switch err.Error() {
+		case "invalid secret token":
+			return nil, huma.Error403Forbidden("Invalid secret token")
+		case "invalid kind", "cannot block yourself":
+			return nil, huma.Error400BadRequest("Cannot block this user")
+		case "user not found":
+			return nil, huma.Error404NotFound("User not found")
+		case "too many blocked users":
+			return nil, huma.Error422UnprocessableEntity("Too many blocked users")
+		default:
+			return nil, huma.Error500InternalServerError("Failed to block user")
+		}
+	}
+	return &struct{}{}, nil
+}
+
+func HandleUnblockUser(ctx context.Context, input *UnblockUserInput) (*struct{}, error) {
+	if err := data.UnblockUser(ctx, input.ProfileID, input.SecretToken, input.UserID); err != nil {
+		switch err.Error() {
+		case "invalid secret token":
+			return nil, huma.Error403Forbidden("Invalid secret token")
+		case "not blocked":
+			return nil, huma.Error404NotFound("User is not blocked")
+		default:
+			return nil, huma.Error500InternalServerError("Failed to unblock user")
+		}
+	}
+	return &struct{}{}, nil
+}
+
+func HandleGetComments(ctx context.Context, input *GetCommentsInput) (*struct{ Body any }, error) {
+	comments, err := data.GetApprovedComments(ctx, input.ChapterID, input.Page, input.Sort, input.ProfileID, input.SecretToken)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("Failed to fetch comments")
+	}
+	return &struct{ Body any }{Body: comments}, nil
+}
+
+func HandleGetCommentReplies(ctx context.Context, input *GetCommentRepliesInput) (*struct{ Body any }, error) {
+	replies, err := data.GetCommentReplies(ctx, input.CommentID, input.ProfileID, input.SecretToken)
+	if err != nil {
+		if err.Error() == "comment not found" {
+			return nil, huma.Error404NotFound("Comment not found")
+		}
+		return nil, huma.Error500InternalServerError("Failed to fetch replies")
+	}
+	return &struct{ Body any }{Body: replies}, nil
+}
+
+func HandleCreateComment(ctx context.Context, input *CreateCommentAPIInput) (*struct{ Body any }, error) {
+	commentInput := models.CreateCommentInput{
+		ChapterID:      input.ChapterID,
+		ParentID:       input.Body.ParentID,
+		Content:        input.Body.Content,
+		TurnstileToken: input.Body.TurnstileToken,
+	}
+
+	comment, err := data.CreateComment(ctx, input.ProfileID, input.SecretToken, commentInput)
+	if err != nil {
+		switch err.Error() {
+		case "rate limit exceeded":
+			return nil, huma.Error429TooManyRequests("Подождите 30 секунд перед отправкой следующего комментария")
+		case "captcha verification failed":
+			return nil, huma.Error400BadRequest("Captcha verification failed")
+		case "invalid secret token":
+			return nil, huma.Error403Forbidden("Invalid credentials")
+		case "invalid content length":
+			return nil, huma.Error400BadRequest("Comment must be 1-1000 characters")
+		case "chapter not found":
+			return nil, huma.Error404NotFound("Chapter not found")
+		case "parent not found":
+			return nil, huma.Error404NotFound("Parent comment not found")
+		case "blocked by author":
+			return nil, huma.Error403Forbidden("Автор комментария ограничил ответы от вас")
+		default:
+			return nil, huma.Error500InternalServerError("Failed to create comment")
+		}
+	}
+	return &struct{ Body any }{Body: comment}, nil
+}
+
+func HandleEditComment(ctx context.Context, input *EditCommentInput) (*struct{ Body any }, error) {
+	comment, err := data.EditComment(ctx, input.ProfileID, input.SecretToken, input.CommentID, input.Body.Content)
+	if err != nil {
+		switch err.Error() {
+		case "rate limit exceeded":
+			return nil, huma.Error429TooManyRequests("Подождите 30 секунд перед следующим изменением")
+		case "invalid secret token":
+			return nil, huma.Error403Forbidden("Invalid credentials")
+		case "invalid content length":
+			return nil, huma.Error400BadRequest("Comment must be 1-1000 characters")
+		case "comment not found":
+			return nil, huma.Error404NotFound("Comment not found")
+		default:
+			return nil, huma.Error500InternalServerError("Failed to edit comment")
+		}
+	}
+	return &struct{ Body any }{Body: comment}, nil
+}
+
+func HandleDeleteComment(ctx context.Context, input *DeleteCommentInput) (*struct{}, error) {
+	if err := data.DeleteComment(ctx, input.ProfileID, input.SecretToken, input.CommentID); err != nil {
+		switch err.Error() {
+		case "invalid secret token":
+			return nil, huma.Error403Forbidden("Invalid credentials")
+		case "comment not found":
+			return nil, huma.Error404NotFound("Comment not found")
+		default:
+			return nil, huma.Error500InternalServerError("Failed to delete comment")
+		}
+	}
+	return &struct{}{}, nil
+}
+
+func HandleReportComment(ctx context.Context, input *ReportCommentInput) (*struct{}, error) {
+	if err := data.ReportComment(ctx, input.ProfileID, input.SecretToken, input.CommentID, input.Body.Reason); err != nil {
+		switch err.Error() {
+		case "invalid secret token":
+			return nil, huma.Error403Forbidden("Invalid credentials")
+		case "invalid reason":
+			return nil, huma.Error400BadRequest("Invalid reason")
+		case "comment not found":
+			return nil, huma.Error404NotFound("Comment not found")
+		case "cannot report own comment":
+			return nil, huma.Error400BadRequest("Cannot report own comment")
+		case "already reported":
+			return nil, huma.Error409Conflict("Comment already reported")
+		default:
+			return nil, huma.Error500InternalServerError("Failed to report comment")
+		}
+	}
+	return &struct{}{}, nil
+}
+
+func reactionError(err error) error {
+	switch err.Error() {
+	case "invalid secret token":
+		return huma.Error403Forbidden("Invalid credentials")
+	case "invalid reaction":
+		return huma.Error400BadRequest("Invalid reaction")
+	case "comment not found":
+		return huma.Error404NotFound("Comment not found")
+	default:
+		return huma.Error500InternalServerError("Failed to update reaction")
+	}
+}
+
+func HandleSetCommentReaction(ctx context.Context, input *SetCommentReactionInput) (*struct{ Body any }, error) {
+	reaction, err := data.SetCommentReaction(ctx, input.ProfileID, input.SecretToken, input.CommentID, input.Body.Reaction)
+	if err != nil {
+		return nil, reactionError(err)
+	}
+	return &struct{ Body any }{Body: reaction}, nil
+}
+
+func HandleDeleteCommentReaction(ctx context.Context, input *CommentReactionInput) (*struct{ Body any }, error) {
+	reaction, err := data.DeleteCommentReaction(ctx, input.ProfileID, input.SecretToken, input.CommentID)
+	if err != nil {
+		return nil, reactionError(err)
+	}
+	return &struct{ Body any }{Body: reaction}, nil
+}
+
+func HandleGetChapterReactions(ctx context.Context, input *GetChapterReactionsInput) (*struct{ Body any }, error) {
+	reactions, err := data.GetChapterReactions(ctx, input.ProfileID, input.SecretToken, input.ChapterID)
+	if err != nil {
+		return nil, reactionError(err)
+	}
+	return &struct{ Body any }{Body: map[string]any{"reactions": reactions}}, nil
+}
+
+func HandleTelegramWebhook(ctx context.Context, input *TelegramWebhookInput) (*struct{}, error) {
+	expectedSecret := data.GetTelegramWebhookSecret()
+	if expectedSecret != "" && input.WebhookSecret != expectedSecret {
+		logger.Warn("Telegram Webhook: Invalid secret token. Got: %s", input.WebhookSecret)
+		return nil, huma.Error403Forbidden("Invalid webhook secret")
+	}
+
+	bodyStr := string(input.Body)
+	logger.Debug("Telegram Webhook Payload: %s", bodyStr)
+
+	type TelegramUpdate struct {
+		UpdateID      int64 `json:"update_id"`
+		CallbackQuery *struct {
 			ID      string `json:"id"`
 			Data    string `json:"data"`
 			Message *struct {
@@ -308,24 +840,55 @@ func HandleTelegramWebhook(ctx context.Context, input *TelegramWebhookInput) (*s
 	}
 
 	action := parts[0]
-	commentID := parts[1]
+	targetID := parts[1]
 
 	var status string
 	var statusText string
 
 	switch action {
-	case "approve":
+	case "approve", "review_approve":
 		status = "approved"
 		statusText = "✅ Подтверждено"
-	case "reject":
+	case "reject", "review_reject":
 		status = "rejected"
 		statusText = "❌ Отклонено"
+	case "report_keep":
+		status = "approved"
+		statusText = "✅ Комментарий оставлен"
+	case "report_remove":
+		status = "rejected"
+		statusText = "🗑 Комментарий удалён"
+	case "avatar_approve":
+		status = "approved"
+		statusText = "✅ Аватар одобрен"
+	case "avatar_reject":
+		status = "rejected"
+		statusText = "❌ Аватар отклонён"
 	default:
 		logger.Warn("Unknown action in callback: %s", action)
 		return &struct{}{}, nil
 	}
 
-	if err := data.UpdateCommentStatus(ctx, commentID, status); err != nil {
+	if strings.HasPrefix(action, "avatar_") {
+		profileID, submissionID, _ := strings.Cut(targetID, ":")
+		if err := data.UpdateAvatarStatus(ctx, profileID, submissionID, status); err != nil {
+			logger.Error("Failed to update avatar via webhook: %v", err)
+			return &struct{}{}, nil
+		}
+	} else if strings.HasPrefix(action, "review_") {
+		if err := data.UpdateReviewStatus(ctx, targetID, status); err != nil {
+			logger.Error("Failed to update review via webhook: %v", err)
+			return &struct{}{}, nil
+		}
+	} else if strings.HasPrefix(action, "report_") {
+		if err := data.ResolveCommentReports(ctx, targetID, status); err != nil {
+			if err.Error() != "comment not found" {
+				logger.Error("Failed to resolve comment reports via webhook: %v", err)
+				return &struct{}{}, nil
+			}
+			logger.Warn("Reported comment %s is gone, dropping its report message", targetID)
+		}
+	} else if err := data.UpdateCommentStatus(ctx, targetID, status); err != nil {
 		logger.Error("Failed to update comment via webhook: %v", err)
 		return &struct{}{}, nil
 	}
@@ -369,14 +932,28 @@ func HandleUpdateDisplayName(ctx context.Context, input *UpdateDisplayNameInput)
 	return &struct{ Body any }{Body: profile}, nil
 }
 
-func HandleUploadAvatar(ctx context.Context, input *UploadAvatarInput) (*struct{ Body any }, error) {
-	imageData, err := base64.StdEncoding.DecodeString(input.Body.Image)
+func HandleUpdatePrivacy(ctx context.Context, input *UpdatePrivacyInput) (*struct{ Body any }, error) {
+	profile, err := data.UpdatePrivacy(ctx, input.ProfileID, input.SecretToken, input.Body.IsPublic)
 	if err != nil {
-		return nil, huma.Error400BadRequest("Invalid base64 image")
+		if strings.Contains(err.Error(), "invalid secret token") {
+			return nil, huma.Error403Forbidden("Invalid credentials")
+		}
+		return nil, huma.Error500InternalServerError("Failed to update profile")
 	}
+	return &struct{ Body any }{Body: profile}, nil
+}
 
-	if len(imageData) > 1<<20 {
-		return nil, huma.Error400BadRequest("Image too large (max 1MB)")
+func HandleUploadAvatar(ctx context.Context, input *UploadAvatarInput) (*struct{ Body any }, error) {
+	file := input.RawBody.Data().Image
+	defer file.Close()
+
+	if file.Size > data.AvatarMaxUploadSize {
+		return nil, huma.Error400BadRequest("Image too large (max 5MB)")
+	}
+
+	imageData, err := io.ReadAll(io.LimitReader(file, data.AvatarMaxUploadSize))
+	if err != nil {
+		return nil, huma.Error400BadRequest("Invalid image")
 	}
 
 	profile, err := data.UpdateAvatar(ctx, input.ProfileID, input.SecretToken, imageData)
@@ -387,7 +964,491 @@ func HandleUploadAvatar(ctx context.Context, input *UploadAvatarInput) (*struct{
 		if strings.Contains(err.Error(), "unsupported format") {
 			return nil, huma.Error400BadRequest("Unsupported format")
 		}
+		if strings.Contains(err.Error(), "image too large") {
+			return nil, huma.Error400BadRequest("Image dimensions too large")
+		}
 		return nil, huma.Error500InternalServerError("Upload failed")
 	}
 	return &struct{ Body any }{Body: profile}, nil
 }
+
+func HandleRemoveAvatar(ctx context.Context, input *AuthenticatedProfileInput) (*struct{ Body any }, error) {
+	profile, err := data.RemoveAvatar(ctx, input.ProfileID, input.SecretToken)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid secret token") {
+			return nil, huma.Error403Forbidden("Invalid credentials")
+		}
+		return nil, huma.Error500InternalServerError("Failed to remove avatar")
+	}
+	return &struct{ Body any }{Body: profile}, nil
+}
+
+func HandleExportProfile(ctx context.Context, input *AuthenticatedProfileInput) (*struct{ Body any }, error) {
+	export, err := data.ExportProfileData(ctx, input.ProfileID, input.SecretToken)
+	if err != nil {
+		if err.Error() == "invalid secret token" {
+			return nil, huma.Error403Forbidden("Invalid secret token")
+		}
+		return nil, huma.Error500InternalServerError("Failed to export profile")
+	}
+	return &struct{ Body any }{Body: export}, nil
+}
+
+func HandleExportProfileArchive(ctx context.Context, input *AuthenticatedProfileInput) (*ExportArchiveOutput, error) {
+	archive, err := data.ExportProfileArchive(ctx, input.ProfileID, input.SecretToken)
+	if err != nil {
+		if err.Error() == "invalid secret token" {
+			return nil, huma.Error403Forbidden("Invalid secret token")
+		}
+		return nil, huma.Error500InternalServerError("Failed to export profile")
+	}
+	return &ExportArchiveOutput{
+		ContentType:        "application/zip",
+		ContentDisposition: fmt.Sprintf(`attachment; filename="kappalib-%s.zip"`, input.ProfileID),
+		Body:               archive,
+	}, nil
+}
+
+func HandleImportProfile(ctx context.Context, input *ImportProfileInput) (*struct{ Body any }, error) {
+	archive, err := base64.StdEncoding.DecodeString(input.Body.Archive)
+	if err != nil {
+		return nil, huma.Error400BadRequest("Invalid base64 archive")
+	}
+
+	if len(archive) > 10<<20 {
+		return nil, huma.Error400BadRequest("Archive too large (max 10MB)")
+	}
+
+	result, err := data.ImportProfile(ctx, input.Body.TurnstileToken, input.UserAgent, archive)
+	if err != nil {
+		switch err.Error() {
+		case "invalid archive":
+			return nil, huma.Error400BadRequest("Invalid archive")
+		case "unsupported archive version":
+			return nil, huma.Error400BadRequest("Unsupported archive version")
+		case "captcha verification failed":
+			return nil, huma.Error400BadRequest("Captcha verification failed")
+		}
+		return nil, huma.Error500InternalServerError("Import failed")
+	}
+	return &struct{ Body any }{Body: result}, nil
+}
+
+func HandleGetReaderSettings(ctx context.Context, input *AuthenticatedProfileInput) (*struct{ Body any }, error) {
+	settings, err := data.GetReaderSettings(ctx, input.ProfileID, input.SecretToken)
+	if err != nil {
+		switch err.Error() {
+		case "invalid secret token":
+			return nil, huma.Error403Forbidden("Invalid secret token")
+		case "profile not found":
+			return nil, huma.Error404NotFound("Profile not found")
+		}
+		return nil, huma.Error500InternalServerError("Failed to fetch reader settings")
+	}
+	return &struct{ Body any }{Body: settings}, nil
+}
+
+func HandleUpdateReaderSettings(ctx context.Context, input *UpdateReaderSettingsInput) (*struct{ Body any }, error) {
+	settings, err := data.UpdateReaderSettings(ctx, input.ProfileID, input.SecretToken, input.Body)
+	if err != nil {
+		switch err.Error() {
+		case "invalid secret token":
+			return nil, huma.Error403Forbidden("Invalid secret token")
+		case "unsupported settings version":
+			return nil, huma.Error400BadRequest("Unsupported settings version")
+		}
+		if strings.HasPrefix(err.Error(), "invalid settings: ") {
+			return nil, huma.Error400BadRequest(fmt.Sprintf("Invalid reader settings: %s", strings.TrimPrefix(err.Error(), "invalid settings: ")))
+		}
+		return nil, huma.Error500InternalServerError("Failed to save reader settings")
+	}
+	return &struct{ Body any }{Body: settings}, nil
+}
+
+func readerSettingsCookie(value string, maxAge int) http.Cookie {
+	return http.Cookie{
+		Name:     data.ReaderSettingsCookieName,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+func HandleIssueReaderSettingsCookie(ctx context.Context, input *AuthenticatedProfileInput) (*ReaderSettingsCookieOutput, error) {
+	value, err := data.IssueReaderSettingsCookie(ctx, input.ProfileID, input.SecretToken)
+	if err != nil {
+		if err.Error() == "invalid secret token" {
+			return nil, huma.Error403Forbidden("Invalid secret token")
+		}
+		return nil, huma.Error500InternalServerError("Failed to issue reader settings cookie")
+	}
+	return &ReaderSettingsCookieOutput{SetCookie: readerSettingsCookie(value, 365*24*60*60)}, nil
+}
+
+func HandleClearReaderSettingsCookie(ctx context.Context, input *ProfileIDInput) (*ReaderSettingsCookieOutput, error) {
+	return &ReaderSettingsCookieOutput{SetCookie: readerSettingsCookie("", -1)}, nil
+}
+
+func HandleGetLibrary(ctx context.Context, input *GetLibraryInput) (*struct{ Body any }, error) {
+	library, err := data.GetLibrary(ctx, input.ProfileID, input.SecretToken, input.Shelf)
+	if err != nil {
+		switch err.Error() {
+		case "invalid secret token":
+			return nil, huma.Error403Forbidden("Invalid credentials")
+		case "invalid shelf":
+			return nil, huma.Error400BadRequest("Invalid shelf")
+		default:
+			return nil, huma.Error500InternalServerError("Failed to fetch library")
+		}
+	}
+	return &struct{ Body any }{Body: library}, nil
+}
+
+func HandleGetLibraryEntry(ctx context.Context, input *LibraryEntryInput) (*struct{ Body any }, error) {
+	entry, err := data.GetLibraryEntry(ctx, input.ProfileID, input.SecretToken, input.NovelID)
+	if err != nil {
+		switch err.Error() {
+		case "invalid secret token":
+			return nil, huma.Error403Forbidden("Invalid credentials")
+		case "entry not found":
+			return nil, huma.Error404NotFound("Novel is not in library")
+		default:
+			return nil, huma.Error500InternalServerError("Failed to fetch library entry")
+		}
+	}
+	return &struct{ Body any }{Body: entry}, nil
+}
+
+func HandleSetLibraryEntry(ctx context.Context, input *SetLibraryEntryInput) (*struct{ Body any }, error) {
+	entry, err := data.SetLibraryEntry(ctx, input.ProfileID, input.SecretToken, input.NovelID, input.Body.Shelf, input.Body.Rating)
+	if err != nil {
+		switch err.Error() {
+		case "invalid secret token":
+			return nil, huma.Error403Forbidden("Invalid credentials")
+		case "invalid shelf":
+			return nil, huma.Error400BadRequest("Invalid shelf")
+		case "invalid rating":
+			return nil, huma.Error400BadRequest("Rating must be between 1 and 10")
+		case "novel not found":
+			return nil, huma.Error404NotFound("Novel not found")
+		default:
+			return nil, huma.Error500InternalServerError("Failed to save library entry")
+		}
+	}
+	return &struct{ Body any }{Body: entry}, nil
+}
+
+func HandleDeleteLibraryEntry(ctx context.Context, input *LibraryEntryInput) (*struct{}, error) {
+	err := data.DeleteLibraryEntry(ctx, input.ProfileID, input.SecretToken, input.NovelID)
+	if err != nil {
+		switch err.Error() {
+		case "invalid secret token":
+			return nil, huma.Error403Forbidden("Invalid credentials")
+		case "entry not found":
+			return nil, huma.Error404NotFound("Novel is not in library")
+		default:
+			return nil, huma.Error500InternalServerError("Failed to delete library entry")
+		}
+	}
+	return &struct{}{}, nil
+}
+
+func HandleGetReadingProgressList(ctx context.Context, input *GetReadingProgressListInput) (*struct{ Body any }, error) {
+	progress, err := data.GetReadingProgressList(ctx, input.ProfileID, input.SecretToken, input.Limit)
+	if err != nil {
+		if err.Error() == "invalid secret token" {
+			return nil, huma.Error403Forbidden("Invalid credentials")
+		}
+		return nil, huma.Error500InternalServerError("Failed to fetch reading progress")
+	}
+	return &struct{ Body any }{Body: progress}, nil
+}
+
+func HandleGetReadingProgress(ctx context.Context, input *ReadingProgressInput) (*struct{ Body any }, error) {
+	progress, err := data.GetReadingProgress(ctx, input.ProfileID, input.SecretToken, input.NovelID)
+	if err != nil {
+		switch err.Error() {
+		case "invalid secret token":
+			return nil, huma.Error403Forbidden("Invalid credentials")
+		case "progress not found":
+			return nil, huma.Error404NotFound("No reading progress for this novel")
+		default:
+			return nil, huma.Error500InternalServerError("Failed to fetch reading progress")
+		}
+	}
+	return &struct{ Body any }{Body: progress}, nil
+}
+
+func HandleSaveReadingProgress(ctx context.Context, input *SaveReadingProgressInput) (*struct{ Body any }, error) {
+	progress, err := data.SaveReadingProgress(ctx, input.ProfileID, input.SecretToken, input.NovelID,
+		input.Body.ChapterID, input.Body.Paragraph, input.Body.ScrollPercent)
+	if err != nil {
+		switch err.Error() {
+		case "invalid secret token":
+			return nil, huma.Error403Forbidden("Invalid credentials")
+		case "invalid position":
+			return nil, huma.Error400BadRequest("Invalid reading position")
+		case "chapter not found":
+			return nil, huma.Error404NotFound("Chapter not found in this novel")
+		default:
+			return nil, huma.Error500InternalServerError("Failed to save reading progress")
+		}
+	}
+	return &struct{ Body any }{Body: progress}, nil
+}
+
+func HandleGetHistory(ctx context.Context, input *GetHistoryInput) (*struct{ Body any }, error) {
+	history, err := data.GetHistory(ctx, input.ProfileID, input.SecretToken, input.Page, 50)
+	if err != nil {
+		if err.Error() == "invalid secret token" {
+			return nil, huma.Error403Forbidden("Invalid credentials")
+		}
+		return nil, huma.Error500InternalServerError("Failed to fetch history")
+	}
+	return &struct{ Body any }{Body: history}, nil
+}
+
+func HandleRecordHistory(ctx context.Context, input *RecordHistoryInput) (*struct{}, error) {
+	err := data.RecordHistory(ctx, input.ProfileID, input.SecretToken, input.Body.ChapterID)
+	if err != nil {
+		switch err.Error() {
+		case "invalid secret token":
+			return nil, huma.Error403Forbidden("Invalid credentials")
+		case "chapter not found":
+			return nil, huma.Error404NotFound("Chapter not found")
+		default:
+			return nil, huma.Error500InternalServerError("Failed to record history")
+		}
+	}
+	return &struct{}{}, nil
+}
+
+func HandleClearHistory(ctx context.Context, input *ClearHistoryInput) (*struct{}, error) {
+	if err := data.ClearHistory(ctx, input.ProfileID, input.SecretToken); err != nil {
+		if err.Error() == "invalid secret token" {
+			return nil, huma.Error403Forbidden("Invalid credentials")
+		}
+		return nil, huma.Error500InternalServerError("Failed to clear history")
+	}
+	return &struct{}{}, nil
+}
+
+func followError(err error) error {
+	switch err.Error() {
+	case "invalid secret token":
+		return huma.Error403Forbidden("Invalid credentials")
+	case "novel not found":
+		return huma.Error404NotFound("Novel not found")
+	default:
+		return huma.Error500InternalServerError("Failed to update follow status")
+	}
+}
+
+func HandleGetFollowStatus(ctx context.Context, input *FollowInput) (*struct{ Body any }, error) {
+	status, err := data.GetFollowStatus(ctx, input.ProfileID, input.SecretToken, input.NovelID)
+	if err != nil {
+		return nil, followError(err)
+	}
+	return &struct{ Body any }{Body: status}, nil
+}
+
+func HandleFollowNovel(ctx context.Context, input *FollowInput) (*struct{ Body any }, error) {
+	status, err := data.FollowNovel(ctx, input.ProfileID, input.SecretToken, input.NovelID)
+	if err != nil {
+		return nil, followError(err)
+	}
+	return &struct{ Body any }{Body: status}, nil
+}
+
+func HandleUnfollowNovel(ctx context.Context, input *FollowInput) (*struct{ Body any }, error) {
+	status, err := data.UnfollowNovel(ctx, input.ProfileID, input.SecretToken, input.NovelID)
+	if err != nil {
+		return nil, followError(err)
+	}
+	return &struct{ Body any }{Body: status}, nil
+}
+
+func ratingError(err error) error {
+	switch err.Error() {
+	case "invalid secret token":
+		return huma.Error403Forbidden("Invalid credentials")
+	case "invalid rating":
+		return huma.Error400BadRequest("Rating must be between 1 and 10")
+	case "novel not found":
+		return huma.Error404NotFound("Novel not found")
+	case "rating not found":
+		return huma.Error404NotFound("Rating not found")
+	default:
+		return huma.Error500InternalServerError("Failed to update rating")
+	}
+}
+
+func HandleGetRatingSummary(ctx context.Context, input *IDInput) (*struct{ Body any }, error) {
+	summary, err := data.GetRatingSummary(ctx, input.ID)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("Failed to fetch rating")
+	}
+	return &struct{ Body any }{Body: summary}, nil
+}
+
+func HandleGetUserRating(ctx context.Context, input *RatingInput) (*struct{ Body any }, error) {
+	rating, err := data.GetUserRating(ctx, input.ProfileID, input.SecretToken, input.NovelID)
+	if err != nil {
+		return nil, ratingError(err)
+	}
+	return &struct{ Body any }{Body: rating}, nil
+}
+
+func HandleRateNovel(ctx context.Context, input *RateNovelInput) (*struct{ Body any }, error) {
+	rating, err := data.RateNovel(ctx, input.ProfileID, input.SecretToken, input.NovelID, input.Body.Score)
+	if err != nil {
+		return nil, ratingError(err)
+	}
+	return &struct{ Body any }{Body: rating}, nil
+}
+
+func HandleUnrateNovel(ctx context.Context, input *RatingInput) (*struct{}, error) {
+	if err := data.UnrateNovel(ctx, input.ProfileID, input.SecretToken, input.NovelID); err != nil {
+		return nil, ratingError(err)
+	}
+	return &struct{}{}, nil
+}
+
+func HandleGetReviews(ctx context.Context, input *GetReviewsInput) (*struct{ Body any }, error) {
+	reviews, err := data.GetApprovedReviews(ctx, input.NovelID, input.Page)
+	if err != nil {
+		return nil, huma.Error500InternalServerError("Failed to fetch reviews")
+	}
+	return &struct{ Body any }{Body: reviews}, nil
+}
+
+func HandleCreateReview(ctx context.Context, input *CreateReviewAPIInput) (*struct{ Body any }, error) {
+	reviewInput := models.CreateReviewInput{
+		NovelID:        input.NovelID,
+		Content:        input.Body.Content,
+		TurnstileToken: input.Body.TurnstileToken,
+	}
+
+	review, err := data.CreateReview(ctx, input.ProfileID, input.SecretToken, reviewInput)
+	if err != nil {
+		switch err.Error() {
+		case "rate limit exceeded":
+			return nil, huma.Error429TooManyRequests("Подождите 30 секунд перед отправкой отзыва")
+		case "captcha verification failed":
+			return nil, huma.Error400BadRequest("Captcha verification failed")
+		case "invalid secret token":
+			return nil, huma.Error403Forbidden("Invalid credentials")
+		case "invalid content length":
+			return nil, huma.Error400BadRequest("Review must be 50-5000 characters")
+		case "novel not found":
+			return nil, huma.Error404NotFound("Novel not found")
+		default:
+			return nil, huma.Error500InternalServerError("Failed to save review")
+		}
+	}
+	return &struct{ Body any }{Body: review}, nil
+}
+
+func HandleDeleteReview(ctx context.Context, input *RatingInput) (*struct{}, error) {
+	if err := data.DeleteReview(ctx, input.ProfileID, input.SecretToken, input.NovelID); err != nil {
+		switch err.Error() {
+		case "invalid secret token":
+			return nil, huma.Error403Forbidden("Invalid credentials")
+		case "review not found":
+			return nil, huma.Error404NotFound("Review not found")
+		default:
+			return nil, huma.Error500InternalServerError("Failed to delete review")
+		}
+	}
+	return &struct{}{}, nil
+}
+
+func HandleGetNotifications(ctx context.Context, input *GetNotificationsInput) (*struct{ Body any }, error) {
+	notifications, err := data.GetNotifications(ctx, input.ProfileID, input.SecretToken, input.Page, 30)
+	if err != nil {
+		if err.Error() == "invalid secret token" {
+			return nil, huma.Error403Forbidden("Invalid credentials")
+		}
+		return nil, huma.Error500InternalServerError("Failed to fetch notifications")
+	}
+	return &struct{ Body any }{Body: notifications}, nil
+}
+
+func HandleGetUnreadNotificationsCount(ctx context.Context, input *UnreadNotificationsInput) (*struct{ Body any }, error) {
+	count, err := data.GetUnreadNotificationsCount(ctx, input.ProfileID, input.SecretToken)
+	if err != nil {
+		if err.Error() == "invalid secret token" {
+			return nil, huma.Error403Forbidden("Invalid credentials")
+		}
+		return nil, huma.Error500InternalServerError("Failed to count notifications")
+	}
+	return &struct{ Body any }{Body: map[string]int{"unread_count": count}}, nil
+}
+
+func HandleMarkNotificationsRead(ctx context.Context, input *MarkNotificationsReadInput) (*struct{ Body any }, error) {
+	count, err := data.MarkNotificationsRead(ctx, input.ProfileID, input.SecretToken, input.Body.IDs)
+	if err != nil {
+		if err.Error() == "invalid secret token" {
+			return nil, huma.Error403Forbidden("Invalid credentials")
+		}
+		return nil, huma.Error500InternalServerError("Failed to mark notifications as read")
+	}
+	return &struct{ Body any }{Body: map[string]int{"unread_count": count}}, nil
+}
+
+func pushError(err error) error {
+	switch err.Error() {
+	case "invalid secret token":
+		return huma.Error403Forbidden("Invalid credentials")
+	case "push disabled":
+		return huma.Error503ServiceUnavailable("Push notifications are not configured")
+	case "invalid endpoint":
+		return huma.Error400BadRequest("Invalid push endpoint")
+	case "invalid subscription keys":
+		return huma.Error400BadRequest("Invalid subscription keys")
+	case "too many subscriptions":
+		return huma.Error409Conflict("Too many push subscriptions for this profile")
+	case "subscription not found":
+		return huma.Error404NotFound("Subscription not found")
+	default:
+		return huma.Error500InternalServerError("Failed to process push subscription")
+	}
+}
+
+func HandleGetPushPublicKey(ctx context.Context, input *struct{}) (*struct{ Body any }, error) {
+	key, err := data.GetPushPublicKey()
+	if err != nil {
+		return nil, pushError(err)
+	}
+	return &struct{ Body any }{Body: map[string]string{"public_key": key}}, nil
+}
+
+func HandleSavePushSubscription(ctx context.Context, input *SavePushSubscriptionInput) (*struct{}, error) {
+	err := data.SavePushSubscription(ctx, input.ProfileID, input.SecretToken, input.UserAgent, data.PushSubscriptionInput{
+		Endpoint: input.Body.Endpoint,
+		P256dh:   input.Body.Keys.P256dh,
+		Auth:     input.Body.Keys.Auth,
+	})
+	if err != nil {
+		return nil, pushError(err)
+	}
+	return &struct{}{}, nil
+}
+
+func HandleDeletePushSubscription(ctx context.Context, input *DeletePushSubscriptionInput) (*struct{}, error) {
+	if err := data.DeletePushSubscription(ctx, input.ProfileID, input.SecretToken, input.Body.Endpoint); err != nil {
+		return nil, pushError(err)
+	}
+	return &struct{}{}, nil
+}
+
+func HandleTestPush(ctx context.Context, input *TestPushInput) (*struct{ Body any }, error) {
+	delivered, err := data.SendTestPush(ctx, input.ProfileID, input.SecretToken)
+	if err != nil {
+		return nil, pushError(err)
+	}
+	return &struct{ Body any }{Body: map[string]int{"delivered": delivered}}, nil
+}
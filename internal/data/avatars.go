@@ -0,0 +1,380 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"time"
+
+	"github.com/HugoSmits86/nativewebp"
+	"github.com/ch1kulya/kappalib/internal/database"
+	"github.com/ch1kulya/kappalib/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/minio/minio-go/v7"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+
+	"github.com/ch1kulya/logger"
+)
+
+//go:embed sql/users_avatar_submit.sql
+var queryUsersAvatarSubmit string
+
+//go:embed sql/users_avatar_moderate.sql
+var queryUsersAvatarModerate string
+
+var (
+	ErrUnsupportedFormat = fmt.Errorf("unsupported image format")
+	ErrImageTooLarge     = fmt.Errorf("image dimensions too large")
+)
+
+const (
+	AvatarMaxUploadSize = 5 << 20
+	avatarMaxPixels     = 4096 * 4096
+)
+
+var avatarSizes = []int{256, 128, 64}
+
+type avatarObject struct {
+	size        int
+	ext         string
+	contentType string
+	data        []byte
+}
+
+func UpdateAvatar(ctx context.Context, profileID, secretToken string, imageData []byte) (*models.ProfilePublic, error) {
+	if minioClient == nil {
+		return nil, fmt.Errorf("s3 not configured")
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return nil, fmt.Errorf("invalid secret token")
+	}
+
+	if err := submitAvatar(ctx, profileID, imageData); err != nil {
+		return nil, err
+	}
+
+	return GetProfile(ctx, profileID)
+}
+
+func RemoveAvatar(ctx context.Context, profileID, secretToken string) (*models.ProfilePublic, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return nil, fmt.Errorf("invalid secret token")
+	}
+
+	var messageID *int64
+	err := database.DB.QueryRow(dbCtx, `
+		UPDATE users u SET has_custom_avatar = false, avatar_status = NULL, avatar_submission_id = NULL,
+			avatar_telegram_message_id = NULL, last_active_at = now()
+		FROM (SELECT id, avatar_telegram_message_id FROM users WHERE id = $1 FOR UPDATE) prev
+		WHERE u.id = prev.id
+		RETURNING prev.avatar_telegram_message_id`, profileID).Scan(&messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if messageID != nil {
+		go deleteModerationMessage(*messageID)
+	}
+
+	if minioClient != nil {
+		if err := deleteAvatarObjects(ctx, profileID); err != nil {
+			logger.Warn("Failed to remove avatar objects of %s: %v", profileID, err)
+		}
+	}
+
+	logger.Debug("Removed custom avatar of %s", profileID)
+
+	return GetProfile(ctx, profileID)
+}
+
+func UpdateAvatarStatus(ctx context.Context, profileID, submissionID, status string) error {
+	if submissionID == "" {
+		return fmt.Errorf("avatar moderation outdated")
+	}
+	if status == "approved" && minioClient == nil {
+		return fmt.Errorf("s3 not configured")
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	tx, err := database.DB.Begin(dbCtx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(dbCtx)
+
+	var id string
+	err = tx.QueryRow(dbCtx,
+		`SELECT id FROM users WHERE id = $1 AND avatar_status = 'pending' AND avatar_submission_id = $2 FOR UPDATE`,
+		profileID, submissionID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("avatar moderation outdated")
+		}
+		return err
+	}
+
+	if status == "approved" {
+		if err := publishPendingAvatar(dbCtx, profileID, submissionID); err != nil {
+			logger.Error("Failed to publish avatar of %s: %v", profileID, err)
+			return err
+		}
+	}
+
+	if err := tx.QueryRow(dbCtx, queryUsersAvatarModerate, profileID, status, submissionID).Scan(&id); err != nil {
+		logger.Error("Failed to update avatar status: %v", err)
+		return err
+	}
+
+	if err := tx.Commit(dbCtx); err != nil {
+		return err
+	}
+
+	if minioClient != nil {
+		if err := removeAvatarPrefix(dbCtx, pendingAvatarPrefix(profileID, submissionID)); err != nil {
+			logger.Warn("Failed to remove pending avatar of %s: %v", profileID, err)
+		}
+	}
+
+	logger.Info("Avatar of %s status updated to %s", profileID, status)
+	return nil
+}
+
+func submitAvatar(ctx context.Context, profileID string, imageData []byte) error {
+	select {
+	case imageProcessingSem <- struct{}{}:
+		defer func() { <-imageProcessingSem }()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	objects, err := processAvatar(imageData)
+	if err != nil {
+		if errors.Is(err, ErrUnsupportedFormat) {
+			return fmt.Errorf("unsupported format")
+		}
+		if errors.Is(err, ErrImageTooLarge) {
+			return fmt.Errorf("image too large")
+		}
+		return fmt.Errorf("image processing failed: %w", err)
+	}
+
+	submissionID := generateAvatarSubmissionID()
+	for _, obj := range objects {
+		key := pendingAvatarKey(profileID, submissionID, obj.size, obj.ext)
+		_, err = minioClient.PutObject(ctx, s3Bucket, key, bytes.NewReader(obj.data), int64(len(obj.data)), minio.PutObjectOptions{
+			ContentType:  obj.contentType,
+			CacheControl: "public, max-age=3600",
+		})
+		if err != nil {
+			return fmt.Errorf("s3 upload failed: %w", err)
+		}
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var displayName string
+	var previousMessageID *int64
+	var previousSubmissionID *string
+	err = database.DB.QueryRow(dbCtx, queryUsersAvatarSubmit, profileID, submissionID).Scan(
+		&displayName, &previousMessageID, &previousSubmissionID)
+	if err != nil {
+		logger.Error("Failed to mark avatar as pending: %v", err)
+		removeAvatarPrefix(ctx, pendingAvatarPrefix(profileID, submissionID))
+		return err
+	}
+
+	if previousMessageID != nil {
+		go deleteModerationMessage(*previousMessageID)
+	}
+	if previousSubmissionID != nil {
+		go removeAvatarPrefix(context.Background(), pendingAvatarPrefix(profileID, *previousSubmissionID))
+	}
+	go sendAvatarToTelegram(context.Background(), profileID, submissionID, displayName, objects[0].data)
+
+	logger.Debug("Avatar of %s submitted for moderation", profileID)
+	return nil
+}
+
+func sendAvatarToTelegram(ctx context.Context, profileID, submissionID, displayName string, photo []byte) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if telegramBotToken == "" || telegramChatID == "" {
+		logger.Warn("Telegram credentials not set, skipping notification")
+		return
+	}
+
+	caption := fmt.Sprintf(
+		"🖼 <b>Новый аватар</b>\n\n"+
+			"👤 Профиль: %s (<code>%s</code>)",
+		displayName,
+		profileID,
+	)
+
+	messageID, err := sendModerationPhoto(ctx, photo, caption, []map[string]string{
+		{"text": "✅ Подтвердить", "callback_data": fmt.Sprintf("avatar_approve:%s:%s", profileID, submissionID)},
+		{"text": "❌ Отклонить", "callback_data": fmt.Sprintf("avatar_reject:%s:%s", profileID, submissionID)},
+	})
+	if err != nil {
+		logger.Error("Failed to send avatar to telegram: %v", err)
+		return
+	}
+
+	dbCtx, dbCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer dbCancel()
+	result, err := database.DB.Exec(dbCtx,
+		`UPDATE users SET avatar_telegram_message_id = $1 WHERE id = $2 AND avatar_status = 'pending' AND avatar_submission_id = $3`,
+		messageID, profileID, submissionID)
+	if err != nil || result.RowsAffected() == 0 {
+		deleteModerationMessage(messageID)
+	}
+}
+
+func publishPendingAvatar(ctx context.Context, profileID, submissionID string) error {
+	for _, size := range avatarSizes {
+		for _, ext := range []string{"jpg", "webp"} {
+			_, err := minioClient.CopyObject(ctx,
+				minio.CopyDestOptions{Bucket: s3Bucket, Object: avatarKey(profileID, size, ext)},
+				minio.CopySrcOptions{Bucket: s3Bucket, Object: pendingAvatarKey(profileID, submissionID, size, ext)},
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func avatarKey(profileID string, size int, ext string) string {
+	if size == avatarSizes[0] {
+		return fmt.Sprintf("avatars/%s.%s", profileID, ext)
+	}
+	return fmt.Sprintf("avatars/%s_%d.%s", profileID, size, ext)
+}
+
+func generateAvatarSubmissionID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func pendingAvatarPrefix(profileID, submissionID string) string {
+	return fmt.Sprintf("avatars-pending/%s/%s/", profileID, submissionID)
+}
+
+func pendingAvatarKey(profileID, submissionID string, size int, ext string) string {
+	return fmt.Sprintf("%s%d.%s", pendingAvatarPrefix(profileID, submissionID), size, ext)
+}
+
+func deleteAvatarObjects(ctx context.Context, profileID string) error {
+	var firstErr error
+	for _, size := range avatarSizes {
+		for _, ext := range []string{"jpg", "webp"} {
+			err := minioClient.RemoveObject(ctx, s3Bucket, avatarKey(profileID, size, ext), minio.RemoveObjectOptions{})
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	if err := removeAvatarPrefix(ctx, fmt.Sprintf("avatars-pending/%s/", profileID)); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	return firstErr
+}
+
+func removeAvatarPrefix(ctx context.Context, prefix string) error {
+	if minioClient == nil {
+		return nil
+	}
+	var firstErr error
+	for obj := range minioClient.ListObjects(ctx, s3Bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if obj.Err != nil {
+			return obj.Err
+		}
+		err := minioClient.RemoveObject(ctx, s3Bucket, obj.Key, minio.RemoveObjectOptions{})
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func processAvatar(data []byte) ([]avatarObject, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+
+	switch format {
+	case "jpeg", "png", "gif", "webp":
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+
+	if cfg.Width*cfg.Height > avatarMaxPixels {
+		return nil, ErrImageTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+
+	var cropRect image.Rectangle
+	if srcW > srcH {
+		offset := (srcW - srcH) / 2
+		cropRect = image.Rect(bounds.Min.X+offset, bounds.Min.Y, bounds.Min.X+offset+srcH, bounds.Min.Y+srcH)
+	} else {
+		offset := (srcH - srcW) / 2
+		cropRect = image.Rect(bounds.Min.X, bounds.Min.Y+offset, bounds.Min.X+srcW, bounds.Min.Y+offset+srcW)
+	}
+
+	cropped := image.NewRGBA(image.Rect(0, 0, cropRect.Dx(), cropRect.Dy()))
+	draw.Draw(cropped, cropped.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(cropped, cropped.Bounds(), img, cropRect.Min, draw.Over)
+
+	objects := make([]avatarObject, 0, len(avatarSizes)*2)
+	for _, size := range avatarSizes {
+		resized := image.NewRGBA(image.Rect(0, 0, size, size))
+		draw.CatmullRom.Scale(resized, resized.Bounds(), cropped, cropped.Bounds(), draw.Over, nil)
+
+		var jpegBuf bytes.Buffer
+		if err := jpeg.Encode(&jpegBuf, resized, &jpeg.Options{Quality: 85}); err != nil {
+			return nil, err
+		}
+
+		var webpBuf bytes.Buffer
+		if err := nativewebp.Encode(&webpBuf, resized, nil); err != nil {
+			return nil, err
+		}
+
+		objects = append(objects,
+			avatarObject{size, "jpg", "image/jpeg", jpegBuf.Bytes()},
+			avatarObject{size, "webp", "image/webp", webpBuf.Bytes()},
+		)
+	}
+
+	return objects, nil
+}
@@ -0,0 +1,123 @@
+package data
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ch1kulya/kappalib/internal/database"
+	"github.com/ch1kulya/kappalib/internal/models"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/ch1kulya/logger"
+)
+
+//go:embed sql/blocks_get_list.sql
+var queryBlocksGetList string
+
+//go:embed sql/blocks_upsert.sql
+var queryBlocksUpsert string
+
+const maxBlockedUsers = 500
+
+func isBlockedBy(ctx context.Context, blockerID, profileID string) bool {
+	var blocked bool
+	err := database.DB.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM user_blocks WHERE user_id = $1 AND blocked_user_id = $2 AND kind = 'block')`,
+		blockerID, profileID).Scan(&blocked)
+	return err == nil && blocked
+}
+
+func GetBlockedUsers(ctx context.Context, profileID, secretToken string) ([]models.BlockedUser, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return nil, fmt.Errorf("invalid secret token")
+	}
+
+	rows, err := database.DB.Query(dbCtx, queryBlocksGetList, profileID)
+	if err != nil {
+		logger.Error("Failed to get blocked users: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	blocked := make([]models.BlockedUser, 0)
+	for rows.Next() {
+		var b models.BlockedUser
+		if err := rows.Scan(&b.UserID, &b.DisplayName, &b.AvatarSeed, &b.HasCustomAvatar, &b.Kind, &b.CreatedAt); err != nil {
+			logger.Warn("Blocked user row scan error: %v", err)
+			continue
+		}
+		blocked = append(blocked, b)
+	}
+
+	return blocked, rows.Err()
+}
+
+func BlockUser(ctx context.Context, profileID, secretToken, targetID, kind string) error {
+	if kind != "mute" && kind != "block" {
+		return fmt.Errorf("invalid kind")
+	}
+	if targetID == profileID {
+		return fmt.Errorf("cannot block yourself")
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return fmt.Errorf("invalid secret token")
+	}
+
+	var count int
+	err := database.DB.QueryRow(dbCtx,
+		`SELECT COUNT(*) FROM user_blocks WHERE user_id = $1 AND blocked_user_id <> $2`,
+		profileID, targetID).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count >= maxBlockedUsers {
+		return fmt.Errorf("too many blocked users")
+	}
+
+	var id string
+	err = database.DB.QueryRow(dbCtx, queryBlocksUpsert, profileID, targetID, kind).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("user not found")
+		}
+		logger.Error("Failed to block user: %v", err)
+		return err
+	}
+
+	logger.Debug("Profile %s set %s on %s", profileID, kind, targetID)
+	return nil
+}
+
+func UnblockUser(ctx context.Context, profileID, secretToken, targetID string) error {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return fmt.Errorf("invalid secret token")
+	}
+
+	result, err := database.DB.Exec(dbCtx,
+		`DELETE FROM user_blocks WHERE user_id = $1 AND blocked_user_id = $2`,
+		profileID, targetID)
+	if err != nil {
+		logger.Error("Failed to unblock user: %v", err)
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("not blocked")
+	}
+
+	logger.Debug("Profile %s unblocked %s", profileID, targetID)
+	return nil
+}
@@ -67,7 +67,7 @@ func GetChapter(ctx context.Context, id string) (*models.Chapter, error) {
 
 		err := database.DB.QueryRow(dbCtx, queryChaptersGetOne, id).Scan(
 			&c.ID, &c.NovelID, &c.ChapterNum,
-			&c.Title, &c.TitleEn, &c.Content, &c.CreatedAt,
+			&c.Title, &c.TitleEn, &c.Content, &c.ViewsCount, &c.CreatedAt,
 			&sourceName, &sourceLogo,
 		)
 		if err != nil {
@@ -1,19 +1,25 @@
 package data
 
 import (
+	"bytes"
 	"context"
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"mime/multipart"
 	"net/http"
 	"net/url"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/ch1kulya/kappalib/internal/database"
 	"github.com/ch1kulya/kappalib/internal/models"
+	"github.com/jackc/pgx/v5"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
 
@@ -28,6 +34,9 @@ var queryCommentsCreate string
 //go:embed sql/comments_get_approved.sql
 var queryCommentsGetApproved string
 
+//go:embed sql/comments_get_replies.sql
+var queryCommentsGetReplies string
+
 //go:embed sql/comments_count_approved.sql
 var queryCommentsCountApproved string
 
@@ -40,6 +49,12 @@ var queryCommentsUpdateStatus string
 //go:embed sql/comments_set_telegram_message_id.sql
 var queryCommentsSetTelegramMessageID string
 
+//go:embed sql/comments_update_content.sql
+var queryCommentsUpdateContent string
+
+//go:embed sql/comments_delete.sql
+var queryCommentsDelete string
+
 var (
 	commentsTurnstileSecret = os.Getenv("TURNSTILE_COMMENTS_SECRET")
 	telegramBotToken        = os.Getenv("TELEGRAM_BOT_TOKEN")
@@ -63,7 +78,12 @@ var userCommentLimiter = struct {
 	lastComment: make(map[string]time.Time),
 }
 
-const commentCooldown = 30 * time.Second
+const (
+	commentCooldown  = 30 * time.Second
+	maxCommentDepth  = 3
+	repliesPerThread = 20
+	maxThreadReplies = 500
+)
 
 func checkCommentRateLimit(userID string) bool {
 	userCommentLimiter.Lock()
@@ -189,12 +209,33 @@ func CreateComment(ctx context.Context, profileID, secretToken string, input mod
 		return nil, fmt.Errorf("invalid secret token")
 	}
 
+	var parentID, replyToUserID *string
+	depth := 0
+	if input.ParentID != "" {
+		parent, err := getCommentByID(dbCtx, input.ParentID)
+		if err != nil || parent.ChapterID != input.ChapterID || parent.Status != "approved" {
+			return nil, fmt.Errorf("parent not found")
+		}
+		if isBlockedBy(dbCtx, parent.UserID, profileID) {
+			return nil, fmt.Errorf("blocked by author")
+		}
+		replyToUserID = &parent.UserID
+		if parent.Depth >= maxCommentDepth-1 {
+			parentID = parent.ParentID
+			depth = parent.Depth
+		} else {
+			parentID = &parent.ID
+			depth = parent.Depth + 1
+		}
+	}
+
 	contentHTML := renderMarkdown(input.Content)
 
 	var comment models.Comment
 	err := database.DB.QueryRow(dbCtx, queryCommentsCreate,
-		input.ChapterID, profileID, contentHTML,
-	).Scan(&comment.ID, &comment.ChapterID, &comment.UserID, &comment.ContentHTML, &comment.Status, &comment.CreatedAt)
+		input.ChapterID, profileID, contentHTML, parentID, depth, replyToUserID,
+	).Scan(&comment.ID, &comment.ChapterID, &comment.UserID, &comment.ParentID, &comment.Depth,
+		&comment.ContentHTML, &comment.Status, &comment.CreatedAt)
 
 	if err != nil {
 		logger.Error("Failed to create comment: %v", err)
@@ -215,15 +256,98 @@ func CreateComment(ctx context.Context, profileID, secretToken string, input mod
 	return &comment, nil
 }
 
-func GetApprovedComments(ctx context.Context, chapterID string, page int) (*models.CommentsPage, error) {
+var CommentSorts = []string{"newest", "oldest", "top"}
+
+func EditComment(ctx context.Context, profileID, secretToken, commentID, content string) (*models.Comment, error) {
+	if len(content) == 0 || len(content) > 1000 {
+		return nil, fmt.Errorf("invalid content length")
+	}
+
+	if !checkCommentRateLimit(profileID) {
+		return nil, fmt.Errorf("rate limit exceeded")
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return nil, fmt.Errorf("invalid secret token")
+	}
+
+	contentHTML := renderMarkdown(content)
+
+	var comment models.Comment
+	var previousMessageID *int64
+	err := database.DB.QueryRow(dbCtx, queryCommentsUpdateContent, commentID, profileID, contentHTML).Scan(
+		&comment.ID, &comment.ChapterID, &comment.UserID, &comment.ParentID, &comment.Depth,
+		&comment.ContentHTML, &comment.Status, &comment.CreatedAt, &comment.EditedAt, &previousMessageID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("comment not found")
+		}
+		logger.Error("Failed to edit comment: %v", err)
+		return nil, err
+	}
+
+	database.DB.QueryRow(dbCtx, `SELECT display_name, avatar_seed, has_custom_avatar FROM users WHERE id = $1`, profileID).Scan(
+		&comment.UserDisplayName, &comment.UserAvatarSeed, &comment.UserHasCustomAvatar)
+
+	if previousMessageID != nil {
+		go deleteModerationMessage(*previousMessageID)
+	}
+	go sendCommentToTelegram(context.Background(), &comment)
+
+	recordCommentTime(profileID)
+
+	logger.Info("Comment edited: %s by user %s", comment.ID, profileID)
+	return &comment, nil
+}
+
+func DeleteComment(ctx context.Context, profileID, secretToken, commentID string) error {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return fmt.Errorf("invalid secret token")
+	}
+
+	var messageID *int64
+	var hasReplies bool
+	err := database.DB.QueryRow(dbCtx, queryCommentsDelete, commentID, profileID).Scan(&messageID, &hasReplies)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("comment not found")
+		}
+		logger.Error("Failed to delete comment: %v", err)
+		return err
+	}
+
+	if messageID != nil {
+		go deleteModerationMessage(*messageID)
+	}
+
+	logger.Info("Comment deleted: %s by user %s (kept as placeholder: %t)", commentID, profileID, hasReplies)
+	return nil
+}
+
+func GetApprovedComments(ctx context.Context, chapterID string, page int, sort, profileID, secretToken string) (*models.CommentsPage, error) {
+	if !slices.Contains(CommentSorts, sort) {
+		sort = "newest"
+	}
+
 	pageSize := 12
 	offset := (page - 1) * pageSize
 
 	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	var totalCount int
-	if err := database.DB.QueryRow(dbCtx, queryCommentsCountApproved, chapterID).Scan(&totalCount); err != nil {
+	viewerID := ""
+	if profileID != "" && verifySecretToken(dbCtx, profileID, secretToken) {
+		viewerID = profileID
+	}
+
+	var totalCount, totalWithReplies int
+	if err := database.DB.QueryRow(dbCtx, queryCommentsCountApproved, chapterID, viewerID).Scan(&totalCount, &totalWithReplies); err != nil {
 		logger.Error("Failed to count comments: %v", err)
 		return nil, err
 	}
@@ -238,31 +362,127 @@ func GetApprovedComments(ctx context.Context, chapterID string, page int) (*mode
 		}, nil
 	}
 
-	rows, err := database.DB.Query(dbCtx, queryCommentsGetApproved, chapterID, pageSize, offset)
+	rows, err := database.DB.Query(dbCtx, queryCommentsGetApproved, chapterID, pageSize, offset, sort, viewerID)
 	if err != nil {
 		logger.Error("Failed to get comments: %v", err)
 		return nil, err
 	}
+
+	comments, err := scanComments(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(comments) > 0 {
+		if err := attachReplies(dbCtx, comments, viewerID, repliesPerThread); err != nil {
+			logger.Error("Failed to get comment replies: %v", err)
+			return nil, err
+		}
+	}
+
+	totalPages := (totalCount + pageSize - 1) / pageSize
+	return &models.CommentsPage{
+		Comments:         comments,
+		Page:             page,
+		PageSize:         pageSize,
+		TotalCount:       totalCount,
+		TotalPages:       totalPages,
+		TotalWithReplies: totalWithReplies,
+	}, nil
+}
+
+func scanComment(rows pgx.Rows, c *models.Comment, extra ...any) error {
+	dest := []any{&c.ID, &c.ChapterID, &c.UserID, &c.ParentID, &c.Depth, &c.RepliesCount,
+		&c.LikesCount, &c.DislikesCount, &c.ContentHTML, &c.Status, &c.CreatedAt, &c.EditedAt, &c.IsDeleted,
+		&c.UserDisplayName, &c.UserAvatarSeed, &c.UserHasCustomAvatar}
+	return rows.Scan(append(dest, extra...)...)
+}
+
+func scanComments(rows pgx.Rows) ([]models.Comment, error) {
 	defer rows.Close()
 
 	comments := make([]models.Comment, 0)
 	for rows.Next() {
 		var c models.Comment
-		if err := rows.Scan(&c.ID, &c.ChapterID, &c.UserID, &c.ContentHTML, &c.Status, &c.CreatedAt, &c.UserDisplayName, &c.UserAvatarSeed, &c.UserHasCustomAvatar); err != nil {
+		if err := scanComment(rows, &c); err != nil {
 			logger.Warn("Comment row scan error: %v", err)
 			continue
 		}
 		comments = append(comments, c)
 	}
+	return comments, rows.Err()
+}
 
-	totalPages := (totalCount + pageSize - 1) / pageSize
-	return &models.CommentsPage{
-		Comments:   comments,
-		Page:       page,
-		PageSize:   pageSize,
-		TotalCount: totalCount,
-		TotalPages: totalPages,
-	}, nil
+func attachReplies(ctx context.Context, roots []models.Comment, viewerID string, limit int) error {
+	rootIDs := make([]string, len(roots))
+	for i, c := range roots {
+		rootIDs[i] = c.ID
+	}
+
+	rows, err := database.DB.Query(ctx, queryCommentsGetReplies, rootIDs, limit, viewerID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	children := make(map[string][]models.Comment)
+	threadSizes := make(map[string]int)
+	for rows.Next() {
+		var r models.Comment
+		var rootID string
+		var threadSize int
+		if err := scanComment(rows, &r, &rootID, &threadSize); err != nil {
+			logger.Warn("Comment row scan error: %v", err)
+			continue
+		}
+		threadSizes[rootID] = threadSize
+		if r.ParentID != nil {
+			children[*r.ParentID] = append(children[*r.ParentID], r)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	var attach func(c *models.Comment)
+	attach = func(c *models.Comment) {
+		c.Replies = children[c.ID]
+		for i := range c.Replies {
+			attach(&c.Replies[i])
+		}
+	}
+	for i := range roots {
+		attach(&roots[i])
+		roots[i].HasMoreReplies = threadSizes[roots[i].ID] > limit
+	}
+	return nil
+}
+
+func GetCommentReplies(ctx context.Context, commentID, profileID, secretToken string) (*models.CommentReplies, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	comment, err := getCommentByID(dbCtx, commentID)
+	if err != nil || comment.Status != "approved" {
+		return nil, fmt.Errorf("comment not found")
+	}
+
+	viewerID := ""
+	if profileID != "" && verifySecretToken(dbCtx, profileID, secretToken) {
+		viewerID = profileID
+	}
+
+	roots := []models.Comment{{ID: comment.ID}}
+	if err := attachReplies(dbCtx, roots, viewerID, maxThreadReplies); err != nil {
+		logger.Error("Failed to get comment replies: %v", err)
+		return nil, err
+	}
+
+	replies := roots[0].Replies
+	if replies == nil {
+		replies = []models.Comment{}
+	}
+	return &models.CommentReplies{Replies: replies, HasMore: roots[0].HasMoreReplies}, nil
 }
 
 func UpdateCommentStatus(ctx context.Context, commentID, status string) error {
@@ -284,9 +504,14 @@ func GetCommentByID(ctx context.Context, commentID string) (*models.Comment, err
 	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
+	return getCommentByID(dbCtx, commentID)
+}
+
+func getCommentByID(ctx context.Context, commentID string) (*models.Comment, error) {
 	var c models.Comment
-	err := database.DB.QueryRow(dbCtx, queryCommentsGetByID, commentID).Scan(
-		&c.ID, &c.ChapterID, &c.UserID, &c.ContentHTML, &c.Status, &c.TelegramMessageID, &c.CreatedAt,
+	err := database.DB.QueryRow(ctx, queryCommentsGetByID, commentID).Scan(
+		&c.ID, &c.ChapterID, &c.UserID, &c.ParentID, &c.Depth, &c.ContentHTML, &c.Status, &c.TelegramMessageID,
+		&c.CreatedAt, &c.EditedAt, &c.IsDeleted,
 	)
 	if err != nil {
 		return nil, err
@@ -311,11 +536,19 @@ func sendCommentToTelegram(ctx context.Context, comment *models.Comment) {
 
 	contentForTelegram := htmlToTelegramHTML(comment.ContentHTML)
 
+	heading := "💬 <b>Новый комментарий</b>"
+	if comment.EditedAt != nil {
+		heading = fmt.Sprintf("✏️ <b>Изменённый комментарий</b> <code>%s</code>", comment.ID)
+	} else if comment.ParentID != nil {
+		heading = fmt.Sprintf("↩️ <b>Ответ на комментарий</b> <code>%s</code>", *comment.ParentID)
+	}
+
 	text := fmt.Sprintf(
-		"💬 <b>Новый комментарий</b>\n\n"+
+		"%s\n\n"+
 			"👤 Автор: %s\n"+
 			"📖 Глава: <code>%s</code>\n\n"+
 			"📝 Текст:\n%s",
+		heading,
 		comment.UserDisplayName,
 		comment.ChapterID,
 		contentForTelegram,
@@ -325,13 +558,30 @@ func sendCommentToTelegram(ctx context.Context, comment *models.Comment) {
 		text = text[:4000] + "..."
 	}
 
+	messageID, err := sendModerationMessage(ctx, text,
+		fmt.Sprintf("approve:%s", commentID),
+		fmt.Sprintf("reject:%s", commentID),
+	)
+	if err != nil {
+		logger.Error("Failed to send comment to telegram: %v", err)
+		return
+	}
+
+	dbCtx, dbCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer dbCancel()
+	database.DB.Exec(dbCtx, queryCommentsSetTelegramMessageID, messageID, comment.ID)
+}
+
+func sendModerationMessage(ctx context.Context, text, approveData, rejectData string) (int64, error) {
+	return sendModerationButtons(ctx, text, []map[string]string{
+		{"text": "✅ Подтвердить", "callback_data": approveData},
+		{"text": "❌ Отклонить", "callback_data": rejectData},
+	})
+}
+
+func sendModerationButtons(ctx context.Context, text string, buttons []map[string]string) (int64, error) {
 	keyboard := map[string]any{
-		"inline_keyboard": [][]map[string]string{
-			{
-				{"text": "✅ Подтвердить", "callback_data": fmt.Sprintf("approve:%s", commentID)},
-				{"text": "❌ Отклонить", "callback_data": fmt.Sprintf("reject:%s", commentID)},
-			},
-		},
+		"inline_keyboard": [][]map[string]string{buttons},
 	}
 
 	keyboardJSON, _ := json.Marshal(keyboard)
@@ -347,15 +597,58 @@ func sendCommentToTelegram(ctx context.Context, comment *models.Comment) {
 
 	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, strings.NewReader(data.Encode()))
 	if err != nil {
-		logger.Error("Failed to create telegram request: %v", err)
-		return
+		return 0, err
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
+	return sendTelegramRequest(req, "sendMessage")
+}
+
+func sendModerationPhoto(ctx context.Context, photo []byte, caption string, buttons []map[string]string) (int64, error) {
+	keyboard := map[string]any{
+		"inline_keyboard": [][]map[string]string{buttons},
+	}
+
+	keyboardJSON, _ := json.Marshal(keyboard)
+
+	apiURL := fmt.Sprintf("https://api.telegram.org/bot%s/sendPhoto", telegramBotToken)
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, field := range [][2]string{
+		{"chat_id", telegramChatID},
+		{"caption", caption},
+		{"parse_mode", "HTML"},
+		{"reply_markup", string(keyboardJSON)},
+	} {
+		if err := writer.WriteField(field[0], field[1]); err != nil {
+			return 0, err
+		}
+	}
+	part, err := writer.CreateFormFile("photo", "avatar.jpg")
+	if err != nil {
+		return 0, err
+	}
+	if _, err := part.Write(photo); err != nil {
+		return 0, err
+	}
+	if err := writer.Close(); err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, &body)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	return sendTelegramRequest(req, "sendPhoto")
+}
+
+func sendTelegramRequest(req *http.Request, method string) (int64, error) {
 	resp, err := telegramClient.Do(req)
 	if err != nil {
-		logger.Error("Failed to send telegram message: %v", err)
-		return
+		return 0, err
 	}
 	defer resp.Body.Close()
 
@@ -367,15 +660,14 @@ func sendCommentToTelegram(ctx context.Context, comment *models.Comment) {
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		logger.Error("Failed to decode telegram response: %v", err)
-		return
+		return 0, err
 	}
 
-	if result.OK {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		database.DB.Exec(ctx, queryCommentsSetTelegramMessageID, result.Result.MessageID, comment.ID)
+	if !result.OK {
+		return 0, fmt.Errorf("telegram %s failed", method)
 	}
+
+	return result.Result.MessageID, nil
 }
 
 func htmlToTelegramHTML(html string) string {
@@ -499,3 +791,13 @@ func DeleteTelegramMessage(chatID int64, messageID int64) error {
 
 	return nil
 }
+
+func deleteModerationMessage(messageID int64) {
+	chatID, err := strconv.ParseInt(telegramChatID, 10, 64)
+	if err != nil {
+		return
+	}
+	if err := DeleteTelegramMessage(chatID, messageID); err != nil {
+		logger.Warn("Failed to delete outdated moderation message %d: %v", messageID, err)
+	}
+}
@@ -0,0 +1,283 @@
+package data
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"time"
+
+	"github.com/ch1kulya/kappalib/internal/database"
+	"github.com/ch1kulya/kappalib/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/minio/minio-go/v7"
+
+	"github.com/ch1kulya/logger"
+)
+
+//go:embed sql/profile_export.sql
+var queryProfileExport string
+
+//go:embed sql/profile_import.sql
+var queryProfileImport string
+
+const (
+	exportVersion        = 1
+	exportDataFile       = "profile.json"
+	exportAvatarFile     = "avatar.jpg"
+	exportMaxDataSize    = 8 << 20
+	exportMaxAvatarSize  = 1 << 20
+	importMaxListEntries = 5000
+)
+
+var avatarSeedRegex = regexp.MustCompile(`^[a-f0-9]{16}$`)
+
+func ExportProfileData(ctx context.Context, profileID, secretToken string) (*models.ProfileExport, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return nil, fmt.Errorf("invalid secret token")
+	}
+
+	export := models.ProfileExport{
+		Version:    exportVersion,
+		ExportedAt: time.Now().UTC(),
+		ProfileID:  profileID,
+	}
+
+	var cookiesJSON, libraryJSON, progressJSON, historyJSON, followsJSON, ratingsJSON, reviewsJSON, commentsJSON []byte
+	err := database.DB.QueryRow(dbCtx, queryProfileExport, profileID).Scan(
+		&export.Profile.DisplayName, &export.Profile.AvatarSeed, &export.Profile.HasCustomAvatar,
+		&export.Profile.IsPublic, &export.Profile.CreatedAt, &cookiesJSON,
+		&libraryJSON, &progressJSON, &historyJSON, &followsJSON, &ratingsJSON, &reviewsJSON, &commentsJSON)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("profile not found")
+		}
+		logger.Error("Failed to export profile %s: %v", profileID, err)
+		return nil, err
+	}
+
+	for _, part := range []struct {
+		raw  []byte
+		dest any
+	}{
+		{cookiesJSON, &export.Cookies},
+		{libraryJSON, &export.Library},
+		{progressJSON, &export.Progress},
+		{historyJSON, &export.History},
+		{followsJSON, &export.Follows},
+		{ratingsJSON, &export.Ratings},
+		{reviewsJSON, &export.Reviews},
+		{commentsJSON, &export.Comments},
+	} {
+		if err := json.Unmarshal(part.raw, part.dest); err != nil {
+			logger.Error("Failed to decode export data for %s: %v", profileID, err)
+			return nil, err
+		}
+	}
+
+	logger.Info("Profile data exported: %s", profileID)
+	return &export, nil
+}
+
+func ExportProfileArchive(ctx context.Context, profileID, secretToken string) ([]byte, error) {
+	export, err := ExportProfileData(ctx, profileID, secretToken)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	w, err := zw.Create(exportDataFile)
+	if err != nil {
+		return nil, err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(export); err != nil {
+		return nil, err
+	}
+
+	if export.Profile.HasCustomAvatar && minioClient != nil {
+		if avatar, err := fetchAvatar(ctx, profileID); err != nil {
+			logger.Warn("Failed to fetch avatar for export %s: %v", profileID, err)
+		} else if w, err := zw.Create(exportAvatarFile); err == nil {
+			w.Write(avatar)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func fetchAvatar(ctx context.Context, profileID string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	obj, err := minioClient.GetObject(ctx, s3Bucket, avatarKey(profileID, avatarSizes[0], "jpg"), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+
+	return io.ReadAll(io.LimitReader(obj, exportMaxAvatarSize))
+}
+
+func readArchiveFile(f *zip.File, limit int64) ([]byte, error) {
+	if f.UncompressedSize64 > uint64(limit) {
+		return nil, fmt.Errorf("invalid archive")
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive")
+	}
+	defer rc.Close()
+	return io.ReadAll(io.LimitReader(rc, limit))
+}
+
+func parseProfileArchive(archive []byte) (*models.ProfileExport, []byte, error) {
+	var dataJSON, avatar []byte
+
+	if bytes.HasPrefix(archive, []byte("PK")) {
+		zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid archive")
+		}
+		for _, f := range zr.File {
+			switch f.Name {
+			case exportDataFile:
+				if dataJSON, err = readArchiveFile(f, exportMaxDataSize); err != nil {
+					return nil, nil, err
+				}
+			case exportAvatarFile:
+				if avatar, err = readArchiveFile(f, exportMaxAvatarSize); err != nil {
+					return nil, nil, err
+				}
+			}
+		}
+	} else {
+		dataJSON = archive
+	}
+
+	if dataJSON == nil {
+		return nil, nil, fmt.Errorf("invalid archive")
+	}
+
+	var export models.ProfileExport
+	if err := json.Unmarshal(dataJSON, &export); err != nil {
+		return nil, nil, fmt.Errorf("invalid archive")
+	}
+	if export.Version < 1 || export.Version > exportVersion {
+		return nil, nil, fmt.Errorf("unsupported archive version")
+	}
+
+	return &export, avatar, nil
+}
+
+func ImportProfile(ctx context.Context, turnstileToken, userAgent string, archive []byte) (*models.ProfileImportResult, error) {
+	export, avatar, err := parseProfileArchive(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	if !verifyTurnstile(turnstileToken) {
+		return nil, fmt.Errorf("captcha verification failed")
+	}
+
+	displayName, err := ValidateDisplayName(export.Profile.DisplayName)
+	if err != nil {
+		displayName = generateRandomName()
+	}
+	avatarSeed := export.Profile.AvatarSeed
+	if !avatarSeedRegex.MatchString(avatarSeed) {
+		avatarSeed = generateAvatarSeed()
+	}
+	cookies := validateCookies(export.Cookies)
+	cookiesJSON, _ := json.Marshal(cookies)
+
+	lists := make([][]byte, 0, 5)
+	for _, list := range []any{
+		capList(export.Library),
+		capList(export.Progress),
+		export.History,
+		capList(export.Follows),
+		capList(export.Ratings),
+	} {
+		raw, err := json.Marshal(list)
+		if err != nil {
+			return nil, fmt.Errorf("invalid archive")
+		}
+		lists = append(lists, raw)
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	tx, err := database.DB.Begin(dbCtx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(dbCtx)
+
+	secretToken := generateSecretToken()
+	result := models.ProfileImportResult{
+		Profile: models.ProfileWithToken{SecretToken: secretToken},
+		Cookies: cookies,
+	}
+	err = tx.QueryRow(dbCtx, queryUsersCreate,
+		displayName, avatarSeed, hashToken(secretToken), deviceLabel(userAgent)).Scan(
+		&result.Profile.ID, &result.Profile.DisplayName, &result.Profile.AvatarSeed, &result.Profile.CreatedAt)
+	if err != nil {
+		logger.Error("Failed to create imported profile: %v", err)
+		return nil, err
+	}
+
+	if _, err := tx.Exec(dbCtx,
+		`UPDATE users SET cookies = $1, is_public = $2 WHERE id = $3`,
+		cookiesJSON, export.Profile.IsPublic, result.Profile.ID); err != nil {
+		logger.Error("Failed to restore profile settings: %v", err)
+		return nil, err
+	}
+
+	err = tx.QueryRow(dbCtx, queryProfileImport,
+		result.Profile.ID, lists[0], lists[1], lists[2], lists[3], lists[4],
+		historyMaxEntries, historyRetentionDays,
+	).Scan(&result.Library, &result.Progress, &result.History, &result.Follows, &result.Ratings)
+	if err != nil {
+		logger.Error("Failed to import reading data: %v", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(dbCtx); err != nil {
+		return nil, err
+	}
+
+	if len(avatar) > 0 && minioClient != nil {
+		if err := submitAvatar(ctx, result.Profile.ID, avatar); err != nil {
+			logger.Warn("Failed to restore avatar for %s: %v", result.Profile.ID, err)
+		} else {
+			result.Avatar = true
+		}
+	}
+
+	logger.Info("Profile imported: %s (%s) from %s", result.Profile.DisplayName, result.Profile.ID, export.ProfileID)
+	return &result, nil
+}
+
+func capList[T any](list []T) []T {
+	if len(list) > importMaxListEntries {
+		return list[:importMaxListEntries]
+	}
+	return list
+}
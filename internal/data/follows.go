@@ -0,0 +1,75 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ch1kulya/kappalib/internal/database"
+	"github.com/ch1kulya/kappalib/internal/models"
+
+	"github.com/ch1kulya/logger"
+)
+
+func GetFollowStatus(ctx context.Context, profileID, secretToken, novelID string) (*models.FollowStatus, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return nil, fmt.Errorf("invalid secret token")
+	}
+
+	var following bool
+	err := database.DB.QueryRow(dbCtx,
+		`SELECT EXISTS(SELECT 1 FROM novel_follows WHERE user_id = $1 AND novel_id = $2)`,
+		profileID, novelID,
+	).Scan(&following)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.FollowStatus{NovelID: novelID, Following: following}, nil
+}
+
+func FollowNovel(ctx context.Context, profileID, secretToken, novelID string) (*models.FollowStatus, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return nil, fmt.Errorf("invalid secret token")
+	}
+
+	if !novelExists(dbCtx, novelID) {
+		return nil, fmt.Errorf("novel not found")
+	}
+
+	_, err := database.DB.Exec(dbCtx,
+		`INSERT INTO novel_follows (user_id, novel_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+		profileID, novelID)
+	if err != nil {
+		logger.Error("Failed to follow novel: %v", err)
+		return nil, err
+	}
+
+	logger.Debug("Profile %s followed novel %s", profileID, novelID)
+	return &models.FollowStatus{NovelID: novelID, Following: true}, nil
+}
+
+func UnfollowNovel(ctx context.Context, profileID, secretToken, novelID string) (*models.FollowStatus, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return nil, fmt.Errorf("invalid secret token")
+	}
+
+	_, err := database.DB.Exec(dbCtx,
+		`DELETE FROM novel_follows WHERE user_id = $1 AND novel_id = $2`,
+		profileID, novelID)
+	if err != nil {
+		logger.Error("Failed to unfollow novel: %v", err)
+		return nil, err
+	}
+
+	return &models.FollowStatus{NovelID: novelID, Following: false}, nil
+}
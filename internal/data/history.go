@@ -0,0 +1,150 @@
+package data
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ch1kulya/kappalib/internal/database"
+	"github.com/ch1kulya/kappalib/internal/models"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/ch1kulya/logger"
+)
+
+//go:embed sql/history_record.sql
+var queryHistoryRecord string
+
+//go:embed sql/history_trim.sql
+var queryHistoryTrim string
+
+//go:embed sql/history_get_page.sql
+var queryHistoryGetPage string
+
+//go:embed sql/history_cleanup.sql
+var queryHistoryCleanup string
+
+const (
+	historyMaxEntries      = 500
+	historyRetentionDays   = 180
+	historyDedupMinutes    = 30
+	historyCleanupInterval = 24 * time.Hour
+)
+
+func RecordHistory(ctx context.Context, profileID, secretToken, chapterID string) error {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return fmt.Errorf("invalid secret token")
+	}
+
+	var id int64
+	err := database.DB.QueryRow(dbCtx, queryHistoryRecord, profileID, chapterID, historyDedupMinutes).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("chapter not found")
+		}
+		logger.Error("Failed to record history: %v", err)
+		return err
+	}
+
+	if _, err := database.DB.Exec(dbCtx, queryHistoryTrim, profileID, historyMaxEntries); err != nil {
+		logger.Warn("Failed to trim history for %s: %v", profileID, err)
+	}
+
+	return nil
+}
+
+func GetHistory(ctx context.Context, profileID, secretToken string, page, pageSize int) (*models.HistoryPage, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return nil, fmt.Errorf("invalid secret token")
+	}
+
+	offset := (page - 1) * pageSize
+	rows, err := database.DB.Query(dbCtx, queryHistoryGetPage, profileID, pageSize+1, offset)
+	if err != nil {
+		logger.Error("Failed to get history: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]models.HistoryEntry, 0, pageSize)
+	for rows.Next() {
+		var e models.HistoryEntry
+		if err := rows.Scan(&e.ID, &e.NovelID, &e.NovelTitle, &e.NovelCoverURL,
+			&e.ChapterID, &e.ChapterNum, &e.ChapterTitle, &e.ReadAt); err != nil {
+			logger.Warn("History row scan error: %v", err)
+			continue
+		}
+		entries = append(entries, e)
+	}
+
+	hasMore := len(entries) > pageSize
+	if hasMore {
+		entries = entries[:pageSize]
+	}
+
+	return &models.HistoryPage{
+		Entries:  entries,
+		Page:     page,
+		PageSize: pageSize,
+		HasMore:  hasMore,
+	}, nil
+}
+
+func ClearHistory(ctx context.Context, profileID, secretToken string) error {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return fmt.Errorf("invalid secret token")
+	}
+
+	_, err := database.DB.Exec(dbCtx, `DELETE FROM reading_history WHERE user_id = $1`, profileID)
+	if err != nil {
+		logger.Error("Failed to clear history: %v", err)
+		return err
+	}
+
+	logger.Info("History cleared for profile %s", profileID)
+	return nil
+}
+
+func cleanupHistory(ctx context.Context) error {
+	dbCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	result, err := database.DB.Exec(dbCtx, queryHistoryCleanup, historyRetentionDays)
+	if err != nil {
+		return err
+	}
+
+	logger.Debug("Removed %d expired history entries", result.RowsAffected())
+	return nil
+}
+
+func StartHistoryCleaner(ctx context.Context) {
+	if err := cleanupHistory(ctx); err != nil {
+		logger.Warn("Failed to clean up history: %v", err)
+	}
+
+	ticker := time.NewTicker(historyCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := cleanupHistory(ctx); err != nil {
+				logger.Warn("Failed to clean up history: %v", err)
+			}
+		}
+	}
+}
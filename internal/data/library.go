@@ -0,0 +1,181 @@
+package data
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ch1kulya/kappalib/internal/cache"
+	"github.com/ch1kulya/kappalib/internal/database"
+	"github.com/ch1kulya/kappalib/internal/models"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/ch1kulya/logger"
+)
+
+//go:embed sql/library_get_list.sql
+var queryLibraryGetList string
+
+//go:embed sql/library_count_by_shelf.sql
+var queryLibraryCountByShelf string
+
+//go:embed sql/library_upsert.sql
+var queryLibraryUpsert string
+
+//go:embed sql/library_get_one.sql
+var queryLibraryGetOne string
+
+var LibraryShelves = []string{"reading", "planned", "completed", "dropped"}
+
+func isValidShelf(shelf string) bool {
+	return slices.Contains(LibraryShelves, shelf)
+}
+
+func GetLibrary(ctx context.Context, profileID, secretToken, shelf string) (*models.Library, error) {
+	if shelf != "" && !isValidShelf(shelf) {
+		return nil, fmt.Errorf("invalid shelf")
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return nil, fmt.Errorf("invalid secret token")
+	}
+
+	counts := make(map[string]int, len(LibraryShelves))
+	for _, s := range LibraryShelves {
+		counts[s] = 0
+	}
+
+	countRows, err := database.DB.Query(dbCtx, queryLibraryCountByShelf, profileID)
+	if err != nil {
+		logger.Error("Failed to count library entries: %v", err)
+		return nil, err
+	}
+	for countRows.Next() {
+		var s string
+		var cnt int
+		if err := countRows.Scan(&s, &cnt); err != nil {
+			continue
+		}
+		counts[s] = cnt
+	}
+	countRows.Close()
+
+	rows, err := database.DB.Query(dbCtx, queryLibraryGetList, profileID, shelf)
+	if err != nil {
+		logger.Error("Failed to get library: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]models.LibraryEntry, 0)
+	for rows.Next() {
+		var e models.LibraryEntry
+		var n models.LibraryNovel
+		if err := rows.Scan(&e.NovelID, &e.Shelf, &e.Rating, &e.CreatedAt, &e.UpdatedAt,
+			&n.Title, &n.TitleEn, &n.Author, &n.CoverURL, &n.ChaptersCount); err != nil {
+			logger.Warn("Library row scan error: %v", err)
+			continue
+		}
+		e.Novel = &n
+		entries = append(entries, e)
+	}
+
+	return &models.Library{
+		Entries: entries,
+		Counts:  counts,
+	}, nil
+}
+
+func GetLibraryEntry(ctx context.Context, profileID, secretToken, novelID string) (*models.LibraryEntry, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return nil, fmt.Errorf("invalid secret token")
+	}
+
+	var e models.LibraryEntry
+	err := database.DB.QueryRow(dbCtx, queryLibraryGetOne, profileID, novelID).Scan(
+		&e.NovelID, &e.Shelf, &e.Rating, &e.CreatedAt, &e.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("entry not found")
+		}
+		return nil, err
+	}
+	return &e, nil
+}
+
+func SetLibraryEntry(ctx context.Context, profileID, secretToken, novelID, shelf string, rating *int) (*models.LibraryEntry, error) {
+	if !isValidShelf(shelf) {
+		return nil, fmt.Errorf("invalid shelf")
+	}
+	if rating != nil && (*rating < minRatingScore || *rating > maxRatingScore) {
+		return nil, fmt.Errorf("invalid rating")
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return nil, fmt.Errorf("invalid secret token")
+	}
+
+	if !novelExists(dbCtx, novelID) {
+		return nil, fmt.Errorf("novel not found")
+	}
+
+	var e models.LibraryEntry
+	err := database.DB.QueryRow(dbCtx, queryLibraryUpsert, profileID, novelID, shelf, rating).Scan(
+		&e.NovelID, &e.Shelf, &e.Rating, &e.CreatedAt, &e.UpdatedAt)
+	if err != nil {
+		logger.Error("Failed to save library entry: %v", err)
+		return nil, err
+	}
+
+	if rating != nil {
+		cache.C.Delete(ratingSummaryKey(novelID))
+	}
+	database.DB.Exec(dbCtx, `UPDATE users SET last_active_at = now() WHERE id = $1`, profileID)
+
+	logger.Debug("Library entry for %s: %s -> %s", profileID, novelID, shelf)
+	return &e, nil
+}
+
+func DeleteLibraryEntry(ctx context.Context, profileID, secretToken, novelID string) error {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return fmt.Errorf("invalid secret token")
+	}
+
+	result, err := database.DB.Exec(dbCtx,
+		`DELETE FROM library_entries WHERE user_id = $1 AND novel_id = $2`,
+		profileID, novelID)
+	if err != nil {
+		logger.Error("Failed to delete library entry: %v", err)
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("entry not found")
+	}
+
+	return nil
+}
+
+func novelExists(ctx context.Context, novelID string) bool {
+	var exists bool
+	err := database.DB.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM novels WHERE id = $1)`,
+		novelID,
+	).Scan(&exists)
+	return err == nil && exists
+}
@@ -0,0 +1,381 @@
+package data
+
+import (
+	"context"
+	"crypto/subtle"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ch1kulya/kappalib/internal/database"
+	"github.com/ch1kulya/kappalib/internal/models"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/ch1kulya/logger"
+)
+
+//go:embed sql/login_claim_sync_code.sql
+var queryLoginClaimSyncCode string
+
+//go:embed sql/login_attempts_cleanup.sql
+var queryLoginAttemptsCleanup string
+
+//go:embed sql/login_requests_get_pending.sql
+var queryLoginRequestsGetPending string
+
+const (
+	loginIPThreshold          = 5
+	loginGlobalThreshold      = 100
+	loginGlobalWindow         = time.Minute
+	loginLockoutBase          = 30 * time.Second
+	loginLockoutMax           = time.Hour
+	loginFailureTTL           = 24 * time.Hour
+	loginRequestTTL           = 10 * time.Minute
+	loginAttemptsRetention    = 30
+	loginAttemptsCleanupEvery = 6 * time.Hour
+)
+
+type loginFailures struct {
+	count       int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+var loginGuard = struct {
+	sync.Mutex
+	ips               map[string]*loginFailures
+	globalCount       int
+	globalWindowStart time.Time
+	globalStrikes     int
+	globalLockedUntil time.Time
+}{
+	ips: make(map[string]*loginFailures),
+}
+
+func lockoutDuration(strikes int) time.Duration {
+	d := loginLockoutBase
+	for i := 0; i < strikes && d < loginLockoutMax; i++ {
+		d *= 2
+	}
+	return min(d, loginLockoutMax)
+}
+
+func LoginRetryAfter(ip string) time.Duration {
+	loginGuard.Lock()
+	defer loginGuard.Unlock()
+
+	until := loginGuard.globalLockedUntil
+	if f, ok := loginGuard.ips[ip]; ok && f.lockedUntil.After(until) {
+		until = f.lockedUntil
+	}
+	return max(time.Until(until), 0)
+}
+
+func recordLoginFailure(ip string) {
+	loginGuard.Lock()
+	defer loginGuard.Unlock()
+
+	now := time.Now()
+
+	f, ok := loginGuard.ips[ip]
+	if !ok || now.Sub(f.lastFailure) > loginFailureTTL {
+		f = &loginFailures{}
+		loginGuard.ips[ip] = f
+	}
+	f.count++
+	f.lastFailure = now
+	if f.count >= loginIPThreshold {
+		f.lockedUntil = now.Add(lockoutDuration(f.count - loginIPThreshold))
+		logger.Warn("Sync code login locked for IP %s until %s", ip, f.lockedUntil.Format(time.RFC3339))
+	}
+
+	if now.Sub(loginGuard.globalWindowStart) > loginGlobalWindow {
+		if loginGuard.globalCount < loginGlobalThreshold {
+			loginGuard.globalStrikes = 0
+		}
+		loginGuard.globalWindowStart = now
+		loginGuard.globalCount = 0
+	}
+	loginGuard.globalCount++
+	if loginGuard.globalCount >= loginGlobalThreshold {
+		loginGuard.globalLockedUntil = now.Add(lockoutDuration(loginGuard.globalStrikes))
+		loginGuard.globalStrikes++
+		loginGuard.globalCount = 0
+		logger.Warn("Sync code login locked globally until %s", loginGuard.globalLockedUntil.Format(time.RFC3339))
+	}
+}
+
+func init() {
+	go func() {
+		ticker := time.NewTicker(10 * time.Minute)
+		defer ticker.Stop()
+		for range ticker.C {
+			loginGuard.Lock()
+			now := time.Now()
+			for ip, f := range loginGuard.ips {
+				if now.Sub(f.lastFailure) > loginFailureTTL && now.After(f.lockedUntil) {
+					delete(loginGuard.ips, ip)
+				}
+			}
+			loginGuard.Unlock()
+		}
+	}()
+}
+
+func logLoginAttempt(ip, userID, outcome string) {
+	dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var uid *string
+	if userID != "" {
+		uid = &userID
+	}
+	if _, err := database.DB.Exec(dbCtx,
+		`INSERT INTO login_attempts (ip, user_id, outcome) VALUES ($1, $2, $3)`,
+		ip, uid, outcome); err != nil {
+		logger.Warn("Failed to log login attempt: %v", err)
+	}
+}
+
+func LoginWithSyncCode(ctx context.Context, syncCode, userAgent, ip string) (*models.LoginResponse, error) {
+	if LoginRetryAfter(ip) > 0 {
+		go logLoginAttempt(ip, "", "locked")
+		return nil, fmt.Errorf("too many attempts")
+	}
+
+	syncCode = strings.ToUpper(strings.TrimSpace(syncCode))
+	if len(syncCode) != 8 {
+		return nil, fmt.Errorf("invalid sync code format")
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := database.DB.Begin(dbCtx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(dbCtx)
+
+	var profile models.ProfilePublic
+	var cookiesJSON []byte
+	var requireConfirmation bool
+	err = tx.QueryRow(dbCtx, queryLoginClaimSyncCode, syncCode).Scan(
+		&profile.ID, &profile.DisplayName, &profile.AvatarSeed, &profile.HasCustomAvatar,
+		&profile.IsPublic, &profile.CreatedAt, &cookiesJSON, &requireConfirmation)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			recordLoginFailure(ip)
+			go logLoginAttempt(ip, "", "invalid_code")
+			return nil, fmt.Errorf("invalid or expired sync code")
+		}
+		return nil, err
+	}
+
+	label := deviceLabel(userAgent)
+
+	if requireConfirmation {
+		pollToken := generateSecretToken()
+		var requestID string
+		err = tx.QueryRow(dbCtx,
+			`INSERT INTO login_requests (user_id, poll_token_hash, device_label, ip, expires_at)
+			VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+			profile.ID, hashToken(pollToken), label, ip, time.Now().Add(loginRequestTTL),
+		).Scan(&requestID)
+		if err != nil {
+			logger.Error("Failed to create login request for %s: %v", profile.ID, err)
+			return nil, err
+		}
+		if err := tx.Commit(dbCtx); err != nil {
+			return nil, err
+		}
+
+		go logLoginAttempt(ip, profile.ID, "pending")
+		logger.Info("Login via sync code awaiting confirmation: %s (%s)", profile.ID, requestID)
+		return &models.LoginResponse{
+			Status:    "pending",
+			RequestID: requestID,
+			PollToken: pollToken,
+		}, nil
+	}
+
+	secretToken, err := createSession(dbCtx, tx, profile.ID, label)
+	if err != nil {
+		logger.Error("Failed to create session for %s: %v", profile.ID, err)
+		return nil, err
+	}
+	if err := tx.Commit(dbCtx); err != nil {
+		return nil, err
+	}
+
+	var cookies map[string]models.CookieValue
+	json.Unmarshal(cookiesJSON, &cookies)
+
+	go logLoginAttempt(ip, profile.ID, "success")
+	logger.Info("Login via sync code: %s", profile.ID)
+	return &models.LoginResponse{
+		Status:      "ok",
+		Profile:     &profile,
+		SecretToken: secretToken,
+		Cookies:     cookies,
+	}, nil
+}
+
+func CompleteLoginRequest(ctx context.Context, requestID, pollToken string) (*models.LoginResponse, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := database.DB.Begin(dbCtx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(dbCtx)
+
+	var userID, storedHash, status, label string
+	err = tx.QueryRow(dbCtx,
+		`SELECT user_id, poll_token_hash, status, device_label FROM login_requests
+		WHERE id = $1 AND expires_at > now() FOR UPDATE`,
+		requestID,
+	).Scan(&userID, &storedHash, &status, &label)
+	if err != nil || subtle.ConstantTimeCompare([]byte(storedHash), []byte(hashToken(pollToken))) != 1 {
+		return nil, fmt.Errorf("login request not found")
+	}
+
+	switch status {
+	case "pending":
+		return &models.LoginResponse{Status: "pending", RequestID: requestID}, nil
+	case "denied":
+		return nil, fmt.Errorf("login denied")
+	case "completed":
+		return nil, fmt.Errorf("login request not found")
+	}
+
+	var profile models.ProfilePublic
+	var cookiesJSON []byte
+	err = tx.QueryRow(dbCtx,
+		`SELECT id, display_name, avatar_seed, has_custom_avatar, is_public, created_at, cookies FROM users WHERE id = $1`,
+		userID,
+	).Scan(&profile.ID, &profile.DisplayName, &profile.AvatarSeed, &profile.HasCustomAvatar,
+		&profile.IsPublic, &profile.CreatedAt, &cookiesJSON)
+	if err != nil {
+		return nil, fmt.Errorf("login request not found")
+	}
+
+	secretToken, err := createSession(dbCtx, tx, userID, label)
+	if err != nil {
+		logger.Error("Failed to create session for %s: %v", userID, err)
+		return nil, err
+	}
+	if _, err := tx.Exec(dbCtx, `UPDATE login_requests SET status = 'completed' WHERE id = $1`, requestID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(dbCtx); err != nil {
+		return nil, err
+	}
+
+	var cookies map[string]models.CookieValue
+	json.Unmarshal(cookiesJSON, &cookies)
+
+	logger.Info("Confirmed login completed: %s (%s)", userID, requestID)
+	return &models.LoginResponse{
+		Status:      "ok",
+		Profile:     &profile,
+		SecretToken: secretToken,
+		Cookies:     cookies,
+	}, nil
+}
+
+func GetLoginRequests(ctx context.Context, profileID, secretToken string) ([]models.LoginRequest, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return nil, fmt.Errorf("invalid secret token")
+	}
+
+	rows, err := database.DB.Query(dbCtx, queryLoginRequestsGetPending, profileID)
+	if err != nil {
+		logger.Error("Failed to get login requests: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := make([]models.LoginRequest, 0)
+	for rows.Next() {
+		var r models.LoginRequest
+		if err := rows.Scan(&r.ID, &r.DeviceLabel, &r.IP, &r.CreatedAt, &r.ExpiresAt); err != nil {
+			logger.Warn("Login request row scan error: %v", err)
+			continue
+		}
+		requests = append(requests, r)
+	}
+
+	return requests, nil
+}
+
+func ResolveLoginRequest(ctx context.Context, profileID, secretToken, requestID string, approve bool) error {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return fmt.Errorf("invalid secret token")
+	}
+
+	status := "denied"
+	if approve {
+		status = "approved"
+	}
+
+	result, err := database.DB.Exec(dbCtx,
+		`UPDATE login_requests SET status = $1
+		WHERE id = $2 AND user_id = $3 AND status = 'pending' AND expires_at > now()`,
+		status, requestID, profileID)
+	if err != nil {
+		logger.Error("Failed to resolve login request: %v", err)
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("login request not found")
+	}
+
+	logger.Info("Login request %s for %s: %s", requestID, profileID, status)
+	return nil
+}
+
+func cleanupLoginAttempts(ctx context.Context) error {
+	dbCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	result, err := database.DB.Exec(dbCtx, queryLoginAttemptsCleanup, loginAttemptsRetention)
+	if err != nil {
+		return err
+	}
+
+	logger.Debug("Removed %d expired login requests", result.RowsAffected())
+	return nil
+}
+
+func StartLoginAttemptsCleaner(ctx context.Context) {
+	if err := cleanupLoginAttempts(ctx); err != nil {
+		logger.Warn("Failed to clean up login attempts: %v", err)
+	}
+
+	ticker := time.NewTicker(loginAttemptsCleanupEvery)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := cleanupLoginAttempts(ctx); err != nil {
+				logger.Warn("Failed to clean up login attempts: %v", err)
+			}
+		}
+	}
+}
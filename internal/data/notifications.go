@@ -0,0 +1,150 @@
+package data
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"time"
+
+	"github.com/ch1kulya/kappalib/internal/database"
+	"github.com/ch1kulya/kappalib/internal/models"
+
+	"github.com/ch1kulya/logger"
+)
+
+//go:embed sql/notifications_get_page.sql
+var queryNotificationsGetPage string
+
+//go:embed sql/notifications_mark_read.sql
+var queryNotificationsMarkRead string
+
+//go:embed sql/notifications_cleanup.sql
+var queryNotificationsCleanup string
+
+const (
+	notificationsReadRetentionDays = 30
+	notificationsRetentionDays     = 90
+	notificationsCleanupInterval   = 24 * time.Hour
+)
+
+func countUnreadNotifications(ctx context.Context, profileID string) (int, error) {
+	var count int
+	err := database.DB.QueryRow(ctx,
+		`SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND NOT is_read`,
+		profileID,
+	).Scan(&count)
+	return count, err
+}
+
+func GetUnreadNotificationsCount(ctx context.Context, profileID, secretToken string) (int, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return 0, fmt.Errorf("invalid secret token")
+	}
+
+	return countUnreadNotifications(dbCtx, profileID)
+}
+
+func GetNotifications(ctx context.Context, profileID, secretToken string, page, pageSize int) (*models.NotificationsPage, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return nil, fmt.Errorf("invalid secret token")
+	}
+
+	unread, err := countUnreadNotifications(dbCtx, profileID)
+	if err != nil {
+		logger.Error("Failed to count unread notifications: %v", err)
+		return nil, err
+	}
+
+	offset := (page - 1) * pageSize
+	rows, err := database.DB.Query(dbCtx, queryNotificationsGetPage, profileID, pageSize+1, offset)
+	if err != nil {
+		logger.Error("Failed to get notifications: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := make([]models.Notification, 0, pageSize)
+	for rows.Next() {
+		var n models.Notification
+		if err := rows.Scan(&n.ID, &n.Type, &n.NovelID, &n.NovelTitle, &n.NovelCoverURL,
+			&n.ChapterID, &n.FirstChapterNum, &n.LastChapterNum, &n.ChaptersCount,
+			&n.CommentID, &n.ActorName, &n.IsRead, &n.CreatedAt, &n.UpdatedAt); err != nil {
+			logger.Warn("Notification row scan error: %v", err)
+			continue
+		}
+		notifications = append(notifications, n)
+	}
+
+	hasMore := len(notifications) > pageSize
+	if hasMore {
+		notifications = notifications[:pageSize]
+	}
+
+	return &models.NotificationsPage{
+		Notifications: notifications,
+		Page:          page,
+		PageSize:      pageSize,
+		HasMore:       hasMore,
+		UnreadCount:   unread,
+	}, nil
+}
+
+func MarkNotificationsRead(ctx context.Context, profileID, secretToken string, ids []int64) (int, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return 0, fmt.Errorf("invalid secret token")
+	}
+
+	if ids == nil {
+		ids = []int64{}
+	}
+
+	if _, err := database.DB.Exec(dbCtx, queryNotificationsMarkRead, profileID, ids); err != nil {
+		logger.Error("Failed to mark notifications as read: %v", err)
+		return 0, err
+	}
+
+	return countUnreadNotifications(dbCtx, profileID)
+}
+
+func cleanupNotifications(ctx context.Context) error {
+	dbCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	result, err := database.DB.Exec(dbCtx, queryNotificationsCleanup,
+		notificationsReadRetentionDays, notificationsRetentionDays)
+	if err != nil {
+		return err
+	}
+
+	logger.Debug("Removed %d old notifications", result.RowsAffected())
+	return nil
+}
+
+func StartNotificationsCleaner(ctx context.Context) {
+	if err := cleanupNotifications(ctx); err != nil {
+		logger.Warn("Failed to clean up notifications: %v", err)
+	}
+
+	ticker := time.NewTicker(notificationsCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := cleanupNotifications(ctx); err != nil {
+				logger.Warn("Failed to clean up notifications: %v", err)
+			}
+		}
+	}
+}
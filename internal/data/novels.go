@@ -37,7 +37,7 @@ func GetNovel(ctx context.Context, id string) (*models.Novel, error) {
 		err := database.DB.QueryRow(dbCtx, queryNovelsGetOne, id).Scan(
 			&n.ID, &n.Title, &n.TitleEn, &n.Author,
 			&n.YearStart, &n.YearEnd, &n.Status, &n.Description,
-			&n.AgeRating, &n.CoverURL, &n.CreatedAt,
+			&n.AgeRating, &n.CoverURL, &n.ViewsCount, &n.CreatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -76,10 +76,18 @@ func GetNovels(ctx context.Context, page int, sort string) (*models.NovelsPage,
 			}, nil
 		}
 
-		baseQuery := `SELECT id, title, title_en, author, year_start, year_end, status, description, age_rating, cover_url, created_at FROM novels`
+		baseQuery := `SELECT id, title, title_en, author, year_start, year_end, status, description, age_rating, cover_url, views_count, created_at FROM novels`
 
 		var orderByClause string
 		switch sort {
+		case "trending":
+			baseQuery += " LEFT JOIN novel_rankings r ON r.novel_id = novels.id"
+			orderByClause = "ORDER BY r.trending_rank ASC NULLS LAST, novels.created_at DESC"
+		case "popular":
+			baseQuery += " LEFT JOIN novel_rankings r ON r.novel_id = novels.id"
+			orderByClause = "ORDER BY r.popular_rank ASC NULLS LAST, novels.created_at DESC"
+		case "rating":
+			orderByClause = "ORDER BY (rating_sum + 35.0) / (rating_count + 5) DESC, rating_count DESC, title ASC"
 		case "newest":
 			orderByClause = "ORDER BY year_start DESC, title ASC"
 		case "large":
@@ -110,7 +118,7 @@ func GetNovels(ctx context.Context, page int, sort string) (*models.NovelsPage,
 			var n models.Novel
 			if err := rows.Scan(&n.ID, &n.Title, &n.TitleEn, &n.Author,
 				&n.YearStart, &n.YearEnd, &n.Status, &n.Description,
-				&n.AgeRating, &n.CoverURL, &n.CreatedAt); err != nil {
+				&n.AgeRating, &n.CoverURL, &n.ViewsCount, &n.CreatedAt); err != nil {
 				logger.Warn("GetNovels: Row scan error: %v", err)
 				continue
 			}
@@ -155,7 +163,7 @@ func SearchNovels(ctx context.Context, query string) ([]models.Novel, error) {
 		var relevance float64
 		if err := rows.Scan(&n.ID, &n.Title, &n.TitleEn, &n.Author,
 			&n.YearStart, &n.YearEnd, &n.Status, &n.Description,
-			&n.AgeRating, &n.CoverURL, &n.CreatedAt, &relevance); err != nil {
+			&n.AgeRating, &n.CoverURL, &n.ViewsCount, &n.CreatedAt, &relevance); err != nil {
 			continue
 		}
 		novels = append(novels, n)
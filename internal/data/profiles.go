@@ -0,0 +1,76 @@
+package data
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"time"
+
+	"github.com/ch1kulya/kappalib/internal/database"
+	"github.com/ch1kulya/kappalib/internal/models"
+
+	"github.com/ch1kulya/logger"
+)
+
+//go:embed sql/users_get_comments.sql
+var queryUsersGetComments string
+
+//go:embed sql/users_comment_stats.sql
+var queryUsersCommentStats string
+
+func GetUserPage(ctx context.Context, userID string, page int) (*models.UserPage, error) {
+	pageSize := 20
+	offset := (page - 1) * pageSize
+
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result := &models.UserPage{
+		Comments: []models.UserComment{},
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	profile := &result.Profile
+	err := database.DB.QueryRow(dbCtx,
+		`SELECT id, display_name, avatar_seed, has_custom_avatar, is_public, created_at FROM users WHERE id = $1`,
+		userID).Scan(&profile.ID, &profile.DisplayName, &profile.AvatarSeed, &profile.HasCustomAvatar, &profile.IsPublic, &profile.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("profile not found")
+	}
+
+	if !profile.IsPublic {
+		return nil, fmt.Errorf("profile is private")
+	}
+
+	stats := &result.Stats
+	if err := database.DB.QueryRow(dbCtx, queryUsersCommentStats, userID).Scan(
+		&stats.CommentsCount, &stats.LikesReceived, &stats.RepliesReceived, &stats.NovelsCount); err != nil {
+		logger.Error("Failed to get comment stats for %s: %v", userID, err)
+		return nil, err
+	}
+
+	result.TotalPages = (stats.CommentsCount + pageSize - 1) / pageSize
+	if stats.CommentsCount == 0 {
+		return result, nil
+	}
+
+	rows, err := database.DB.Query(dbCtx, queryUsersGetComments, userID, pageSize, offset)
+	if err != nil {
+		logger.Error("Failed to get comments for %s: %v", userID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c models.UserComment
+		if err := rows.Scan(&c.ID, &c.ChapterID, &c.ChapterNum, &c.ChapterTitle, &c.NovelID, &c.NovelTitle,
+			&c.ContentHTML, &c.LikesCount, &c.RepliesCount, &c.CreatedAt); err != nil {
+			logger.Warn("User comment row scan error: %v", err)
+			continue
+		}
+		result.Comments = append(result.Comments, c)
+	}
+
+	return result, nil
+}
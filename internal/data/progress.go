@@ -0,0 +1,106 @@
+package data
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ch1kulya/kappalib/internal/database"
+	"github.com/ch1kulya/kappalib/internal/models"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/ch1kulya/logger"
+)
+
+//go:embed sql/progress_get_list.sql
+var queryProgressGetList string
+
+//go:embed sql/progress_get_one.sql
+var queryProgressGetOne string
+
+//go:embed sql/progress_upsert.sql
+var queryProgressUpsert string
+
+func scanReadingProgress(row pgx.Row) (*models.ReadingProgress, error) {
+	var p models.ReadingProgress
+	if err := row.Scan(&p.NovelID, &p.ChapterID, &p.ChapterNum, &p.Paragraph,
+		&p.ScrollPercent, &p.UpdatedAt, &p.TotalChapters); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func GetReadingProgressList(ctx context.Context, profileID, secretToken string, limit int) ([]models.ReadingProgress, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return nil, fmt.Errorf("invalid secret token")
+	}
+
+	rows, err := database.DB.Query(dbCtx, queryProgressGetList, profileID, limit)
+	if err != nil {
+		logger.Error("Failed to get reading progress: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	progress := make([]models.ReadingProgress, 0)
+	for rows.Next() {
+		p, err := scanReadingProgress(rows)
+		if err != nil {
+			logger.Warn("Reading progress row scan error: %v", err)
+			continue
+		}
+		progress = append(progress, *p)
+	}
+
+	return progress, nil
+}
+
+func GetReadingProgress(ctx context.Context, profileID, secretToken, novelID string) (*models.ReadingProgress, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return nil, fmt.Errorf("invalid secret token")
+	}
+
+	p, err := scanReadingProgress(database.DB.QueryRow(dbCtx, queryProgressGetOne, profileID, novelID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("progress not found")
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+func SaveReadingProgress(ctx context.Context, profileID, secretToken, novelID, chapterID string, paragraph int, scrollPercent float64) (*models.ReadingProgress, error) {
+	if paragraph < 0 || scrollPercent < 0 || scrollPercent > 100 {
+		return nil, fmt.Errorf("invalid position")
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return nil, fmt.Errorf("invalid secret token")
+	}
+
+	p, err := scanReadingProgress(database.DB.QueryRow(dbCtx, queryProgressUpsert,
+		profileID, novelID, chapterID, paragraph, scrollPercent))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("chapter not found")
+		}
+		logger.Error("Failed to save reading progress: %v", err)
+		return nil, err
+	}
+
+	database.DB.Exec(dbCtx, `UPDATE users SET last_active_at = now() WHERE id = $1`, profileID)
+
+	return p, nil
+}
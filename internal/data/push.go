@@ -0,0 +1,457 @@
+package data
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ch1kulya/kappalib/internal/database"
+	"github.com/ch1kulya/kappalib/internal/webpush"
+
+	"github.com/ch1kulya/logger"
+)
+
+//go:embed sql/push_pending.sql
+var queryPushPending string
+
+//go:embed sql/push_mark_sent.sql
+var queryPushMarkSent string
+
+//go:embed sql/push_subscription_upsert.sql
+var queryPushSubscriptionUpsert string
+
+const (
+	pushPollInterval      = 30 * time.Second
+	pushBatchSize         = 200
+	pushMaxAgeHours       = 24
+	pushMaxAttempts       = 3
+	pushMaxFailures       = 5
+	pushWorkers           = 8
+	pushMaxSubscriptions  = 10
+	pushNotificationTTL   = 24 * 60 * 60
+	pushMaxRetryAfterWait = time.Minute
+)
+
+var (
+	pushSender     *webpush.Sender
+	pushSenderOnce sync.Once
+)
+
+type PushSubscriptionInput struct {
+	Endpoint string
+	P256dh   string
+	Auth     string
+}
+
+type pushMessage struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	URL   string `json:"url"`
+	Tag   string `json:"tag"`
+}
+
+type pushTarget struct {
+	id  int64
+	sub webpush.Subscription
+}
+
+type pendingPush struct {
+	notificationID int64
+	userID         string
+	message        pushMessage
+	updatedAt      time.Time
+}
+
+func getPushSender() *webpush.Sender {
+	pushSenderOnce.Do(func() {
+		publicKey := os.Getenv("VAPID_PUBLIC_KEY")
+		privateKey := os.Getenv("VAPID_PRIVATE_KEY")
+		if publicKey == "" || privateKey == "" {
+			logger.Warn("VAPID keys are not configured, Web Push is disabled")
+			return
+		}
+
+		subject := os.Getenv("VAPID_SUBJECT")
+		if subject == "" {
+			subject = "mailto:support@kappalib.ru"
+		}
+
+		vapid, err := webpush.NewVAPID(publicKey, privateKey, subject)
+		if err != nil {
+			logger.Error("Failed to load VAPID keys: %v", err)
+			return
+		}
+		pushSender = webpush.NewSender(vapid, nil)
+	})
+	return pushSender
+}
+
+func GetPushPublicKey() (string, error) {
+	sender := getPushSender()
+	if sender == nil {
+		return "", fmt.Errorf("push disabled")
+	}
+	return sender.PublicKey(), nil
+}
+
+func validatePushEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid endpoint")
+	}
+	if u.Scheme == "https" {
+		return nil
+	}
+	if u.Scheme == "http" && os.Getenv("WEBPUSH_ALLOW_INSECURE_ENDPOINTS") == "true" {
+		return nil
+	}
+	return fmt.Errorf("invalid endpoint")
+}
+
+func SavePushSubscription(ctx context.Context, profileID, secretToken, userAgent string, input PushSubscriptionInput) error {
+	if getPushSender() == nil {
+		return fmt.Errorf("push disabled")
+	}
+	if err := validatePushEndpoint(input.Endpoint); err != nil {
+		return err
+	}
+	if err := webpush.ValidateKeys(input.P256dh, input.Auth); err != nil {
+		return fmt.Errorf("invalid subscription keys")
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return fmt.Errorf("invalid secret token")
+	}
+
+	var count int
+	err := database.DB.QueryRow(dbCtx,
+		`SELECT COUNT(*) FROM push_subscriptions WHERE user_id = $1 AND endpoint <> $2`,
+		profileID, input.Endpoint,
+	).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count >= pushMaxSubscriptions {
+		return fmt.Errorf("too many subscriptions")
+	}
+
+	if len(userAgent) > 300 {
+		userAgent = userAgent[:300]
+	}
+
+	_, err = database.DB.Exec(dbCtx, queryPushSubscriptionUpsert,
+		profileID, input.Endpoint, input.P256dh, input.Auth, userAgent)
+	if err != nil {
+		logger.Error("Failed to save push subscription: %v", err)
+		return err
+	}
+
+	logger.Info("Push subscription saved for profile %s", profileID)
+	return nil
+}
+
+func DeletePushSubscription(ctx context.Context, profileID, secretToken, endpoint string) error {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return fmt.Errorf("invalid secret token")
+	}
+
+	result, err := database.DB.Exec(dbCtx,
+		`DELETE FROM push_subscriptions WHERE user_id = $1 AND endpoint = $2`,
+		profileID, endpoint)
+	if err != nil {
+		logger.Error("Failed to delete push subscription: %v", err)
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("subscription not found")
+	}
+
+	return nil
+}
+
+func SendTestPush(ctx context.Context, profileID, secretToken string) (int, error) {
+	sender := getPushSender()
+	if sender == nil {
+		return 0, fmt.Errorf("push disabled")
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return 0, fmt.Errorf("invalid secret token")
+	}
+
+	targets, err := loadPushTargets(dbCtx, []string{profileID})
+	if err != nil {
+		return 0, err
+	}
+	if len(targets[profileID]) == 0 {
+		return 0, fmt.Errorf("subscription not found")
+	}
+
+	payload, _ := json.Marshal(pushMessage{
+		Title: "kappalib",
+		Body:  "Push-уведомления работают",
+		URL:   "/notifications",
+		Tag:   "test",
+	})
+
+	delivered := 0
+	for _, target := range targets[profileID] {
+		if deliverPush(ctx, sender, target, payload, "test") {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+func loadPushTargets(ctx context.Context, userIDs []string) (map[string][]pushTarget, error) {
+	rows, err := database.DB.Query(ctx,
+		`SELECT id, user_id, endpoint, p256dh, auth FROM push_subscriptions WHERE user_id = ANY($1)`,
+		userIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	targets := make(map[string][]pushTarget)
+	for rows.Next() {
+		var t pushTarget
+		var userID string
+		if err := rows.Scan(&t.id, &userID, &t.sub.Endpoint, &t.sub.P256dh, &t.sub.Auth); err != nil {
+			logger.Warn("Push subscription scan error: %v", err)
+			continue
+		}
+		targets[userID] = append(targets[userID], t)
+	}
+	return targets, nil
+}
+
+func loadPendingPushes(ctx context.Context) ([]pendingPush, error) {
+	rows, err := database.DB.Query(ctx, queryPushPending, pushMaxAgeHours, pushBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pending := make([]pendingPush, 0)
+	for rows.Next() {
+		var p pendingPush
+		var notificationType, novelID, novelTitle string
+		var chapterID, commentID, actorName *string
+		var firstNum, lastNum int
+		if err := rows.Scan(&p.notificationID, &p.userID, &notificationType, &novelID, &novelTitle,
+			&chapterID, &firstNum, &lastNum, &commentID, &actorName, &p.updatedAt); err != nil {
+			logger.Warn("Pending push scan error: %v", err)
+			continue
+		}
+
+		link := "/" + novelID
+		if chapterID != nil {
+			link = fmt.Sprintf("/%s/chapter/%s", novelID, *chapterID)
+		}
+
+		if notificationType == "comment_reply" && commentID != nil {
+			author := "Кто-то"
+			if actorName != nil {
+				author = *actorName
+			}
+			p.message = pushMessage{
+				Title: novelTitle,
+				Body:  fmt.Sprintf("%s ответил(а) на ваш комментарий", author),
+				URL:   fmt.Sprintf("%s#comment-%s", link, *commentID),
+				Tag:   "comment-" + *commentID,
+			}
+		} else {
+			body := fmt.Sprintf("Новая глава %d", firstNum)
+			if lastNum > firstNum {
+				body = fmt.Sprintf("Новые главы %d–%d", firstNum, lastNum)
+			}
+			p.message = pushMessage{
+				Title: novelTitle,
+				Body:  body,
+				URL:   link,
+				Tag:   "novel-" + novelID,
+			}
+		}
+		pending = append(pending, p)
+	}
+	return pending, nil
+}
+
+func deliverPush(ctx context.Context, sender *webpush.Sender, target pushTarget, payload []byte, topic string) bool {
+	opts := webpush.Options{TTL: pushNotificationTTL, Urgency: "normal", Topic: topic}
+
+	var err error
+	for attempt := 1; attempt <= pushMaxAttempts; attempt++ {
+		err = sender.Send(ctx, target.sub, payload, opts)
+		if err == nil {
+			database.DB.Exec(ctx,
+				`UPDATE push_subscriptions SET failure_count = 0, last_success_at = now() WHERE id = $1`,
+				target.id)
+			return true
+		}
+
+		if errors.Is(err, webpush.ErrSubscriptionGone) {
+			logger.Info("Removing expired push subscription %d", target.id)
+			database.DB.Exec(ctx, `DELETE FROM push_subscriptions WHERE id = $1`, target.id)
+			return false
+		}
+
+		var tempErr *webpush.TemporaryError
+		if !errors.As(err, &tempErr) || attempt == pushMaxAttempts {
+			break
+		}
+
+		wait := time.Duration(1<<(attempt-1)) * time.Second
+		if tempErr.RetryAfter > wait {
+			wait = min(tempErr.RetryAfter, pushMaxRetryAfterWait)
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(wait):
+		}
+	}
+
+	logger.Warn("Push delivery to subscription %d failed: %v", target.id, err)
+	recordPushFailure(ctx, target.id)
+	return false
+}
+
+func recordPushFailure(ctx context.Context, subscriptionID int64) {
+	var failures int
+	err := database.DB.QueryRow(ctx,
+		`UPDATE push_subscriptions SET failure_count = failure_count + 1 WHERE id = $1 RETURNING failure_count`,
+		subscriptionID,
+	).Scan(&failures)
+	if err != nil {
+		return
+	}
+
+	if failures >= pushMaxFailures {
+		logger.Info("Pruning push subscription %d after %d failed deliveries", subscriptionID, failures)
+		database.DB.Exec(ctx, `DELETE FROM push_subscriptions WHERE id = $1`, subscriptionID)
+	}
+}
+
+func DeliverPendingPushes(ctx context.Context) error {
+	sender := getPushSender()
+	if sender == nil {
+		return nil
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	pending, err := loadPendingPushes(dbCtx)
+	cancel()
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	userIDs := make([]string, 0, len(pending))
+	seen := make(map[string]bool)
+	for _, p := range pending {
+		if !seen[p.userID] {
+			seen[p.userID] = true
+			userIDs = append(userIDs, p.userID)
+		}
+	}
+
+	dbCtx, cancel = context.WithTimeout(ctx, 10*time.Second)
+	targets, err := loadPushTargets(dbCtx, userIDs)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	type job struct {
+		target  pushTarget
+		payload []byte
+		topic   string
+	}
+
+	jobs := make(chan job)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	delivered, failed := 0, 0
+
+	for range pushWorkers {
+		wg.Go(func() {
+			for j := range jobs {
+				sendCtx, sendCancel := context.WithTimeout(ctx, 2*time.Minute)
+				ok := deliverPush(sendCtx, sender, j.target, j.payload, j.topic)
+				sendCancel()
+
+				mu.Lock()
+				if ok {
+					delivered++
+				} else {
+					failed++
+				}
+				mu.Unlock()
+			}
+		})
+	}
+
+	ids := make([]int64, 0, len(pending))
+	updatedAt := make([]time.Time, 0, len(pending))
+	for _, p := range pending {
+		payload, err := json.Marshal(p.message)
+		if err != nil {
+			continue
+		}
+		for _, target := range targets[p.userID] {
+			jobs <- job{target: target, payload: payload, topic: p.message.Tag}
+		}
+		ids = append(ids, p.notificationID)
+		updatedAt = append(updatedAt, p.updatedAt)
+	}
+	close(jobs)
+	wg.Wait()
+
+	dbCtx, cancel = context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if _, err := database.DB.Exec(dbCtx, queryPushMarkSent, ids, updatedAt); err != nil {
+		return err
+	}
+
+	logger.Debug("Web Push: %d delivered, %d failed for %d notifications", delivered, failed, len(ids))
+	return nil
+}
+
+func StartPushDelivery(ctx context.Context) {
+	if getPushSender() == nil {
+		return
+	}
+
+	ticker := time.NewTicker(pushPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := DeliverPendingPushes(ctx); err != nil {
+				logger.Error("Failed to deliver push notifications: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package data
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"time"
+
+	"github.com/ch1kulya/kappalib/internal/cache"
+	"github.com/ch1kulya/kappalib/internal/database"
+	"github.com/ch1kulya/kappalib/internal/models"
+
+	"github.com/ch1kulya/logger"
+)
+
+//go:embed sql/rankings_refresh.sql
+var queryRankingsRefresh string
+
+//go:embed sql/novels_trending.sql
+var queryNovelsTrending string
+
+const (
+	rankingsRefreshInterval = 15 * time.Minute
+	trendingWindowDays      = 7
+	trendingDecayDays       = 3.0
+)
+
+func RefreshRankings(ctx context.Context) error {
+	dbCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	result, err := database.DB.Exec(dbCtx, queryRankingsRefresh, trendingDecayDays, trendingWindowDays)
+	if err != nil {
+		return err
+	}
+
+	logger.Debug("Rankings refreshed for %d novels", result.RowsAffected())
+	return nil
+}
+
+func StartRankingsRefresher(ctx context.Context) {
+	if err := RefreshRankings(ctx); err != nil {
+		logger.Error("Failed to refresh rankings: %v", err)
+	}
+
+	ticker := time.NewTicker(rankingsRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := RefreshRankings(ctx); err != nil {
+				logger.Error("Failed to refresh rankings: %v", err)
+			}
+		}
+	}
+}
+
+func GetTrendingNovels(ctx context.Context, limit int) ([]models.Novel, error) {
+	key := fmt.Sprintf("novels:trending:%d", limit)
+
+	value, err := cache.C.GetOrFetch(key, 10*time.Minute, func() (any, error) {
+		dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		rows, err := database.DB.Query(dbCtx, queryNovelsTrending, limit)
+		if err != nil {
+			logger.Error("GetTrendingNovels: Query failed: %v", err)
+			return nil, err
+		}
+		defer rows.Close()
+
+		novels := make([]models.Novel, 0, limit)
+		for rows.Next() {
+			var n models.Novel
+			if err := rows.Scan(&n.ID, &n.Title, &n.TitleEn, &n.Author,
+				&n.YearStart, &n.YearEnd, &n.Status, &n.Description,
+				&n.AgeRating, &n.CoverURL, &n.ViewsCount, &n.CreatedAt); err != nil {
+				logger.Warn("GetTrendingNovels: Row scan error: %v", err)
+				continue
+			}
+			novels = append(novels, n)
+		}
+
+		return novels, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	return value.([]models.Novel), nil
+}
@@ -0,0 +1,165 @@
+package data
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ch1kulya/kappalib/internal/cache"
+	"github.com/ch1kulya/kappalib/internal/database"
+	"github.com/ch1kulya/kappalib/internal/models"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/ch1kulya/logger"
+)
+
+//go:embed sql/ratings_distribution.sql
+var queryRatingsDistribution string
+
+//go:embed sql/ratings_upsert.sql
+var queryRatingsUpsert string
+
+//go:embed sql/reviews_get_user.sql
+var queryReviewsGetUser string
+
+const (
+	minRatingScore = 1
+	maxRatingScore = 10
+)
+
+func ratingSummaryKey(novelID string) string {
+	return fmt.Sprintf("novel:rating:%s", novelID)
+}
+
+func GetRatingSummary(ctx context.Context, novelID string) (*models.RatingSummary, error) {
+	value, err := cache.C.GetOrFetch(ratingSummaryKey(novelID), 10*time.Minute, func() (any, error) {
+		dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		rows, err := database.DB.Query(dbCtx, queryRatingsDistribution, novelID)
+		if err != nil {
+			logger.Error("GetRatingSummary: Query failed: %v", err)
+			return nil, err
+		}
+		defer rows.Close()
+
+		summary := &models.RatingSummary{
+			NovelID:      novelID,
+			Distribution: make([]int, maxRatingScore),
+		}
+
+		total := 0
+		for rows.Next() {
+			var score, cnt int
+			if err := rows.Scan(&score, &cnt); err != nil {
+				logger.Warn("GetRatingSummary: Row scan error: %v", err)
+				continue
+			}
+			if score < minRatingScore || score > maxRatingScore {
+				continue
+			}
+			summary.Distribution[score-1] = cnt
+			summary.Count += cnt
+			total += score * cnt
+		}
+
+		if summary.Count > 0 {
+			summary.Average = float64(total) / float64(summary.Count)
+		}
+
+		return summary, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	return value.(*models.RatingSummary), nil
+}
+
+func GetUserRating(ctx context.Context, profileID, secretToken, novelID string) (*models.UserRating, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return nil, fmt.Errorf("invalid secret token")
+	}
+
+	result := &models.UserRating{NovelID: novelID}
+
+	var score int
+	err := database.DB.QueryRow(dbCtx,
+		`SELECT score FROM novel_ratings WHERE user_id = $1 AND novel_id = $2`,
+		profileID, novelID,
+	).Scan(&score)
+	if err == nil {
+		result.Score = &score
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	var r models.Review
+	err = database.DB.QueryRow(dbCtx, queryReviewsGetUser, profileID, novelID).Scan(
+		&r.ID, &r.NovelID, &r.UserID, &r.ContentHTML, &r.Status, &r.Score, &r.CreatedAt, &r.UpdatedAt)
+	if err == nil {
+		result.Review = &r
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func RateNovel(ctx context.Context, profileID, secretToken, novelID string, score int) (*models.UserRating, error) {
+	if score < minRatingScore || score > maxRatingScore {
+		return nil, fmt.Errorf("invalid rating")
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return nil, fmt.Errorf("invalid secret token")
+	}
+
+	if !novelExists(dbCtx, novelID) {
+		return nil, fmt.Errorf("novel not found")
+	}
+
+	var saved int
+	if err := database.DB.QueryRow(dbCtx, queryRatingsUpsert, profileID, novelID, score).Scan(&saved); err != nil {
+		logger.Error("Failed to save rating: %v", err)
+		return nil, err
+	}
+
+	cache.C.Delete(ratingSummaryKey(novelID))
+	database.DB.Exec(dbCtx, `UPDATE users SET last_active_at = now() WHERE id = $1`, profileID)
+
+	logger.Debug("Profile %s rated novel %s: %d", profileID, novelID, saved)
+	return &models.UserRating{NovelID: novelID, Score: &saved}, nil
+}
+
+func UnrateNovel(ctx context.Context, profileID, secretToken, novelID string) error {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return fmt.Errorf("invalid secret token")
+	}
+
+	result, err := database.DB.Exec(dbCtx,
+		`DELETE FROM novel_ratings WHERE user_id = $1 AND novel_id = $2`,
+		profileID, novelID)
+	if err != nil {
+		logger.Error("Failed to delete rating: %v", err)
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("rating not found")
+	}
+
+	cache.C.Delete(ratingSummaryKey(novelID))
+	return nil
+}
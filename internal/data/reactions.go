@@ -0,0 +1,126 @@
+package data
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ch1kulya/kappalib/internal/database"
+	"github.com/ch1kulya/kappalib/internal/models"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/ch1kulya/logger"
+)
+
+//go:embed sql/comment_reactions_upsert.sql
+var queryCommentReactionsUpsert string
+
+//go:embed sql/comment_reactions_get_chapter.sql
+var queryCommentReactionsGetChapter string
+
+var reactionValues = map[string]int{
+	"like":    1,
+	"dislike": -1,
+}
+
+func reactionName(value int) string {
+	for name, v := range reactionValues {
+		if v == value {
+			return name
+		}
+	}
+	return ""
+}
+
+func commentReactionCounts(ctx context.Context, commentID, reaction string) (*models.CommentReaction, error) {
+	r := &models.CommentReaction{CommentID: commentID, Reaction: reaction}
+	err := database.DB.QueryRow(ctx,
+		`SELECT likes_count, dislikes_count FROM comments WHERE id = $1`,
+		commentID,
+	).Scan(&r.LikesCount, &r.DislikesCount)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func SetCommentReaction(ctx context.Context, profileID, secretToken, commentID, reaction string) (*models.CommentReaction, error) {
+	value, ok := reactionValues[reaction]
+	if !ok {
+		return nil, fmt.Errorf("invalid reaction")
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return nil, fmt.Errorf("invalid secret token")
+	}
+
+	var savedID string
+	err := database.DB.QueryRow(dbCtx, queryCommentReactionsUpsert, profileID, commentID, value).Scan(&savedID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("comment not found")
+		}
+		logger.Error("Failed to save comment reaction: %v", err)
+		return nil, err
+	}
+
+	return commentReactionCounts(dbCtx, commentID, reaction)
+}
+
+func DeleteCommentReaction(ctx context.Context, profileID, secretToken, commentID string) (*models.CommentReaction, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return nil, fmt.Errorf("invalid secret token")
+	}
+
+	_, err := database.DB.Exec(dbCtx,
+		`DELETE FROM comment_reactions WHERE user_id = $1 AND comment_id = $2`,
+		profileID, commentID)
+	if err != nil {
+		logger.Error("Failed to delete comment reaction: %v", err)
+		return nil, err
+	}
+
+	r, err := commentReactionCounts(dbCtx, commentID, "")
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("comment not found")
+		}
+		return nil, err
+	}
+	return r, nil
+}
+
+func GetChapterReactions(ctx context.Context, profileID, secretToken, chapterID string) (map[string]string, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return nil, fmt.Errorf("invalid secret token")
+	}
+
+	rows, err := database.DB.Query(dbCtx, queryCommentReactionsGetChapter, profileID, chapterID)
+	if err != nil {
+		logger.Error("Failed to get chapter reactions: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	reactions := make(map[string]string)
+	for rows.Next() {
+		var commentID string
+		var value int
+		if err := rows.Scan(&commentID, &value); err != nil {
+			continue
+		}
+		reactions[commentID] = reactionName(value)
+	}
+	return reactions, nil
+}
@@ -0,0 +1,289 @@
+package data
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ch1kulya/kappalib/internal/database"
+	"github.com/ch1kulya/kappalib/internal/models"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/ch1kulya/logger"
+)
+
+const (
+	ReaderSettingsVersion   = 2
+	maxReaderCustomThemes   = 3
+	minReaderTextContrast   = 4.5
+	minReaderLinkContrast   = 3.0
+	customReaderThemePrefix = "custom:"
+
+	ReaderSettingsCookieName = "kl_reader"
+)
+
+var DefaultReaderSettings = models.ReaderSettings{
+	Version:    ReaderSettingsVersion,
+	Theme:      "auto",
+	FontSize:   18,
+	FontFamily: "default",
+	Indent:     0,
+	Density:    "normal",
+	Justify:    false,
+}
+
+var readerFontFamilies = []string{
+	"default", "literata", "nunito", "merriweather", "lora", "pt-serif", "open-sans", "roboto",
+}
+
+var hexColorRegex = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+func RelativeLuminance(hex string) float64 {
+	channel := func(v uint64) float64 {
+		c := float64(v) / 255
+		if c <= 0.03928 {
+			return c / 12.92
+		}
+		return math.Pow((c+0.055)/1.055, 2.4)
+	}
+	rgb, _ := strconv.ParseUint(strings.TrimPrefix(hex, "#"), 16, 32)
+	return 0.2126*channel(rgb>>16&0xff) + 0.7152*channel(rgb>>8&0xff) + 0.0722*channel(rgb&0xff)
+}
+
+func contrastRatio(a, b string) float64 {
+	la, lb := RelativeLuminance(a), RelativeLuminance(b)
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+func ReaderCustomTheme(settings models.ReaderSettings) *models.ReaderTheme {
+	if !strings.HasPrefix(settings.Theme, customReaderThemePrefix) {
+		return nil
+	}
+	idx, err := strconv.Atoi(strings.TrimPrefix(settings.Theme, customReaderThemePrefix))
+	if err != nil || idx < 0 || idx >= len(settings.CustomThemes) {
+		return nil
+	}
+	return &settings.CustomThemes[idx]
+}
+
+func validateReaderTheme(theme models.ReaderTheme) error {
+	for _, color := range []string{theme.Background, theme.Text, theme.Link} {
+		if !hexColorRegex.MatchString(color) {
+			return fmt.Errorf("invalid theme color")
+		}
+	}
+	if contrastRatio(theme.Text, theme.Background) < minReaderTextContrast ||
+		contrastRatio(theme.Link, theme.Background) < minReaderLinkContrast {
+		return fmt.Errorf("insufficient theme contrast")
+	}
+	return nil
+}
+
+func ValidateReaderSettings(settings models.ReaderSettings) error {
+	if len(settings.CustomThemes) > maxReaderCustomThemes {
+		return fmt.Errorf("too many custom themes")
+	}
+	for _, theme := range settings.CustomThemes {
+		if err := validateReaderTheme(theme); err != nil {
+			return err
+		}
+	}
+
+	switch settings.Theme {
+	case "light", "dark", "auto":
+	default:
+		if ReaderCustomTheme(settings) == nil {
+			return fmt.Errorf("invalid theme")
+		}
+	}
+
+	if settings.FontSize < 14 || settings.FontSize > 26 {
+		return fmt.Errorf("invalid font size")
+	}
+
+	if !slices.Contains(readerFontFamilies, settings.FontFamily) {
+		return fmt.Errorf("invalid font family")
+	}
+
+	if settings.Indent < 0 || settings.Indent > 3 {
+		return fmt.Errorf("invalid indent")
+	}
+
+	switch settings.Density {
+	case "compact", "normal", "relaxed":
+	default:
+		return fmt.Errorf("invalid density")
+	}
+
+	if settings.LineHeight != 0 && (settings.LineHeight < 1.2 || settings.LineHeight > 2.4) {
+		return fmt.Errorf("invalid line height")
+	}
+
+	if settings.ParagraphSpacing != 0 && (settings.ParagraphSpacing < 0.2 || settings.ParagraphSpacing > 3) {
+		return fmt.Errorf("invalid paragraph spacing")
+	}
+
+	if settings.MaxWidth != 0 && (settings.MaxWidth < 40 || settings.MaxWidth > 100) {
+		return fmt.Errorf("invalid max width")
+	}
+
+	return nil
+}
+
+func upgradeReaderSettings(settings models.ReaderSettings) (models.ReaderSettings, error) {
+	if settings.Version > ReaderSettingsVersion {
+		return settings, fmt.Errorf("unsupported settings version")
+	}
+	settings.Version = ReaderSettingsVersion
+	return settings, nil
+}
+
+func ParseReaderSettings(raw []byte) (models.ReaderSettings, error) {
+	settings := DefaultReaderSettings
+	settings.Version = 0
+	if err := json.Unmarshal(raw, &settings); err != nil {
+		return DefaultReaderSettings, err
+	}
+
+	settings, err := upgradeReaderSettings(settings)
+	if err != nil {
+		return DefaultReaderSettings, err
+	}
+
+	if err := ValidateReaderSettings(settings); err != nil {
+		return DefaultReaderSettings, err
+	}
+
+	return settings, nil
+}
+
+func loadReaderSettings(ctx context.Context, profileID string) (*models.ReaderSettings, error) {
+	var raw []byte
+	err := database.DB.QueryRow(ctx,
+		`SELECT reader_settings FROM users WHERE id = $1`, profileID,
+	).Scan(&raw)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("profile not found")
+		}
+		return nil, err
+	}
+
+	if raw == nil {
+		return nil, nil
+	}
+
+	settings, err := ParseReaderSettings(raw)
+	if err != nil {
+		logger.Warn("Stored reader settings of %s are invalid: %v", profileID, err)
+		return nil, nil
+	}
+	return &settings, nil
+}
+
+func GetReaderSettings(ctx context.Context, profileID, secretToken string) (*models.ReaderSettings, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return nil, fmt.Errorf("invalid secret token")
+	}
+
+	settings, err := loadReaderSettings(dbCtx, profileID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		defaults := DefaultReaderSettings
+		return &defaults, nil
+	}
+	return settings, nil
+}
+
+func signReaderSettingsCookie(profileID, sessionID string) string {
+	mac := hmac.New(sha256.New, tokenHashKey)
+	mac.Write([]byte("reader-settings:" + profileID + ":" + sessionID))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func IssueReaderSettingsCookie(ctx context.Context, profileID, secretToken string) (string, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	sessionID, ok := lookupSession(dbCtx, profileID, secretToken)
+	if !ok {
+		return "", fmt.Errorf("invalid secret token")
+	}
+
+	return profileID + ":" + sessionID + ":" + signReaderSettingsCookie(profileID, sessionID), nil
+}
+
+func GetCookieReaderSettings(ctx context.Context, value string) (*models.ReaderSettings, bool) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 {
+		return nil, false
+	}
+	profileID, sessionID, signature := parts[0], parts[1], parts[2]
+	if !hmac.Equal([]byte(signature), []byte(signReaderSettingsCookie(profileID, sessionID))) {
+		return nil, false
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	var active bool
+	err := database.DB.QueryRow(dbCtx,
+		`SELECT EXISTS(SELECT 1 FROM sessions WHERE id = $1 AND user_id = $2)`,
+		sessionID, profileID).Scan(&active)
+	if err != nil || !active {
+		return nil, false
+	}
+
+	settings, err := loadReaderSettings(dbCtx, profileID)
+	if err != nil || settings == nil {
+		return nil, false
+	}
+	return settings, true
+}
+
+func UpdateReaderSettings(ctx context.Context, profileID, secretToken string, settings models.ReaderSettings) (*models.ReaderSettings, error) {
+	settings, err := upgradeReaderSettings(settings)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateReaderSettings(settings); err != nil {
+		return nil, fmt.Errorf("invalid settings: %w", err)
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return nil, fmt.Errorf("invalid secret token")
+	}
+
+	raw, _ := json.Marshal(settings)
+	_, err = database.DB.Exec(dbCtx,
+		`UPDATE users SET reader_settings = $1, last_active_at = now() WHERE id = $2`,
+		raw, profileID)
+	if err != nil {
+		logger.Error("Failed to save reader settings: %v", err)
+		return nil, err
+	}
+
+	logger.Debug("Updated reader settings for %s", profileID)
+	return &settings, nil
+}
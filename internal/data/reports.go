@@ -0,0 +1,207 @@
+package data
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ch1kulya/kappalib/internal/database"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/ch1kulya/logger"
+)
+
+//go:embed sql/comment_reports_insert.sql
+var queryCommentReportsInsert string
+
+//go:embed sql/comment_reports_count.sql
+var queryCommentReportsCount string
+
+//go:embed sql/comment_reports_hide.sql
+var queryCommentReportsHide string
+
+//go:embed sql/comment_reports_reasons.sql
+var queryCommentReportsReasons string
+
+//go:embed sql/comment_reports_resolve.sql
+var queryCommentReportsResolve string
+
+const defaultReportHideThreshold = 3
+
+var reportHideThreshold = loadReportHideThreshold()
+
+func loadReportHideThreshold() int {
+	value := os.Getenv("COMMENT_REPORT_HIDE_THRESHOLD")
+	if value == "" {
+		return defaultReportHideThreshold
+	}
+	threshold, err := strconv.Atoi(value)
+	if err != nil || threshold < 1 {
+		logger.Warn("Invalid COMMENT_REPORT_HIDE_THRESHOLD %q, using %d", value, defaultReportHideThreshold)
+		return defaultReportHideThreshold
+	}
+	return threshold
+}
+
+var reportReasons = map[string]string{
+	"spam":      "Спам",
+	"offensive": "Оскорбления",
+	"spoiler":   "Спойлер",
+	"other":     "Другое",
+}
+
+func ReportComment(ctx context.Context, profileID, secretToken, commentID, reason string) error {
+	if _, ok := reportReasons[reason]; !ok {
+		return fmt.Errorf("invalid reason")
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return fmt.Errorf("invalid secret token")
+	}
+
+	var reportedID string
+	err := database.DB.QueryRow(dbCtx, queryCommentReportsInsert, profileID, commentID, reason).Scan(&reportedID)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			logger.Error("Failed to save comment report: %v", err)
+			return err
+		}
+		comment, err := getCommentByID(dbCtx, commentID)
+		switch {
+		case err != nil || comment.Status != "approved" || comment.IsDeleted:
+			return fmt.Errorf("comment not found")
+		case comment.UserID == profileID:
+			return fmt.Errorf("cannot report own comment")
+		default:
+			return fmt.Errorf("already reported")
+		}
+	}
+
+	// Reports made before a moderator kept the comment no longer count,
+	// so a kept comment can still be hidden and re-sent after new reports.
+	var reportsCount int
+	if err := database.DB.QueryRow(dbCtx, queryCommentReportsCount, commentID).Scan(&reportsCount); err != nil {
+		logger.Error("Failed to count comment reports: %v", err)
+		return err
+	}
+
+	hidden := false
+	if reportsCount >= reportHideThreshold {
+		var hiddenID string
+		if err := database.DB.QueryRow(dbCtx, queryCommentReportsHide, commentID, reportHideThreshold).Scan(&hiddenID); err == nil {
+			hidden = true
+			logger.Info("Comment %s hidden after %d reports", commentID, reportsCount)
+		}
+	}
+
+	if reportsCount == 1 || hidden {
+		go sendReportToTelegram(context.Background(), commentID, reportsCount, hidden)
+	}
+
+	logger.Info("Comment %s reported by user %s: %s", commentID, profileID, reason)
+	return nil
+}
+
+func ResolveCommentReports(ctx context.Context, commentID, status string) error {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// An edited comment is pending moderation of its new text: keeping it
+	// leaves that moderation in place, removing it also drops its message.
+	var staleMessageID *int64
+	if err := database.DB.QueryRow(dbCtx, queryCommentReportsResolve, status, commentID).Scan(&staleMessageID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("comment not found")
+		}
+		logger.Error("Failed to resolve comment reports: %v", err)
+		return err
+	}
+
+	if staleMessageID != nil {
+		go deleteModerationMessage(*staleMessageID)
+	}
+
+	logger.Info("Reports on comment %s resolved, status %s", commentID, status)
+	return nil
+}
+
+func sendReportToTelegram(ctx context.Context, commentID string, reportsCount int, hidden bool) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if telegramBotToken == "" || telegramChatID == "" {
+		logger.Warn("Telegram credentials not set, skipping notification")
+		return
+	}
+
+	comment, err := getCommentByID(ctx, commentID)
+	if err != nil {
+		logger.Error("Failed to load reported comment %s: %v", commentID, err)
+		return
+	}
+
+	database.DB.QueryRow(ctx, `SELECT display_name FROM users WHERE id = $1`, comment.UserID).Scan(&comment.UserDisplayName)
+
+	var reasons []string
+	rows, err := database.DB.Query(ctx, queryCommentReportsReasons, commentID)
+	if err == nil {
+		for rows.Next() {
+			var reason string
+			var cnt int
+			if err := rows.Scan(&reason, &cnt); err != nil {
+				continue
+			}
+			reasons = append(reasons, fmt.Sprintf("• %s: %d", reportReasons[reason], cnt))
+		}
+		rows.Close()
+	}
+
+	status := ""
+	if hidden {
+		status = "\n🙈 Комментарий скрыт автоматически"
+	}
+
+	text := fmt.Sprintf(
+		"🚩 <b>Жалоба на комментарий</b> <code>%s</code>\n\n"+
+			"👤 Автор: %s\n"+
+			"📖 Глава: <code>%s</code>\n"+
+			"⚠️ Жалоб: %d%s\n%s\n\n"+
+			"📝 Текст:\n%s",
+		comment.ID,
+		comment.UserDisplayName,
+		comment.ChapterID,
+		reportsCount,
+		status,
+		strings.Join(reasons, "\n"),
+		htmlToTelegramHTML(comment.ContentHTML),
+	)
+
+	if len(text) > 4000 {
+		text = text[:4000] + "..."
+	}
+
+	if comment.TelegramMessageID != nil {
+		deleteModerationMessage(*comment.TelegramMessageID)
+	}
+
+	messageID, err := sendModerationButtons(ctx, text, []map[string]string{
+		{"text": "✅ Оставить", "callback_data": fmt.Sprintf("report_keep:%s", comment.ID)},
+		{"text": "🗑 Удалить", "callback_data": fmt.Sprintf("report_remove:%s", comment.ID)},
+	})
+	if err != nil {
+		logger.Error("Failed to send comment report to telegram: %v", err)
+		return
+	}
+
+	dbCtx, dbCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer dbCancel()
+	database.DB.Exec(dbCtx, queryCommentsSetTelegramMessageID, messageID, comment.ID)
+}
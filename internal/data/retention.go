@@ -0,0 +1,179 @@
+package data
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ch1kulya/kappalib/internal/database"
+	"github.com/ch1kulya/kappalib/internal/models"
+
+	"github.com/ch1kulya/logger"
+)
+
+//go:embed sql/profiles_abandoned_count.sql
+var queryProfilesAbandonedCount string
+
+//go:embed sql/profiles_abandoned_lock.sql
+var queryProfilesAbandonedLock string
+
+const (
+	defaultProfileRetentionDays = 365
+	profileCleanupBatch         = 500
+	profileCleanupInterval      = 24 * time.Hour
+)
+
+func ProfileRetentionDays() int {
+	value := os.Getenv("PROFILE_RETENTION_DAYS")
+	if value == "" {
+		return defaultProfileRetentionDays
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 0 {
+		logger.Warn("Invalid PROFILE_RETENTION_DAYS %q, using %d", value, defaultProfileRetentionDays)
+		return defaultProfileRetentionDays
+	}
+	return days
+}
+
+func CleanupAbandonedProfiles(ctx context.Context, retentionDays int, dryRun bool) (*models.ProfileCleanupResult, error) {
+	if retentionDays <= 0 {
+		return nil, fmt.Errorf("retention period must be positive")
+	}
+
+	result := &models.ProfileCleanupResult{}
+
+	if dryRun || minioClient == nil {
+		dbCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+
+		err := database.DB.QueryRow(dbCtx, queryProfilesAbandonedCount, retentionDays).Scan(&result.Profiles, &result.Avatars)
+		if dryRun || err != nil {
+			return result, err
+		}
+		if result.Avatars > 0 {
+			return nil, fmt.Errorf("s3 not configured, refusing to delete %d profiles with avatars", result.Avatars)
+		}
+		result = &models.ProfileCleanupResult{}
+	}
+
+	for {
+		batch, err := deleteAbandonedProfilesBatch(ctx, retentionDays)
+		result.Profiles += batch.deleted
+		result.Avatars += batch.avatars
+		if err != nil {
+			return result, err
+		}
+		if batch.failed > 0 {
+			return result, fmt.Errorf("failed to remove avatars of %d profiles, they are kept for the next run", batch.failed)
+		}
+
+		if batch.selected < profileCleanupBatch {
+			return result, nil
+		}
+	}
+}
+
+type profileCleanupBatchResult struct {
+	selected, deleted, avatars, failed int
+}
+
+func deleteAbandonedProfilesBatch(ctx context.Context, retentionDays int) (profileCleanupBatchResult, error) {
+	var batch profileCleanupBatchResult
+
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	tx, err := database.DB.Begin(dbCtx)
+	if err != nil {
+		return batch, err
+	}
+	defer tx.Rollback(dbCtx)
+
+	rows, err := tx.Query(dbCtx, queryProfilesAbandonedLock, retentionDays, profileCleanupBatch)
+	if err != nil {
+		return batch, err
+	}
+
+	var ids, avatars []string
+	for rows.Next() {
+		var id string
+		var hasAvatar bool
+		if err := rows.Scan(&id, &hasAvatar); err != nil {
+			rows.Close()
+			return batch, err
+		}
+		if hasAvatar {
+			avatars = append(avatars, id)
+		} else {
+			ids = append(ids, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return batch, err
+	}
+	batch.selected = len(ids) + len(avatars)
+
+	if len(avatars) > 0 && minioClient == nil {
+		return batch, fmt.Errorf("s3 not configured, cannot remove avatars of %d profiles", len(avatars))
+	}
+
+	for _, id := range avatars {
+		if err := deleteAvatarObjects(dbCtx, id); err != nil {
+			logger.Warn("Failed to remove avatar of abandoned profile %s: %v", id, err)
+			batch.failed++
+			continue
+		}
+		ids = append(ids, id)
+		batch.avatars++
+	}
+
+	if len(ids) > 0 {
+		if _, err := tx.Exec(dbCtx, `DELETE FROM users WHERE id = ANY($1)`, ids); err != nil {
+			return profileCleanupBatchResult{}, err
+		}
+	}
+
+	if err := tx.Commit(dbCtx); err != nil {
+		return profileCleanupBatchResult{}, err
+	}
+
+	batch.deleted = len(ids)
+	return batch, nil
+}
+
+func runProfileCleanup(ctx context.Context, retentionDays int) {
+	result, err := CleanupAbandonedProfiles(ctx, retentionDays, false)
+	if err != nil {
+		logger.Warn("Failed to clean up abandoned profiles: %v", err)
+	}
+	if result != nil && result.Profiles > 0 {
+		logger.Info("Removed %d abandoned profiles and %d avatars", result.Profiles, result.Avatars)
+	}
+}
+
+func StartProfileCleaner(ctx context.Context) {
+	retentionDays := ProfileRetentionDays()
+	if retentionDays == 0 {
+		logger.Info("Abandoned profile cleanup disabled")
+		return
+	}
+
+	runProfileCleanup(ctx, retentionDays)
+
+	ticker := time.NewTicker(profileCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			runProfileCleanup(ctx, retentionDays)
+		}
+	}
+}
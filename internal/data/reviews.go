@@ -0,0 +1,232 @@
+package data
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ch1kulya/kappalib/internal/database"
+	"github.com/ch1kulya/kappalib/internal/models"
+
+	"github.com/ch1kulya/logger"
+)
+
+//go:embed sql/reviews_upsert.sql
+var queryReviewsUpsert string
+
+//go:embed sql/reviews_get_approved.sql
+var queryReviewsGetApproved string
+
+//go:embed sql/reviews_count_approved.sql
+var queryReviewsCountApproved string
+
+//go:embed sql/reviews_update_status.sql
+var queryReviewsUpdateStatus string
+
+//go:embed sql/reviews_set_telegram_message_id.sql
+var queryReviewsSetTelegramMessageID string
+
+const (
+	minReviewLength = 50
+	maxReviewLength = 5000
+)
+
+func CreateReview(ctx context.Context, profileID, secretToken string, input models.CreateReviewInput) (*models.Review, error) {
+	length := utf8.RuneCountInString(input.Content)
+	if length < minReviewLength || length > maxReviewLength {
+		return nil, fmt.Errorf("invalid content length")
+	}
+
+	if !checkCommentRateLimit(profileID) {
+		return nil, fmt.Errorf("rate limit exceeded")
+	}
+
+	if !verifyCommentsTurnstile(input.TurnstileToken) {
+		return nil, fmt.Errorf("captcha verification failed")
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !novelExists(dbCtx, input.NovelID) {
+		return nil, fmt.Errorf("novel not found")
+	}
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return nil, fmt.Errorf("invalid secret token")
+	}
+
+	contentHTML := renderMarkdown(input.Content)
+
+	var review models.Review
+	var previousMessageID *int64
+	err := database.DB.QueryRow(dbCtx, queryReviewsUpsert,
+		input.NovelID, profileID, contentHTML,
+	).Scan(&review.ID, &review.NovelID, &review.UserID, &review.ContentHTML, &review.Status,
+		&review.CreatedAt, &review.UpdatedAt, &previousMessageID)
+	if err != nil {
+		logger.Error("Failed to save review: %v", err)
+		return nil, err
+	}
+
+	database.DB.QueryRow(dbCtx,
+		`SELECT display_name, avatar_seed, has_custom_avatar FROM users WHERE id = $1`,
+		profileID,
+	).Scan(&review.UserDisplayName, &review.UserAvatarSeed, &review.UserHasCustomAvatar)
+	database.DB.QueryRow(dbCtx,
+		`SELECT score FROM novel_ratings WHERE user_id = $1 AND novel_id = $2`,
+		profileID, input.NovelID,
+	).Scan(&review.Score)
+
+	if previousMessageID != nil {
+		go deleteModerationMessage(*previousMessageID)
+	}
+	go sendReviewToTelegram(context.Background(), &review)
+
+	recordCommentTime(profileID)
+
+	logger.Info("Review saved: %s by user %s", review.ID, profileID)
+	return &review, nil
+}
+
+func DeleteReview(ctx context.Context, profileID, secretToken, novelID string) error {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if !verifySecretToken(dbCtx, profileID, secretToken) {
+		return fmt.Errorf("invalid secret token")
+	}
+
+	var messageID *int64
+	err := database.DB.QueryRow(dbCtx,
+		`DELETE FROM novel_reviews WHERE user_id = $1 AND novel_id = $2 RETURNING telegram_message_id`,
+		profileID, novelID,
+	).Scan(&messageID)
+	if err != nil {
+		return fmt.Errorf("review not found")
+	}
+
+	if messageID != nil {
+		go deleteModerationMessage(*messageID)
+	}
+
+	return nil
+}
+
+func GetApprovedReviews(ctx context.Context, novelID string, page int) (*models.ReviewsPage, error) {
+	pageSize := 10
+	offset := (page - 1) * pageSize
+
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var totalCount int
+	if err := database.DB.QueryRow(dbCtx, queryReviewsCountApproved, novelID).Scan(&totalCount); err != nil {
+		logger.Error("Failed to count reviews: %v", err)
+		return nil, err
+	}
+
+	if totalCount == 0 {
+		return &models.ReviewsPage{
+			Reviews:    []models.Review{},
+			Page:       page,
+			PageSize:   pageSize,
+			TotalCount: 0,
+			TotalPages: 0,
+		}, nil
+	}
+
+	rows, err := database.DB.Query(dbCtx, queryReviewsGetApproved, novelID, pageSize, offset)
+	if err != nil {
+		logger.Error("Failed to get reviews: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviews := make([]models.Review, 0)
+	for rows.Next() {
+		var r models.Review
+		if err := rows.Scan(&r.ID, &r.NovelID, &r.UserID, &r.ContentHTML, &r.Status, &r.Score,
+			&r.CreatedAt, &r.UpdatedAt, &r.UserDisplayName, &r.UserAvatarSeed, &r.UserHasCustomAvatar); err != nil {
+			logger.Warn("Review row scan error: %v", err)
+			continue
+		}
+		reviews = append(reviews, r)
+	}
+
+	totalPages := (totalCount + pageSize - 1) / pageSize
+	return &models.ReviewsPage{
+		Reviews:    reviews,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalCount: totalCount,
+		TotalPages: totalPages,
+	}, nil
+}
+
+func UpdateReviewStatus(ctx context.Context, reviewID, status string) error {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var novelID string
+	err := database.DB.QueryRow(dbCtx, queryReviewsUpdateStatus, status, reviewID).Scan(&novelID)
+	if err != nil {
+		logger.Error("Failed to update review status: %v", err)
+		return err
+	}
+
+	logger.Info("Review %s status updated to %s", reviewID, status)
+	return nil
+}
+
+func sendReviewToTelegram(ctx context.Context, review *models.Review) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if telegramBotToken == "" || telegramChatID == "" {
+		logger.Warn("Telegram credentials not set, skipping notification")
+		return
+	}
+
+	novelTitle := review.NovelID
+	if novel, err := GetNovel(ctx, review.NovelID); err == nil {
+		novelTitle = novel.Title
+	}
+
+	score := "—"
+	if review.Score != nil {
+		score = fmt.Sprintf("%d/10", *review.Score)
+	}
+
+	text := fmt.Sprintf(
+		"📚 <b>Новый отзыв</b>\n\n"+
+			"👤 Автор: %s\n"+
+			"📖 Новелла: %s (<code>%s</code>)\n"+
+			"⭐ Оценка: %s\n\n"+
+			"📝 Текст:\n%s",
+		review.UserDisplayName,
+		novelTitle,
+		review.NovelID,
+		score,
+		htmlToTelegramHTML(review.ContentHTML),
+	)
+
+	if len(text) > 4000 {
+		text = text[:4000] + "..."
+	}
+
+	messageID, err := sendModerationMessage(ctx, text,
+		fmt.Sprintf("review_approve:%s", review.ID),
+		fmt.Sprintf("review_reject:%s", review.ID),
+	)
+	if err != nil {
+		logger.Error("Failed to send review to telegram: %v", err)
+		return
+	}
+
+	dbCtx, dbCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer dbCancel()
+	database.DB.Exec(dbCtx, queryReviewsSetTelegramMessageID, messageID, review.ID)
+}
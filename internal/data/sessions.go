@@ -0,0 +1,241 @@
+package data
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ch1kulya/kappalib/internal/database"
+	"github.com/ch1kulya/kappalib/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/ch1kulya/logger"
+)
+
+//go:embed sql/sessions_get_list.sql
+var querySessionsGetList string
+
+const sessionTouchInterval = 5 * time.Minute
+
+var tokenHashKey []byte
+
+func InitSessions() error {
+	key := os.Getenv("TOKEN_HASH_KEY")
+	if key == "" {
+		return fmt.Errorf("TOKEN_HASH_KEY not set")
+	}
+	tokenHashKey = []byte(key)
+	return nil
+}
+
+func hashToken(token string) string {
+	mac := hmac.New(sha256.New, tokenHashKey)
+	mac.Write([]byte(token))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+var (
+	uaBrowsers = []struct{ marker, name string }{
+		{"YaBrowser/", "Яндекс Браузер"},
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+	}
+	uaPlatforms = []struct{ marker, name string }{
+		{"Android", "Android"},
+		{"iPhone", "iPhone"},
+		{"iPad", "iPad"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"CrOS", "ChromeOS"},
+		{"Linux", "Linux"},
+	}
+)
+
+func deviceLabel(userAgent string) string {
+	var parts []string
+	for _, b := range uaBrowsers {
+		if strings.Contains(userAgent, b.marker) {
+			parts = append(parts, b.name)
+			break
+		}
+	}
+	for _, p := range uaPlatforms {
+		if strings.Contains(userAgent, p.marker) {
+			parts = append(parts, p.name)
+			break
+		}
+	}
+	if len(parts) == 0 {
+		return "Неизвестное устройство"
+	}
+	return strings.Join(parts, " · ")
+}
+
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+func createSession(ctx context.Context, q execer, userID, label string) (string, error) {
+	token := generateSecretToken()
+	_, err := q.Exec(ctx,
+		`INSERT INTO sessions (user_id, token_hash, device_label) VALUES ($1, $2, $3)`,
+		userID, hashToken(token), label)
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func lookupSession(ctx context.Context, profileID, token string) (string, bool) {
+	if profileID == "" || token == "" {
+		return "", false
+	}
+
+	tokenHash := hashToken(token)
+	var sessionID string
+	var lastSeenAt time.Time
+	legacy := false
+	err := database.DB.QueryRow(ctx,
+		`SELECT id, last_seen_at FROM sessions WHERE user_id = $1 AND token_hash = $2`,
+		profileID, tokenHash).Scan(&sessionID, &lastSeenAt)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			logger.Warn("Session lookup failed for %s: %v", profileID, err)
+			return "", false
+		}
+		sessionID, lastSeenAt, err = lookupLegacySession(ctx, profileID, token)
+		if err != nil {
+			logger.Warn("Legacy session lookup failed for %s: %v", profileID, err)
+			return "", false
+		}
+		legacy = true
+	}
+
+	if sessionID == "" {
+		return "", false
+	}
+
+	if legacy {
+		_, err := database.DB.Exec(ctx,
+			`UPDATE sessions SET token_hash = $1, token = NULL, last_seen_at = now() WHERE id = $2`,
+			tokenHash, sessionID)
+		if err != nil {
+			logger.Warn("Failed to hash legacy token for session %s: %v", sessionID, err)
+		}
+	} else if time.Since(lastSeenAt) > sessionTouchInterval {
+		database.DB.Exec(ctx, `UPDATE sessions SET last_seen_at = now() WHERE id = $1`, sessionID)
+	}
+
+	return sessionID, true
+}
+
+func lookupLegacySession(ctx context.Context, profileID, token string) (string, time.Time, error) {
+	rows, err := database.DB.Query(ctx,
+		`SELECT id, token, last_seen_at FROM sessions WHERE user_id = $1 AND token_hash IS NULL AND token IS NOT NULL`,
+		profileID)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	defer rows.Close()
+
+	var sessionID string
+	var lastSeenAt time.Time
+	for rows.Next() {
+		var id, storedToken string
+		var seen time.Time
+		if err := rows.Scan(&id, &storedToken, &seen); err != nil {
+			return "", time.Time{}, err
+		}
+		if subtle.ConstantTimeCompare([]byte(storedToken), []byte(token)) == 1 {
+			sessionID, lastSeenAt = id, seen
+		}
+	}
+	return sessionID, lastSeenAt, rows.Err()
+}
+
+func GetSessions(ctx context.Context, profileID, secretToken string) ([]models.Session, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	currentID, ok := lookupSession(dbCtx, profileID, secretToken)
+	if !ok {
+		return nil, fmt.Errorf("invalid secret token")
+	}
+
+	rows, err := database.DB.Query(dbCtx, querySessionsGetList, profileID)
+	if err != nil {
+		logger.Error("Failed to get sessions: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := make([]models.Session, 0)
+	for rows.Next() {
+		var s models.Session
+		if err := rows.Scan(&s.ID, &s.DeviceLabel, &s.CreatedAt, &s.LastSeenAt); err != nil {
+			logger.Warn("Session row scan error: %v", err)
+			continue
+		}
+		s.Current = s.ID == currentID
+		sessions = append(sessions, s)
+	}
+
+	return sessions, nil
+}
+
+func RevokeSession(ctx context.Context, profileID, secretToken, sessionID string) error {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if _, ok := lookupSession(dbCtx, profileID, secretToken); !ok {
+		return fmt.Errorf("invalid secret token")
+	}
+
+	result, err := database.DB.Exec(dbCtx,
+		`DELETE FROM sessions WHERE id = $1 AND user_id = $2`,
+		sessionID, profileID)
+	if err != nil {
+		logger.Error("Failed to revoke session: %v", err)
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("session not found")
+	}
+
+	logger.Info("Session %s revoked for %s", sessionID, profileID)
+	return nil
+}
+
+func RevokeOtherSessions(ctx context.Context, profileID, secretToken string) (int64, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	currentID, ok := lookupSession(dbCtx, profileID, secretToken)
+	if !ok {
+		return 0, fmt.Errorf("invalid secret token")
+	}
+
+	result, err := database.DB.Exec(dbCtx,
+		`DELETE FROM sessions WHERE user_id = $1 AND id <> $2`,
+		profileID, currentID)
+	if err != nil {
+		logger.Error("Failed to revoke sessions: %v", err)
+		return 0, err
+	}
+
+	logger.Info("Revoked %d other sessions for %s", result.RowsAffected(), profileID)
+	return result.RowsAffected(), nil
+}
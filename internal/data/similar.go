@@ -0,0 +1,153 @@
+package data
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"time"
+
+	"github.com/ch1kulya/kappalib/internal/cache"
+	"github.com/ch1kulya/kappalib/internal/database"
+	"github.com/ch1kulya/kappalib/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/ch1kulya/logger"
+)
+
+//go:embed sql/similar_readers.sql
+var querySimilarReaders string
+
+//go:embed sql/similar_refresh.sql
+var querySimilarRefresh string
+
+//go:embed sql/novels_similar.sql
+var queryNovelsSimilar string
+
+const (
+	similarRefreshInterval = 6 * time.Hour
+	similarPerNovel        = 12
+	similarCandidates      = 50
+	similarBatchSize       = 100
+)
+
+func RefreshSimilarNovels(ctx context.Context) error {
+	conn, err := database.DB.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	prepCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	if _, err := conn.Exec(prepCtx, querySimilarReaders); err != nil {
+		return err
+	}
+	defer conn.Exec(context.Background(), `DROP TABLE IF EXISTS similar_readers, similar_reader_counts`)
+
+	rows, err := conn.Query(prepCtx, `SELECT id FROM novels ORDER BY id`)
+	if err != nil {
+		return err
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return err
+	}
+
+	var pairs int64
+	for start := 0; start < len(ids); start += similarBatchSize {
+		batch := ids[start:min(start+similarBatchSize, len(ids))]
+		inserted, err := refreshSimilarBatch(ctx, conn, batch)
+		if err != nil {
+			return err
+		}
+		pairs += inserted
+	}
+
+	logger.Info("Similar novels refreshed: %d pairs for %d novels", pairs, len(ids))
+	return nil
+}
+
+func refreshSimilarBatch(ctx context.Context, conn *pgxpool.Conn, ids []string) (int64, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	tx, err := conn.Begin(dbCtx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(dbCtx)
+
+	if _, err := tx.Exec(dbCtx, `DELETE FROM novel_similar WHERE novel_id = ANY($1::varchar[])`, ids); err != nil {
+		return 0, err
+	}
+
+	result, err := tx.Exec(dbCtx, querySimilarRefresh, ids, similarPerNovel, similarCandidates)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(dbCtx); err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+func StartSimilarRefresher(ctx context.Context) {
+	if err := RefreshSimilarNovels(ctx); err != nil {
+		logger.Error("Failed to refresh similar novels: %v", err)
+	}
+
+	ticker := time.NewTicker(similarRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := RefreshSimilarNovels(ctx); err != nil {
+				logger.Error("Failed to refresh similar novels: %v", err)
+			}
+		}
+	}
+}
+
+func GetSimilarNovels(ctx context.Context, novelID string, limit int) (*models.SimilarNovels, error) {
+	key := fmt.Sprintf("similar:%s:%d", novelID, limit)
+
+	value, err := cache.C.GetOrFetch(key, 30*time.Minute, func() (any, error) {
+		dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		rows, err := database.DB.Query(dbCtx, queryNovelsSimilar, novelID, limit)
+		if err != nil {
+			logger.Error("GetSimilarNovels: Failed to fetch similar novels for %s: %v", novelID, err)
+			return nil, err
+		}
+		defer rows.Close()
+
+		novels := make([]models.Novel, 0, limit)
+		for rows.Next() {
+			var n models.Novel
+			if err := rows.Scan(&n.ID, &n.Title, &n.TitleEn, &n.Author,
+				&n.YearStart, &n.YearEnd, &n.Status, &n.Description,
+				&n.AgeRating, &n.CoverURL, &n.ViewsCount, &n.CreatedAt); err != nil {
+				logger.Warn("GetSimilarNovels: Row scan error: %v", err)
+				continue
+			}
+			novels = append(novels, n)
+		}
+
+		return &models.SimilarNovels{
+			NovelID: novelID,
+			Novels:  novels,
+		}, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	return value.(*models.SimilarNovels), nil
+}
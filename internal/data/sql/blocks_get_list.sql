@@ -0,0 +1,5 @@
+SELECT b.blocked_user_id, u.display_name, u.avatar_seed, u.has_custom_avatar, b.kind, b.created_at
+FROM user_blocks b
+JOIN users u ON u.id = b.blocked_user_id
+WHERE b.user_id = $1
+ORDER BY b.created_at DESC;
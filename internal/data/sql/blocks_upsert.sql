@@ -0,0 +1,6 @@
+INSERT INTO user_blocks (user_id, blocked_user_id, kind)
+SELECT $1, u.id, $3
+FROM users u
+WHERE u.id = $2
+ON CONFLICT (user_id, blocked_user_id) DO UPDATE SET kind = EXCLUDED.kind
+RETURNING blocked_user_id;
@@ -5,6 +5,7 @@ SELECT
     c.title,
     c.title_en,
     c.content,
+    c.views_count,
     c.created_at,
     s.name,
     s.logo_url
@@ -0,0 +1,4 @@
+SELECT r.comment_id, r.value
+FROM comment_reactions r
+JOIN comments c ON c.id = r.comment_id
+WHERE r.user_id = $1 AND c.chapter_id = $2;
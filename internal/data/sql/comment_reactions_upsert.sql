@@ -0,0 +1,10 @@
+WITH target AS (
+    SELECT id FROM comments
+    WHERE id = $2 AND status = 'approved' AND user_id <> $1
+), saved AS (
+    INSERT INTO comment_reactions (user_id, comment_id, value)
+    SELECT $1, id, $3 FROM target
+    ON CONFLICT (user_id, comment_id) DO UPDATE SET value = EXCLUDED.value
+    RETURNING comment_id
+)
+SELECT comment_id FROM saved;
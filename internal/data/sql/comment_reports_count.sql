@@ -0,0 +1,5 @@
+SELECT COUNT(*)
+FROM comment_reports r
+JOIN comments c ON c.id = r.comment_id
+WHERE r.comment_id = $1
+  AND (c.reports_kept_at IS NULL OR r.created_at > c.reports_kept_at);
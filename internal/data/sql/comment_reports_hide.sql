@@ -0,0 +1,8 @@
+UPDATE comments c SET status = 'hidden'
+WHERE c.id = $1 AND c.status = 'approved'
+  AND (
+      SELECT COUNT(*) FROM comment_reports r
+      WHERE r.comment_id = c.id
+        AND (c.reports_kept_at IS NULL OR r.created_at > c.reports_kept_at)
+  ) >= $2
+RETURNING c.id;
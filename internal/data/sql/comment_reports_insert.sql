@@ -0,0 +1,8 @@
+WITH target AS (
+    SELECT id FROM comments
+    WHERE id = $2 AND status = 'approved' AND deleted_at IS NULL AND user_id <> $1
+)
+INSERT INTO comment_reports (user_id, comment_id, reason)
+SELECT $1, id, $3 FROM target
+ON CONFLICT (user_id, comment_id) DO NOTHING
+RETURNING comment_id;
@@ -0,0 +1,7 @@
+SELECT r.reason, COUNT(*) AS cnt
+FROM comment_reports r
+JOIN comments c ON c.id = r.comment_id
+WHERE r.comment_id = $1
+  AND (c.reports_kept_at IS NULL OR r.created_at > c.reports_kept_at)
+GROUP BY r.reason
+ORDER BY cnt DESC, r.reason;
@@ -0,0 +1,13 @@
+WITH prev AS (
+    SELECT id, status, telegram_message_id
+    FROM comments
+    WHERE id = $2 AND status IN ('approved', 'hidden', 'pending')
+    FOR UPDATE
+)
+UPDATE comments c SET
+    status = CASE WHEN $1 = 'approved' AND prev.status = 'pending' THEN 'pending' ELSE $1 END,
+    telegram_message_id = CASE WHEN $1 = 'approved' AND prev.status = 'pending' THEN prev.telegram_message_id END,
+    reports_kept_at = CASE WHEN $1 = 'approved' THEN now() ELSE c.reports_kept_at END
+FROM prev
+WHERE c.id = prev.id
+RETURNING CASE WHEN prev.status = 'pending' AND $1 <> 'approved' THEN prev.telegram_message_id END;
@@ -1 +1,14 @@
-SELECT COUNT(*) FROM comments WHERE chapter_id = $1 AND status = 'approved';
+WITH RECURSIVE visible AS (
+    SELECT c.id, c.parent_id
+    FROM comments c
+    WHERE c.chapter_id = $1 AND c.parent_id IS NULL AND c.status = 'approved'
+      AND NOT EXISTS (SELECT 1 FROM user_blocks b WHERE b.user_id = $2 AND b.blocked_user_id = c.user_id)
+    UNION ALL
+    SELECT c.id, c.parent_id
+    FROM comments c
+    JOIN visible v ON c.parent_id = v.id
+    WHERE c.status = 'approved'
+      AND NOT EXISTS (SELECT 1 FROM user_blocks b WHERE b.user_id = $2 AND b.blocked_user_id = c.user_id)
+)
+SELECT COUNT(*) FILTER (WHERE parent_id IS NULL), COUNT(*)
+FROM visible;
@@ -1,3 +1,3 @@
-INSERT INTO comments (chapter_id, user_id, content_html, status)
-VALUES ($1, $2, $3, 'pending')
-RETURNING id, chapter_id, user_id, content_html, status, created_at;
+INSERT INTO comments (chapter_id, user_id, content_html, status, parent_id, depth, reply_to_user_id)
+VALUES ($1, $2, $3, 'pending', $4, $5, $6)
+RETURNING id, chapter_id, user_id, parent_id, depth, content_html, status, created_at;
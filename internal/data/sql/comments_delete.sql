@@ -0,0 +1,20 @@
+WITH target AS (
+    SELECT c.id, c.telegram_message_id,
+           EXISTS(SELECT 1 FROM comments r WHERE r.parent_id = c.id) AS has_replies
+    FROM comments c
+    WHERE c.id = $1 AND c.user_id = $2 AND c.deleted_at IS NULL
+), soft AS (
+    UPDATE comments c SET
+        content_html = '',
+        deleted_at = now(),
+        telegram_message_id = NULL
+    FROM target t
+    WHERE c.id = t.id AND t.has_replies
+    RETURNING c.id
+), hard AS (
+    DELETE FROM comments c
+    USING target t
+    WHERE c.id = t.id AND NOT t.has_replies
+    RETURNING c.id
+)
+SELECT telegram_message_id, has_replies FROM target;
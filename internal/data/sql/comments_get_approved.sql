@@ -1,8 +1,19 @@
 SELECT
-    c.id, c.chapter_id, c.user_id, c.content_html, c.status, c.created_at,
+    c.id, c.chapter_id, c.user_id, c.parent_id, c.depth,
+    c.replies_count - (
+        SELECT COUNT(*) FROM comments r
+        JOIN user_blocks b ON b.user_id = $5 AND b.blocked_user_id = r.user_id
+        WHERE r.parent_id = c.id AND r.status = 'approved'
+    ),
+    c.likes_count, c.dislikes_count,
+    c.content_html, c.status, c.created_at, c.edited_at, c.deleted_at IS NOT NULL,
     u.display_name, u.avatar_seed, u.has_custom_avatar
 FROM comments c
 JOIN users u ON c.user_id = u.id
-WHERE c.chapter_id = $1 AND c.status = 'approved'
-ORDER BY c.created_at DESC
+WHERE c.chapter_id = $1 AND c.parent_id IS NULL AND c.status = 'approved'
+  AND NOT EXISTS (SELECT 1 FROM user_blocks b WHERE b.user_id = $5 AND b.blocked_user_id = c.user_id)
+ORDER BY
+    CASE WHEN $4 = 'top' THEN c.likes_count - c.dislikes_count END DESC,
+    CASE WHEN $4 = 'oldest' THEN c.created_at END ASC,
+    c.created_at DESC
 LIMIT $2 OFFSET $3;
@@ -1,2 +1,3 @@
-SELECT id, chapter_id, user_id, content_html, status, telegram_message_id, created_at
+SELECT id, chapter_id, user_id, parent_id, depth, content_html, status, telegram_message_id,
+       created_at, edited_at, deleted_at IS NOT NULL
 FROM comments WHERE id = $1;
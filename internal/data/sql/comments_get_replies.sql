@@ -0,0 +1,35 @@
+WITH RECURSIVE thread AS (
+    SELECT c.id, c.parent_id AS root_id
+    FROM comments c
+    WHERE c.parent_id = ANY($1::varchar[]) AND c.status = 'approved'
+      AND NOT EXISTS (SELECT 1 FROM user_blocks b WHERE b.user_id = $3 AND b.blocked_user_id = c.user_id)
+    UNION ALL
+    SELECT c.id, t.root_id
+    FROM comments c
+    JOIN thread t ON c.parent_id = t.id
+    WHERE c.status = 'approved'
+      AND NOT EXISTS (SELECT 1 FROM user_blocks b WHERE b.user_id = $3 AND b.blocked_user_id = c.user_id)
+), ranked AS (
+    SELECT
+        t.id, t.root_id,
+        ROW_NUMBER() OVER (PARTITION BY t.root_id ORDER BY c.created_at, c.id) AS rn,
+        COUNT(*) OVER (PARTITION BY t.root_id) AS thread_size
+    FROM thread t
+    JOIN comments c ON c.id = t.id
+)
+SELECT
+    c.id, c.chapter_id, c.user_id, c.parent_id, c.depth,
+    c.replies_count - (
+        SELECT COUNT(*) FROM comments r
+        JOIN user_blocks b ON b.user_id = $3 AND b.blocked_user_id = r.user_id
+        WHERE r.parent_id = c.id AND r.status = 'approved'
+    ),
+    c.likes_count, c.dislikes_count,
+    c.content_html, c.status, c.created_at, c.edited_at, c.deleted_at IS NOT NULL,
+    u.display_name, u.avatar_seed, u.has_custom_avatar,
+    r.root_id, r.thread_size
+FROM ranked r
+JOIN comments c ON c.id = r.id
+JOIN users u ON c.user_id = u.id
+WHERE r.rn <= $2
+ORDER BY c.created_at ASC;
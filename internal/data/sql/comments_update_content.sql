@@ -0,0 +1,18 @@
+WITH current AS (
+    SELECT id, content_html, telegram_message_id
+    FROM comments
+    WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+    FOR UPDATE
+), history AS (
+    INSERT INTO comment_edits (comment_id, content_html)
+    SELECT id, content_html FROM current
+)
+UPDATE comments c SET
+    content_html = $3,
+    status = 'pending',
+    edited_at = now(),
+    telegram_message_id = NULL
+FROM current cur
+WHERE c.id = cur.id
+RETURNING c.id, c.chapter_id, c.user_id, c.parent_id, c.depth, c.content_html, c.status,
+    c.created_at, c.edited_at, cur.telegram_message_id;
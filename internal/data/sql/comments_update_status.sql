@@ -1 +1 @@
-UPDATE comments SET status = $1 WHERE id = $2 RETURNING id;
+UPDATE comments SET status = $1, telegram_message_id = NULL WHERE id = $2 RETURNING id;
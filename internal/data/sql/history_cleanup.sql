@@ -0,0 +1 @@
+DELETE FROM reading_history WHERE read_at < now() - make_interval(days => $1);
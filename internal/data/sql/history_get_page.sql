@@ -0,0 +1,7 @@
+SELECT h.id, h.novel_id, n.title, n.cover_url, h.chapter_id, c.chapter_num, c.title, h.read_at
+FROM reading_history h
+JOIN novels n ON n.id = h.novel_id
+JOIN chapters c ON c.id = h.chapter_id
+WHERE h.user_id = $1
+ORDER BY h.read_at DESC
+LIMIT $2 OFFSET $3;
@@ -0,0 +1,20 @@
+WITH recent AS (
+    UPDATE reading_history
+    SET read_at = now()
+    WHERE id = (
+        SELECT id FROM reading_history
+        WHERE user_id = $1 AND chapter_id = $2 AND read_at > now() - make_interval(mins => $3)
+        ORDER BY read_at DESC
+        LIMIT 1
+    )
+    RETURNING id
+), inserted AS (
+    INSERT INTO reading_history (user_id, novel_id, chapter_id)
+    SELECT $1, c.novel_id, c.id
+    FROM chapters c
+    WHERE c.id = $2 AND NOT EXISTS (SELECT 1 FROM recent)
+    RETURNING id
+)
+SELECT id FROM recent
+UNION ALL
+SELECT id FROM inserted;
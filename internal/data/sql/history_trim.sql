@@ -0,0 +1,7 @@
+DELETE FROM reading_history
+WHERE user_id = $1 AND id NOT IN (
+    SELECT id FROM reading_history
+    WHERE user_id = $1
+    ORDER BY read_at DESC
+    LIMIT $2
+);
@@ -0,0 +1,3 @@
+SELECT shelf, COUNT(*) FROM library_entries
+WHERE user_id = $1
+GROUP BY shelf;
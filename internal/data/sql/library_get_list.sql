@@ -0,0 +1,8 @@
+SELECT
+    l.novel_id, l.shelf, r.score, l.created_at, l.updated_at,
+    n.title, n.title_en, n.author, n.cover_url, n.chapters_count
+FROM library_entries l
+JOIN novels n ON n.id = l.novel_id
+LEFT JOIN novel_ratings r ON r.user_id = l.user_id AND r.novel_id = l.novel_id
+WHERE l.user_id = $1 AND ($2 = '' OR l.shelf = $2)
+ORDER BY l.updated_at DESC;
@@ -0,0 +1,4 @@
+SELECT l.novel_id, l.shelf, r.score, l.created_at, l.updated_at
+FROM library_entries l
+LEFT JOIN novel_ratings r ON r.user_id = l.user_id AND r.novel_id = l.novel_id
+WHERE l.user_id = $1 AND l.novel_id = $2;
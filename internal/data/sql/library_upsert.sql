@@ -0,0 +1,24 @@
+WITH entry AS (
+    INSERT INTO library_entries (user_id, novel_id, shelf)
+    VALUES ($1, $2, $3)
+    ON CONFLICT (user_id, novel_id) DO UPDATE SET
+        shelf = EXCLUDED.shelf,
+        updated_at = now()
+    RETURNING novel_id, shelf, created_at, updated_at
+), rated AS (
+    INSERT INTO novel_ratings (user_id, novel_id, score)
+    SELECT $1, $2, $4::smallint
+    WHERE $4::smallint IS NOT NULL
+    ON CONFLICT (user_id, novel_id) DO UPDATE SET
+        score = EXCLUDED.score,
+        updated_at = now()
+    RETURNING score
+)
+SELECT
+    e.novel_id, e.shelf,
+    COALESCE(
+        (SELECT score FROM rated),
+        (SELECT score FROM novel_ratings WHERE user_id = $1 AND novel_id = $2)
+    ),
+    e.created_at, e.updated_at
+FROM entry e;
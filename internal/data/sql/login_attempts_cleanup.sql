@@ -0,0 +1,6 @@
+WITH attempts AS (
+    DELETE FROM login_attempts
+    WHERE created_at < now() - make_interval(days => $1)
+)
+DELETE FROM login_requests
+WHERE expires_at < now() - interval '1 day';
@@ -0,0 +1,14 @@
+UPDATE users u SET
+    sync_code = NULL,
+    sync_code_expires_at = NULL,
+    sync_code_confirm = false,
+    last_active_at = now()
+FROM (
+    SELECT id, sync_code_confirm
+    FROM users
+    WHERE sync_code = $1 AND sync_code_expires_at > now()
+    FOR UPDATE
+) claimed
+WHERE u.id = claimed.id
+RETURNING u.id, u.display_name, u.avatar_seed, u.has_custom_avatar, u.is_public, u.created_at, u.cookies,
+    claimed.sync_code_confirm;
@@ -0,0 +1,4 @@
+SELECT id, device_label, ip, created_at, expires_at
+FROM login_requests
+WHERE user_id = $1 AND status = 'pending' AND expires_at > now()
+ORDER BY created_at DESC;
@@ -0,0 +1,3 @@
+DELETE FROM notifications
+WHERE (is_read AND updated_at < now() - make_interval(days => $1))
+   OR updated_at < now() - make_interval(days => $2);
@@ -0,0 +1,11 @@
+SELECT nt.id, nt.type, nt.novel_id, n.title, n.cover_url, nt.chapter_id,
+       nt.first_chapter_num, nt.last_chapter_num, nt.chapters_count,
+       nt.comment_id, u.display_name,
+       nt.is_read, nt.created_at, nt.updated_at
+FROM notifications nt
+JOIN novels n ON n.id = nt.novel_id
+LEFT JOIN comments c ON c.id = nt.comment_id
+LEFT JOIN users u ON u.id = c.user_id
+WHERE nt.user_id = $1
+ORDER BY nt.updated_at DESC
+LIMIT $2 OFFSET $3;
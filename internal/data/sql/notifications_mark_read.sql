@@ -0,0 +1,3 @@
+UPDATE notifications
+SET is_read = TRUE
+WHERE user_id = $1 AND NOT is_read AND (cardinality($2::bigint[]) = 0 OR id = ANY($2));
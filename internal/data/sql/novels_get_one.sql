@@ -1,3 +1,3 @@
 SELECT id, title, title_en, author, year_start, year_end, status,
-       description, age_rating, cover_url, created_at
+       description, age_rating, cover_url, views_count, created_at
 FROM novels WHERE id = $1;
@@ -3,7 +3,7 @@ WITH norm_query AS (
 )
 SELECT
     n.id, n.title, n.title_en, n.author, n.year_start, n.year_end, n.status,
-    n.description, n.age_rating, n.cover_url, n.created_at,
+    n.description, n.age_rating, n.cover_url, n.views_count, n.created_at,
     (
         (word_similarity(nq.q, n.title_norm) * 2.5) +
         (word_similarity(nq.q, n.title_en_norm) * 2.0) +
@@ -0,0 +1,7 @@
+SELECT n.id, n.title, n.title_en, n.author, n.year_start, n.year_end, n.status,
+       n.description, n.age_rating, n.cover_url, n.views_count, n.created_at
+FROM novel_similar s
+JOIN novels n ON n.id = s.similar_id
+WHERE s.novel_id = $1
+ORDER BY s.score DESC
+LIMIT $2;
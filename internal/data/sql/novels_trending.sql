@@ -0,0 +1,7 @@
+SELECT n.id, n.title, n.title_en, n.author, n.year_start, n.year_end, n.status,
+       n.description, n.age_rating, n.cover_url, n.views_count, n.created_at
+FROM novel_rankings r
+JOIN novels n ON n.id = r.novel_id
+WHERE r.trending_score > 0
+ORDER BY r.trending_rank ASC
+LIMIT $1;
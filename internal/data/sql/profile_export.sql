@@ -0,0 +1,75 @@
+SELECT
+    u.display_name,
+    u.avatar_seed,
+    COALESCE(u.has_custom_avatar, false),
+    u.is_public,
+    u.created_at,
+    u.cookies,
+    (
+        SELECT COALESCE(jsonb_agg(jsonb_build_object(
+            'novel_id', novel_id,
+            'shelf', shelf,
+            'created_at', created_at,
+            'updated_at', updated_at
+        ) ORDER BY updated_at DESC), '[]')
+        FROM library_entries WHERE user_id = u.id
+    ),
+    (
+        SELECT COALESCE(jsonb_agg(jsonb_build_object(
+            'novel_id', novel_id,
+            'chapter_id', chapter_id,
+            'paragraph', paragraph,
+            'scroll_percent', scroll_percent,
+            'updated_at', updated_at
+        ) ORDER BY updated_at DESC), '[]')
+        FROM reading_progress WHERE user_id = u.id
+    ),
+    (
+        SELECT COALESCE(jsonb_agg(jsonb_build_object(
+            'novel_id', novel_id,
+            'chapter_id', chapter_id,
+            'read_at', read_at
+        ) ORDER BY read_at DESC), '[]')
+        FROM reading_history WHERE user_id = u.id
+    ),
+    (
+        SELECT COALESCE(jsonb_agg(jsonb_build_object(
+            'novel_id', novel_id,
+            'created_at', created_at
+        ) ORDER BY created_at DESC), '[]')
+        FROM novel_follows WHERE user_id = u.id
+    ),
+    (
+        SELECT COALESCE(jsonb_agg(jsonb_build_object(
+            'novel_id', novel_id,
+            'score', score,
+            'created_at', created_at,
+            'updated_at', updated_at
+        ) ORDER BY updated_at DESC), '[]')
+        FROM novel_ratings WHERE user_id = u.id
+    ),
+    (
+        SELECT COALESCE(jsonb_agg(jsonb_build_object(
+            'id', id,
+            'novel_id', novel_id,
+            'content_html', content_html,
+            'status', status,
+            'created_at', created_at,
+            'updated_at', updated_at
+        ) ORDER BY created_at DESC), '[]')
+        FROM novel_reviews WHERE user_id = u.id
+    ),
+    (
+        SELECT COALESCE(jsonb_agg(jsonb_build_object(
+            'id', id,
+            'chapter_id', chapter_id,
+            'parent_id', parent_id,
+            'content_html', content_html,
+            'status', status,
+            'created_at', created_at,
+            'edited_at', edited_at
+        ) ORDER BY created_at DESC), '[]')
+        FROM comments WHERE user_id = u.id AND deleted_at IS NULL
+    )
+FROM users u
+WHERE u.id = $1;
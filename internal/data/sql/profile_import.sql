@@ -0,0 +1,52 @@
+WITH library AS (
+    INSERT INTO library_entries (user_id, novel_id, shelf, created_at, updated_at)
+    SELECT $1, n.id, e.shelf, COALESCE(e.created_at, now()), COALESCE(e.updated_at, now())
+    FROM jsonb_to_recordset($2::jsonb) AS e(novel_id text, shelf text, created_at timestamptz, updated_at timestamptz)
+    JOIN novels n ON n.id = e.novel_id
+    WHERE e.shelf IN ('reading', 'planned', 'completed', 'dropped')
+    ON CONFLICT DO NOTHING
+    RETURNING 1
+), progress AS (
+    INSERT INTO reading_progress (user_id, novel_id, chapter_id, paragraph, scroll_percent, updated_at)
+    SELECT $1, c.novel_id, c.id,
+        GREATEST(COALESCE(e.paragraph, 0), 0),
+        LEAST(GREATEST(COALESCE(e.scroll_percent, 0), 0), 100),
+        COALESCE(e.updated_at, now())
+    FROM jsonb_to_recordset($3::jsonb) AS e(novel_id text, chapter_id text, paragraph integer, scroll_percent real, updated_at timestamptz)
+    JOIN chapters c ON c.id = e.chapter_id AND c.novel_id = e.novel_id
+    ON CONFLICT DO NOTHING
+    RETURNING 1
+), history AS (
+    INSERT INTO reading_history (user_id, novel_id, chapter_id, read_at)
+    SELECT $1, c.novel_id, c.id, e.read_at
+    FROM (
+        SELECT *
+        FROM jsonb_to_recordset($4::jsonb) AS r(novel_id text, chapter_id text, read_at timestamptz)
+        WHERE r.read_at > now() - make_interval(days => $8)
+        ORDER BY r.read_at DESC
+        LIMIT $7
+    ) e
+    JOIN chapters c ON c.id = e.chapter_id AND c.novel_id = e.novel_id
+    RETURNING 1
+), follows AS (
+    INSERT INTO novel_follows (user_id, novel_id, created_at)
+    SELECT $1, n.id, COALESCE(e.created_at, now())
+    FROM jsonb_to_recordset($5::jsonb) AS e(novel_id text, created_at timestamptz)
+    JOIN novels n ON n.id = e.novel_id
+    ON CONFLICT DO NOTHING
+    RETURNING 1
+), ratings AS (
+    INSERT INTO novel_ratings (user_id, novel_id, score, created_at, updated_at)
+    SELECT $1, n.id, e.score, COALESCE(e.created_at, now()), COALESCE(e.updated_at, now())
+    FROM jsonb_to_recordset($6::jsonb) AS e(novel_id text, score smallint, created_at timestamptz, updated_at timestamptz)
+    JOIN novels n ON n.id = e.novel_id
+    WHERE e.score BETWEEN 1 AND 10
+    ON CONFLICT DO NOTHING
+    RETURNING 1
+)
+SELECT
+    (SELECT COUNT(*) FROM library),
+    (SELECT COUNT(*) FROM progress),
+    (SELECT COUNT(*) FROM history),
+    (SELECT COUNT(*) FROM follows),
+    (SELECT COUNT(*) FROM ratings);
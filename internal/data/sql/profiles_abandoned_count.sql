@@ -0,0 +1,9 @@
+SELECT COUNT(*), COUNT(*) FILTER (WHERE u.has_custom_avatar OR u.avatar_status = 'pending')
+FROM users u
+WHERE COALESCE(u.last_active_at, u.created_at) < now() - make_interval(days => $1)
+  AND NOT EXISTS (SELECT 1 FROM comments c WHERE c.user_id = u.id)
+  AND NOT EXISTS (SELECT 1 FROM novel_reviews r WHERE r.user_id = u.id)
+  AND NOT EXISTS (
+      SELECT 1 FROM sessions s
+      WHERE s.user_id = u.id AND s.last_seen_at >= now() - make_interval(days => $1)
+  );
@@ -0,0 +1,12 @@
+SELECT u.id, COALESCE(u.has_custom_avatar, false) OR COALESCE(u.avatar_status = 'pending', false)
+FROM users u
+WHERE COALESCE(u.last_active_at, u.created_at) < now() - make_interval(days => $1)
+  AND NOT EXISTS (SELECT 1 FROM comments c WHERE c.user_id = u.id)
+  AND NOT EXISTS (SELECT 1 FROM novel_reviews r WHERE r.user_id = u.id)
+  AND NOT EXISTS (
+      SELECT 1 FROM sessions s
+      WHERE s.user_id = u.id AND s.last_seen_at >= now() - make_interval(days => $1)
+  )
+ORDER BY COALESCE(u.last_active_at, u.created_at)
+LIMIT $2
+FOR UPDATE OF u SKIP LOCKED;
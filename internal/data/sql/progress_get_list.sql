@@ -0,0 +1,7 @@
+SELECT p.novel_id, p.chapter_id, c.chapter_num, p.paragraph, p.scroll_percent, p.updated_at,
+       (SELECT COUNT(*) FROM chapters WHERE novel_id = p.novel_id) AS total_chapters
+FROM reading_progress p
+JOIN chapters c ON c.id = p.chapter_id
+WHERE p.user_id = $1
+ORDER BY p.updated_at DESC
+LIMIT $2;
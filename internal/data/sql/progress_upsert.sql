@@ -0,0 +1,16 @@
+WITH saved AS (
+    INSERT INTO reading_progress (user_id, novel_id, chapter_id, paragraph, scroll_percent)
+    SELECT $1, c.novel_id, c.id, $4, $5
+    FROM chapters c
+    WHERE c.id = $3 AND c.novel_id = $2
+    ON CONFLICT (user_id, novel_id) DO UPDATE SET
+        chapter_id = EXCLUDED.chapter_id,
+        paragraph = EXCLUDED.paragraph,
+        scroll_percent = EXCLUDED.scroll_percent,
+        updated_at = now()
+    RETURNING novel_id, chapter_id, paragraph, scroll_percent, updated_at
+)
+SELECT s.novel_id, s.chapter_id, c.chapter_num, s.paragraph, s.scroll_percent, s.updated_at,
+       (SELECT COUNT(*) FROM chapters WHERE novel_id = s.novel_id) AS total_chapters
+FROM saved s
+JOIN chapters c ON c.id = s.chapter_id;
@@ -0,0 +1,4 @@
+UPDATE notifications nt
+SET pushed_at = v.updated_at
+FROM unnest($1::bigint[], $2::timestamptz[]) AS v(id, updated_at)
+WHERE nt.id = v.id;
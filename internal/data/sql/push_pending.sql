@@ -0,0 +1,13 @@
+SELECT nt.id, nt.user_id, nt.type, nt.novel_id, n.title, nt.chapter_id,
+       nt.first_chapter_num, nt.last_chapter_num,
+       nt.comment_id, u.display_name, nt.updated_at
+FROM notifications nt
+JOIN novels n ON n.id = nt.novel_id
+LEFT JOIN comments c ON c.id = nt.comment_id
+LEFT JOIN users u ON u.id = c.user_id
+WHERE NOT nt.is_read
+  AND (nt.pushed_at IS NULL OR nt.pushed_at < nt.updated_at)
+  AND nt.updated_at > now() - make_interval(hours => $1)
+  AND EXISTS (SELECT 1 FROM push_subscriptions s WHERE s.user_id = nt.user_id)
+ORDER BY nt.updated_at
+LIMIT $2;
@@ -0,0 +1,36 @@
+WITH recent_novel_views AS (
+    SELECT novel_id, SUM(exp(-(CURRENT_DATE - day) / $1::float8)) AS score
+    FROM novel_views
+    WHERE day > CURRENT_DATE - $2::int
+    GROUP BY novel_id
+), recent_chapter_views AS (
+    SELECT c.novel_id, SUM(exp(-(CURRENT_DATE - cv.day) / $1::float8)) AS score
+    FROM chapter_views cv
+    JOIN chapters c ON c.id = cv.chapter_id
+    WHERE cv.day > CURRENT_DATE - $2::int
+    GROUP BY c.novel_id
+), scores AS (
+    SELECT
+        n.id AS novel_id,
+        n.title,
+        COALESCE(rn.score, 0) + 0.5 * COALESCE(rc.score, 0) AS trending_score,
+        n.views_count::float8 AS popular_score
+    FROM novels n
+    LEFT JOIN recent_novel_views rn ON rn.novel_id = n.id
+    LEFT JOIN recent_chapter_views rc ON rc.novel_id = n.id
+), ranked AS (
+    SELECT
+        novel_id, trending_score, popular_score,
+        ROW_NUMBER() OVER (ORDER BY trending_score DESC, popular_score DESC, title ASC) AS trending_rank,
+        ROW_NUMBER() OVER (ORDER BY popular_score DESC, trending_score DESC, title ASC) AS popular_rank
+    FROM scores
+)
+INSERT INTO novel_rankings (novel_id, trending_score, popular_score, trending_rank, popular_rank, updated_at)
+SELECT novel_id, trending_score, popular_score, trending_rank, popular_rank, now()
+FROM ranked
+ON CONFLICT (novel_id) DO UPDATE SET
+    trending_score = EXCLUDED.trending_score,
+    popular_score = EXCLUDED.popular_score,
+    trending_rank = EXCLUDED.trending_rank,
+    popular_rank = EXCLUDED.popular_rank,
+    updated_at = EXCLUDED.updated_at;
@@ -0,0 +1,3 @@
+SELECT score, COUNT(*) FROM novel_ratings
+WHERE novel_id = $1
+GROUP BY score;
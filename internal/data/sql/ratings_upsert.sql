@@ -0,0 +1,6 @@
+INSERT INTO novel_ratings (user_id, novel_id, score)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, novel_id) DO UPDATE SET
+    score = EXCLUDED.score,
+    updated_at = now()
+RETURNING score;
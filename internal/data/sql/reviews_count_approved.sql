@@ -0,0 +1 @@
+SELECT COUNT(*) FROM novel_reviews WHERE novel_id = $1 AND status = 'approved';
@@ -0,0 +1,9 @@
+SELECT
+    rv.id, rv.novel_id, rv.user_id, rv.content_html, rv.status, r.score, rv.created_at, rv.updated_at,
+    u.display_name, u.avatar_seed, u.has_custom_avatar
+FROM novel_reviews rv
+JOIN users u ON rv.user_id = u.id
+LEFT JOIN novel_ratings r ON r.user_id = rv.user_id AND r.novel_id = rv.novel_id
+WHERE rv.novel_id = $1 AND rv.status = 'approved'
+ORDER BY rv.updated_at DESC
+LIMIT $2 OFFSET $3;
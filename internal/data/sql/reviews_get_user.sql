@@ -0,0 +1,4 @@
+SELECT rv.id, rv.novel_id, rv.user_id, rv.content_html, rv.status, r.score, rv.created_at, rv.updated_at
+FROM novel_reviews rv
+LEFT JOIN novel_ratings r ON r.user_id = rv.user_id AND r.novel_id = rv.novel_id
+WHERE rv.user_id = $1 AND rv.novel_id = $2;
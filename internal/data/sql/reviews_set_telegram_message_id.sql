@@ -0,0 +1 @@
+UPDATE novel_reviews SET telegram_message_id = $1 WHERE id = $2;
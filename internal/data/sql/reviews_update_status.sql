@@ -0,0 +1 @@
+UPDATE novel_reviews SET status = $1 WHERE id = $2 RETURNING novel_id;
@@ -0,0 +1,13 @@
+WITH previous AS (
+    SELECT telegram_message_id FROM novel_reviews
+    WHERE user_id = $2 AND novel_id = $1
+)
+INSERT INTO novel_reviews (novel_id, user_id, content_html, status)
+VALUES ($1, $2, $3, 'pending')
+ON CONFLICT (user_id, novel_id) DO UPDATE SET
+    content_html = EXCLUDED.content_html,
+    status = 'pending',
+    telegram_message_id = NULL,
+    updated_at = now()
+RETURNING id, novel_id, user_id, content_html, status, created_at, updated_at,
+    (SELECT telegram_message_id FROM previous);
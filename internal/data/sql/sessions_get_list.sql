@@ -0,0 +1,4 @@
+SELECT id, device_label, created_at, last_seen_at
+FROM sessions
+WHERE user_id = $1
+ORDER BY last_seen_at DESC;
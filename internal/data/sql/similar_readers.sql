@@ -0,0 +1,23 @@
+DROP TABLE IF EXISTS similar_readers, similar_reader_counts;
+
+CREATE TEMP TABLE similar_readers AS
+SELECT u.id AS user_id, substring(k FROM 15) AS novel_id
+FROM users u, jsonb_object_keys(u.cookies) AS k
+WHERE k LIKE 'kappalib\_prog\_%'
+UNION
+SELECT user_id, novel_id
+FROM library_entries
+WHERE shelf <> 'dropped'
+UNION
+SELECT user_id, novel_id
+FROM reading_progress;
+
+CREATE INDEX ON similar_readers (novel_id);
+CREATE INDEX ON similar_readers (user_id);
+
+CREATE TEMP TABLE similar_reader_counts AS
+SELECT novel_id, COUNT(*) AS cnt FROM similar_readers GROUP BY novel_id;
+
+CREATE UNIQUE INDEX ON similar_reader_counts (novel_id);
+ANALYZE similar_readers;
+ANALYZE similar_reader_counts;
@@ -0,0 +1,60 @@
+WITH co_readers AS (
+    SELECT a.novel_id, b.novel_id AS similar_id, COUNT(*) AS shared
+    FROM similar_readers a
+    JOIN similar_readers b ON a.user_id = b.user_id AND a.novel_id <> b.novel_id
+    WHERE a.novel_id = ANY($1::varchar[])
+    GROUP BY a.novel_id, b.novel_id
+), candidates AS (
+    SELECT novel_id, similar_id
+    FROM (
+        SELECT novel_id, similar_id,
+            ROW_NUMBER() OVER (PARTITION BY novel_id ORDER BY shared DESC, similar_id) AS rn
+        FROM co_readers
+    ) top_readers
+    WHERE rn <= $3
+    UNION
+    SELECT n1.id, n2.id
+    FROM novels n1
+    JOIN novels n2 ON n2.id <> n1.id
+        AND NULLIF(lower(btrim(n1.author)), '') = lower(btrim(n2.author))
+    WHERE n1.id = ANY($1::varchar[])
+    UNION
+    SELECT n1.id, nearest.id
+    FROM novels n1
+    CROSS JOIN LATERAL (
+        SELECT n2.id
+        FROM novels n2
+        WHERE n2.id <> n1.id AND n2.description IS NOT NULL
+        ORDER BY n2.description <-> n1.description
+        LIMIT $3
+    ) nearest
+    WHERE n1.id = ANY($1::varchar[]) AND COALESCE(n1.description, '') <> ''
+), pairs AS (
+    SELECT
+        c.novel_id,
+        c.similar_id,
+        COALESCE(co.shared / sqrt(rc1.cnt * rc2.cnt), 0) AS reader_score,
+        similarity(COALESCE(n1.description, ''), COALESCE(n2.description, '')) AS text_score,
+        (CASE WHEN NULLIF(lower(btrim(n1.author)), '') = lower(btrim(n2.author)) THEN 0.6 ELSE 0 END) +
+        (CASE WHEN n1.age_rating IS NOT DISTINCT FROM n2.age_rating THEN 0.2 ELSE 0 END) +
+        (CASE WHEN abs(n1.year_start - n2.year_start) <= 3 THEN 0.2 ELSE 0 END) AS meta_score
+    FROM candidates c
+    JOIN novels n1 ON n1.id = c.novel_id
+    JOIN novels n2 ON n2.id = c.similar_id
+    LEFT JOIN co_readers co ON co.novel_id = c.novel_id AND co.similar_id = c.similar_id
+    LEFT JOIN similar_reader_counts rc1 ON rc1.novel_id = c.novel_id
+    LEFT JOIN similar_reader_counts rc2 ON rc2.novel_id = c.similar_id
+), ranked AS (
+    SELECT
+        novel_id, similar_id,
+        0.5 * reader_score + 0.35 * text_score + 0.15 * meta_score AS score,
+        ROW_NUMBER() OVER (
+            PARTITION BY novel_id
+            ORDER BY 0.5 * reader_score + 0.35 * text_score + 0.15 * meta_score DESC, similar_id
+        ) AS rn
+    FROM pairs
+)
+INSERT INTO novel_similar (novel_id, similar_id, score)
+SELECT novel_id, similar_id, score
+FROM ranked
+WHERE rn <= $2 AND score > 0;
@@ -0,0 +1,6 @@
+SELECT COUNT(*) FROM (
+    SELECT 1
+    FROM chapters
+    WHERE created_at > now() - make_interval(days => $1)
+    GROUP BY novel_id, (created_at AT TIME ZONE 'Europe/Moscow')::date
+) g;
@@ -0,0 +1,20 @@
+WITH groups AS (
+    SELECT
+        novel_id,
+        (created_at AT TIME ZONE 'Europe/Moscow')::date AS day,
+        (array_agg(id ORDER BY chapter_num ASC))[1] AS first_chapter_id,
+        MIN(chapter_num) AS first_num,
+        MAX(chapter_num) AS last_num,
+        COUNT(*) AS chapters_count,
+        MAX(created_at) AS updated_at
+    FROM chapters
+    WHERE created_at > now() - make_interval(days => $1)
+    GROUP BY novel_id, (created_at AT TIME ZONE 'Europe/Moscow')::date
+)
+SELECT
+    n.id, n.title, n.cover_url,
+    g.day, g.first_chapter_id, g.first_num, g.last_num, g.chapters_count, g.updated_at
+FROM groups g
+JOIN novels n ON n.id = g.novel_id
+ORDER BY g.updated_at DESC
+LIMIT $2 OFFSET $3;
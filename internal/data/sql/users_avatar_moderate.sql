@@ -0,0 +1,9 @@
+UPDATE users SET
+    has_custom_avatar = CASE WHEN $2 = 'approved' THEN true ELSE has_custom_avatar END,
+    avatar_status = CASE WHEN $2 = 'approved' THEN NULL ELSE 'rejected' END,
+    avatar_submission_id = NULL,
+    avatar_telegram_message_id = NULL
+WHERE id = $1
+  AND avatar_status = 'pending'
+  AND avatar_submission_id = $3
+RETURNING id;
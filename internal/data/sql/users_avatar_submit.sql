@@ -0,0 +1,13 @@
+UPDATE users u SET
+    avatar_status = 'pending',
+    avatar_submission_id = $2,
+    avatar_telegram_message_id = NULL,
+    last_active_at = now()
+FROM (
+    SELECT id, avatar_telegram_message_id, avatar_submission_id
+    FROM users
+    WHERE id = $1
+    FOR UPDATE
+) prev
+WHERE u.id = prev.id
+RETURNING u.display_name, prev.avatar_telegram_message_id, prev.avatar_submission_id;
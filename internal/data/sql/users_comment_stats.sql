@@ -0,0 +1,7 @@
+SELECT COUNT(*),
+       COALESCE(SUM(c.likes_count), 0),
+       COALESCE(SUM(c.replies_count), 0),
+       COUNT(DISTINCT ch.novel_id)
+FROM comments c
+JOIN chapters ch ON ch.id = c.chapter_id
+WHERE c.user_id = $1 AND c.status = 'approved' AND c.deleted_at IS NULL;
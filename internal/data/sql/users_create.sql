@@ -0,0 +1,9 @@
+WITH created AS (
+    INSERT INTO users (display_name, avatar_seed, cookies)
+    VALUES ($1, $2, '{}')
+    RETURNING id, display_name, avatar_seed, created_at
+), session AS (
+    INSERT INTO sessions (user_id, token_hash, device_label)
+    SELECT id, $3, $4 FROM created
+)
+SELECT id, display_name, avatar_seed, created_at FROM created;
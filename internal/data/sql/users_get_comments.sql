@@ -0,0 +1,8 @@
+SELECT c.id, c.chapter_id, ch.chapter_num, ch.title, ch.novel_id, n.title,
+       c.content_html, c.likes_count, c.replies_count, c.created_at
+FROM comments c
+JOIN chapters ch ON ch.id = c.chapter_id
+JOIN novels n ON n.id = ch.novel_id
+WHERE c.user_id = $1 AND c.status = 'approved' AND c.deleted_at IS NULL
+ORDER BY c.created_at DESC
+LIMIT $2 OFFSET $3;
@@ -0,0 +1,4 @@
+WITH deleted_novels AS (
+    DELETE FROM novel_views WHERE day < $1
+)
+DELETE FROM chapter_views WHERE day < $1;
@@ -0,0 +1,14 @@
+WITH inserted AS (
+    INSERT INTO chapter_views (chapter_id, visitor_hash, day)
+    SELECT v.chapter_id, v.visitor_hash, v.day
+    FROM unnest($1::varchar[], $2::varchar[], $3::date[]) AS v(chapter_id, visitor_hash, day)
+    JOIN chapters c ON c.id = v.chapter_id
+    ON CONFLICT DO NOTHING
+    RETURNING chapter_id
+), counts AS (
+    SELECT chapter_id, COUNT(*) AS cnt FROM inserted GROUP BY chapter_id
+)
+UPDATE chapters c
+SET views_count = c.views_count + cnt.cnt
+FROM counts cnt
+WHERE c.id = cnt.chapter_id;
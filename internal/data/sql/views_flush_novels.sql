@@ -0,0 +1,14 @@
+WITH inserted AS (
+    INSERT INTO novel_views (novel_id, visitor_hash, day)
+    SELECT v.novel_id, v.visitor_hash, v.day
+    FROM unnest($1::varchar[], $2::varchar[], $3::date[]) AS v(novel_id, visitor_hash, day)
+    JOIN novels n ON n.id = v.novel_id
+    ON CONFLICT DO NOTHING
+    RETURNING novel_id
+), counts AS (
+    SELECT novel_id, COUNT(*) AS cnt FROM inserted GROUP BY novel_id
+)
+UPDATE novels n
+SET views_count = n.views_count + c.cnt
+FROM counts c
+WHERE n.id = c.novel_id;
@@ -0,0 +1,81 @@
+package data
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"time"
+
+	"github.com/ch1kulya/kappalib/internal/cache"
+	"github.com/ch1kulya/kappalib/internal/database"
+	"github.com/ch1kulya/kappalib/internal/models"
+
+	"github.com/ch1kulya/logger"
+)
+
+//go:embed sql/updates_count.sql
+var queryUpdatesCount string
+
+//go:embed sql/updates_get_recent.sql
+var queryUpdatesGetRecent string
+
+const updatesWindowDays = 30
+
+func GetRecentUpdates(ctx context.Context, page, pageSize int) (*models.UpdatesPage, error) {
+	key := fmt.Sprintf("updates:page:%d:size:%d", page, pageSize)
+	offset := (page - 1) * pageSize
+
+	value, err := cache.C.GetOrFetch(key, 2*time.Minute, func() (any, error) {
+		dbCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		var totalCount int
+		if err := database.DB.QueryRow(dbCtx, queryUpdatesCount, updatesWindowDays).Scan(&totalCount); err != nil {
+			logger.Error("GetRecentUpdates: Failed to count updates: %v", err)
+			return nil, err
+		}
+
+		totalPages := (totalCount + pageSize - 1) / pageSize
+		if totalCount == 0 || offset >= totalCount {
+			return &models.UpdatesPage{
+				Updates:    []models.ChapterUpdate{},
+				Page:       page,
+				PageSize:   pageSize,
+				TotalCount: totalCount,
+				TotalPages: totalPages,
+			}, nil
+		}
+
+		rows, err := database.DB.Query(dbCtx, queryUpdatesGetRecent, updatesWindowDays, pageSize, offset)
+		if err != nil {
+			logger.Error("GetRecentUpdates: Failed to query updates: %v", err)
+			return nil, err
+		}
+		defer rows.Close()
+
+		updates := make([]models.ChapterUpdate, 0, pageSize)
+		for rows.Next() {
+			var u models.ChapterUpdate
+			if err := rows.Scan(&u.NovelID, &u.NovelTitle, &u.NovelCoverURL,
+				&u.Day, &u.FirstChapterID, &u.FirstChapterNum, &u.LastChapterNum,
+				&u.ChaptersCount, &u.UpdatedAt); err != nil {
+				logger.Warn("GetRecentUpdates: Row scan error: %v", err)
+				continue
+			}
+			updates = append(updates, u)
+		}
+
+		return &models.UpdatesPage{
+			Updates:    updates,
+			Page:       page,
+			PageSize:   pageSize,
+			TotalCount: totalCount,
+			TotalPages: totalPages,
+		}, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	return value.(*models.UpdatesPage), nil
+}
@@ -1,16 +1,12 @@
 package data
 
 import (
-	"bytes"
 	"context"
 	"crypto/rand"
-	"crypto/subtle"
+	_ "embed"
 	"encoding/hex"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"image"
-	"image/jpeg"
 	"io"
 	"maps"
 	"math/big"
@@ -24,7 +20,6 @@ import (
 	"github.com/ch1kulya/kappalib/internal/models"
 	"github.com/microcosm-cc/bluemonday"
 	"github.com/minio/minio-go/v7"
-	"golang.org/x/image/draw"
 
 	"github.com/ch1kulya/logger"
 )
@@ -41,7 +36,7 @@ var (
 		"Филин", "Хорёк", "Енот", "Суслик", "Бобр",
 	}
 	cookieNameRegex  = regexp.MustCompile(`^kappalib_[a-z0-9_]{1,50}$`)
-	cookieValueRegex = regexp.MustCompile(`^[a-zA-Z0-9_\-{}\[\]":,.\s]{1,500}$`)
+	cookieValueRegex = regexp.MustCompile(`^[a-zA-Z0-9_\-{}\[\]":,.#\s]{1,500}$`)
 	turnstileSecret  = os.Getenv("TURNSTILE_SECRET")
 )
 
@@ -54,7 +49,8 @@ var (
 	strictPolicy       = bluemonday.StrictPolicy()
 )
 
-var ErrUnsupportedFormat = fmt.Errorf("unsupported image format")
+//go:embed sql/users_create.sql
+var queryUsersCreate string
 
 func generateRandomName() string {
 	adjIdx, _ := rand.Int(rand.Reader, big.NewInt(int64(len(adjectives))))
@@ -112,12 +108,8 @@ func verifyTurnstile(token string) bool {
 }
 
 func verifySecretToken(ctx context.Context, profileID, providedToken string) bool {
-	var storedToken string
-	err := database.DB.QueryRow(ctx, `SELECT secret_token FROM users WHERE id = $1`, profileID).Scan(&storedToken)
-	if err != nil {
-		return false
-	}
-	return subtle.ConstantTimeCompare([]byte(storedToken), []byte(providedToken)) == 1
+	_, ok := lookupSession(ctx, profileID, providedToken)
+	return ok
 }
 
 func validateCookies(cookies map[string]models.CookieValue) map[string]models.CookieValue {
@@ -147,7 +139,7 @@ func mergeCookies(existing, incoming map[string]models.CookieValue) map[string]m
 	return result
 }
 
-func CreateProfile(ctx context.Context, turnstileToken string) (*models.ProfileWithToken, error) {
+func CreateProfile(ctx context.Context, turnstileToken, userAgent string) (*models.ProfileWithToken, error) {
 	if !verifyTurnstile(turnstileToken) {
 		return nil, fmt.Errorf("captcha verification failed")
 	}
@@ -159,13 +151,10 @@ func CreateProfile(ctx context.Context, turnstileToken string) (*models.ProfileW
 	avatarSeed := generateAvatarSeed()
 	secretToken := generateSecretToken()
 
-	var profile models.ProfileWithToken
-	err := database.DB.QueryRow(dbCtx,
-		`INSERT INTO users (secret_token, display_name, avatar_seed, cookies)
-		VALUES ($1, $2, $3, '{}')
-		RETURNING id, secret_token, display_name, avatar_seed, created_at`,
-		secretToken, displayName, avatarSeed).Scan(
-		&profile.ID, &profile.SecretToken, &profile.DisplayName, &profile.AvatarSeed, &profile.CreatedAt)
+	profile := models.ProfileWithToken{SecretToken: secretToken}
+	err := database.DB.QueryRow(dbCtx, queryUsersCreate,
+		displayName, avatarSeed, hashToken(secretToken), deviceLabel(userAgent)).Scan(
+		&profile.ID, &profile.DisplayName, &profile.AvatarSeed, &profile.CreatedAt)
 
 	if err != nil {
 		logger.Error("Failed to create profile: %v", err)
@@ -182,8 +171,8 @@ func GetProfile(ctx context.Context, profileID string) (*models.ProfilePublic, e
 
 	var profile models.ProfilePublic
 	err := database.DB.QueryRow(dbCtx,
-		`SELECT id, display_name, avatar_seed, has_custom_avatar, created_at FROM users WHERE id = $1`,
-		profileID).Scan(&profile.ID, &profile.DisplayName, &profile.AvatarSeed, &profile.HasCustomAvatar, &profile.CreatedAt)
+		`SELECT id, display_name, avatar_seed, has_custom_avatar, COALESCE(avatar_status, ''), is_public, created_at FROM users WHERE id = $1`,
+		profileID).Scan(&profile.ID, &profile.DisplayName, &profile.AvatarSeed, &profile.HasCustomAvatar, &profile.AvatarStatus, &profile.IsPublic, &profile.CreatedAt)
 
 	if err != nil {
 		return nil, err
@@ -194,7 +183,7 @@ func GetProfile(ctx context.Context, profileID string) (*models.ProfilePublic, e
 	return &profile, nil
 }
 
-func GenerateSyncCode(ctx context.Context, profileID, secretToken string) (*models.SyncCodeResponse, error) {
+func GenerateSyncCode(ctx context.Context, profileID, secretToken string, requireConfirmation bool) (*models.SyncCodeResponse, error) {
 	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
@@ -206,8 +195,8 @@ func GenerateSyncCode(ctx context.Context, profileID, secretToken string) (*mode
 	expiresAt := time.Now().Add(15 * time.Minute)
 
 	_, err := database.DB.Exec(dbCtx,
-		`UPDATE users SET sync_code = $1, sync_code_expires_at = $2, last_active_at = now() WHERE id = $3`,
-		syncCode, expiresAt, profileID)
+		`UPDATE users SET sync_code = $1, sync_code_expires_at = $2, sync_code_confirm = $3, last_active_at = now() WHERE id = $4`,
+		syncCode, expiresAt, requireConfirmation, profileID)
 
 	if err != nil {
 		logger.Error("Failed to generate sync code: %v", err)
@@ -216,45 +205,9 @@ func GenerateSyncCode(ctx context.Context, profileID, secretToken string) (*mode
 
 	logger.Info("Sync code generated for %s", profileID)
 	return &models.SyncCodeResponse{
-		SyncCode:  syncCode,
-		ExpiresAt: expiresAt.Format(time.RFC3339),
-	}, nil
-}
-
-func LoginWithSyncCode(ctx context.Context, syncCode string) (*models.LoginResponse, error) {
-	syncCode = strings.ToUpper(strings.TrimSpace(syncCode))
-	if len(syncCode) != 8 {
-		return nil, fmt.Errorf("invalid sync code format")
-	}
-
-	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
-
-	var profile models.ProfilePublic
-	var secretToken string
-	var cookiesJSON []byte
-	err := database.DB.QueryRow(dbCtx, `
-		SELECT id, secret_token, display_name, avatar_seed, created_at, cookies
-		FROM users
-		WHERE sync_code = $1 AND sync_code_expires_at > now()`,
-		syncCode).Scan(&profile.ID, &secretToken, &profile.DisplayName, &profile.AvatarSeed, &profile.CreatedAt, &cookiesJSON)
-
-	if err != nil {
-		return nil, fmt.Errorf("invalid or expired sync code")
-	}
-
-	var cookies map[string]models.CookieValue
-	json.Unmarshal(cookiesJSON, &cookies)
-
-	database.DB.Exec(dbCtx,
-		`UPDATE users SET sync_code = NULL, sync_code_expires_at = NULL, last_active_at = now() WHERE id = $1`,
-		profile.ID)
-
-	logger.Info("Login via sync code: %s", profile.ID)
-	return &models.LoginResponse{
-		Profile:     profile,
-		SecretToken: secretToken,
-		Cookies:     cookies,
+		SyncCode:             syncCode,
+		ExpiresAt:            expiresAt.Format(time.RFC3339),
+		RequiresConfirmation: requireConfirmation,
 	}, nil
 }
 
@@ -362,86 +315,22 @@ func UpdateDisplayName(ctx context.Context, profileID, secretToken, newName stri
 	return GetProfile(ctx, profileID)
 }
 
-func UpdateAvatar(ctx context.Context, profileID, secretToken string, imageData []byte) (*models.ProfilePublic, error) {
-	if minioClient == nil {
-		return nil, fmt.Errorf("s3 not configured")
-	}
-
-	dbCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+func UpdatePrivacy(ctx context.Context, profileID, secretToken string, isPublic bool) (*models.ProfilePublic, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	if !verifySecretToken(dbCtx, profileID, secretToken) {
 		return nil, fmt.Errorf("invalid secret token")
 	}
 
-	select {
-	case imageProcessingSem <- struct{}{}:
-		defer func() { <-imageProcessingSem }()
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	}
-
-	imgData, err := processAvatar(imageData)
-	if err != nil {
-		if errors.Is(err, ErrUnsupportedFormat) {
-			return nil, fmt.Errorf("unsupported format")
-		}
-		return nil, fmt.Errorf("image processing failed: %w", err)
-	}
-
-	key := fmt.Sprintf("avatars/%s.jpg", profileID)
-	reader := bytes.NewReader(imgData)
-
-	_, err = minioClient.PutObject(ctx, s3Bucket, key, reader, int64(len(imgData)), minio.PutObjectOptions{
-		ContentType:  "image/jpeg",
-		CacheControl: "public, max-age=3600",
-	})
-	if err != nil {
-		return nil, fmt.Errorf("s3 upload failed: %w", err)
-	}
-
-	_, err = database.DB.Exec(dbCtx,
-		`UPDATE users SET has_custom_avatar = true, last_active_at = now() WHERE id = $1`,
-		profileID)
+	_, err := database.DB.Exec(dbCtx,
+		`UPDATE users SET is_public = $1, last_active_at = now() WHERE id = $2`,
+		isPublic, profileID)
 	if err != nil {
 		return nil, err
 	}
 
-	return GetProfile(ctx, profileID)
-}
+	logger.Debug("Updated profile visibility for %s: public=%t", profileID, isPublic)
 
-func processAvatar(data []byte) ([]byte, error) {
-	img, format, err := image.Decode(bytes.NewReader(data))
-	if err != nil {
-		return nil, ErrUnsupportedFormat
-	}
-
-	if format != "jpeg" && format != "png" {
-		return nil, ErrUnsupportedFormat
-	}
-
-	bounds := img.Bounds()
-	srcW, srcH := bounds.Dx(), bounds.Dy()
-
-	var cropRect image.Rectangle
-	if srcW > srcH {
-		offset := (srcW - srcH) / 2
-		cropRect = image.Rect(offset, 0, offset+srcH, srcH)
-	} else {
-		offset := (srcH - srcW) / 2
-		cropRect = image.Rect(0, offset, srcW, offset+srcW)
-	}
-
-	cropped := image.NewRGBA(image.Rect(0, 0, cropRect.Dx(), cropRect.Dy()))
-	draw.Draw(cropped, cropped.Bounds(), img, cropRect.Min, draw.Src)
-
-	resized := image.NewRGBA(image.Rect(0, 0, 250, 250))
-	draw.CatmullRom.Scale(resized, resized.Bounds(), cropped, cropped.Bounds(), draw.Over, nil)
-
-	var buf bytes.Buffer
-	if err := jpeg.Encode(&buf, resized, &jpeg.Options{Quality: 85}); err != nil {
-		return nil, err
-	}
-
-	return buf.Bytes(), nil
+	return GetProfile(ctx, profileID)
 }
@@ -0,0 +1,213 @@
+package data
+
+import (
+	"context"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ch1kulya/kappalib/internal/database"
+
+	"github.com/ch1kulya/logger"
+)
+
+//go:embed sql/views_flush_novels.sql
+var queryViewsFlushNovels string
+
+//go:embed sql/views_flush_chapters.sql
+var queryViewsFlushChapters string
+
+//go:embed sql/views_cleanup.sql
+var queryViewsCleanup string
+
+const (
+	viewsFlushInterval = time.Minute
+	viewsRetentionDays = 30
+	maxPendingViews    = 50000
+)
+
+type viewKey struct {
+	id          string
+	visitorHash string
+	day         time.Time
+}
+
+var viewBuffer = struct {
+	sync.Mutex
+	novels   map[viewKey]struct{}
+	chapters map[viewKey]struct{}
+}{
+	novels:   make(map[viewKey]struct{}),
+	chapters: make(map[viewKey]struct{}),
+}
+
+var viewsSalt []byte
+
+func InitViews() error {
+	salt := os.Getenv("VIEWS_SALT")
+	if salt == "" {
+		return fmt.Errorf("VIEWS_SALT not set")
+	}
+	viewsSalt = []byte(salt)
+	return nil
+}
+
+func viewDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func hashVisitor(visitor string, day time.Time) string {
+	h := sha256.New()
+	h.Write(viewsSalt)
+	h.Write([]byte(day.Format(time.DateOnly)))
+	h.Write([]byte(visitor))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func RecordView(novelID, chapterID, visitor string) {
+	if novelID == "" {
+		return
+	}
+
+	day := viewDay(time.Now())
+	visitorHash := hashVisitor(visitor, day)
+
+	viewBuffer.Lock()
+	defer viewBuffer.Unlock()
+
+	if len(viewBuffer.novels)+len(viewBuffer.chapters) >= maxPendingViews {
+		logger.Warn("View buffer is full, dropping view for novel %s", novelID)
+		return
+	}
+
+	viewBuffer.novels[viewKey{novelID, visitorHash, day}] = struct{}{}
+	if chapterID != "" {
+		viewBuffer.chapters[viewKey{chapterID, visitorHash, day}] = struct{}{}
+	}
+}
+
+func takePendingViews() (map[viewKey]struct{}, map[viewKey]struct{}) {
+	viewBuffer.Lock()
+	defer viewBuffer.Unlock()
+
+	novels, chapters := viewBuffer.novels, viewBuffer.chapters
+	viewBuffer.novels = make(map[viewKey]struct{})
+	viewBuffer.chapters = make(map[viewKey]struct{})
+	return novels, chapters
+}
+
+func restorePendingViews(novels, chapters map[viewKey]struct{}) int {
+	viewBuffer.Lock()
+	defer viewBuffer.Unlock()
+
+	dropped := 0
+	restore := func(dst, src map[viewKey]struct{}) {
+		for k := range src {
+			if _, ok := dst[k]; ok {
+				continue
+			}
+			if len(viewBuffer.novels)+len(viewBuffer.chapters) >= maxPendingViews {
+				dropped++
+				continue
+			}
+			dst[k] = struct{}{}
+		}
+	}
+	restore(viewBuffer.novels, novels)
+	restore(viewBuffer.chapters, chapters)
+	return dropped
+}
+
+func viewColumns(views map[viewKey]struct{}) ([]string, []string, []time.Time) {
+	ids := make([]string, 0, len(views))
+	hashes := make([]string, 0, len(views))
+	days := make([]time.Time, 0, len(views))
+	for k := range views {
+		ids = append(ids, k.id)
+		hashes = append(hashes, k.visitorHash)
+		days = append(days, k.day)
+	}
+	return ids, hashes, days
+}
+
+func FlushViews(ctx context.Context) error {
+	novels, chapters := takePendingViews()
+	if len(novels) == 0 && len(chapters) == 0 {
+		return nil
+	}
+
+	if err := writeViews(ctx, novels, chapters); err != nil {
+		if dropped := restorePendingViews(novels, chapters); dropped > 0 {
+			logger.Warn("View buffer is full, dropped %d views from the failed flush", dropped)
+		}
+		return err
+	}
+
+	logger.Debug("Flushed %d novel views and %d chapter views", len(novels), len(chapters))
+	return nil
+}
+
+func writeViews(ctx context.Context, novels, chapters map[viewKey]struct{}) error {
+	dbCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	tx, err := database.DB.Begin(dbCtx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(dbCtx)
+
+	if len(novels) > 0 {
+		ids, hashes, days := viewColumns(novels)
+		if _, err := tx.Exec(dbCtx, queryViewsFlushNovels, ids, hashes, days); err != nil {
+			return err
+		}
+	}
+
+	if len(chapters) > 0 {
+		ids, hashes, days := viewColumns(chapters)
+		if _, err := tx.Exec(dbCtx, queryViewsFlushChapters, ids, hashes, days); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(dbCtx)
+}
+
+func cleanupViews(ctx context.Context) error {
+	dbCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	cutoff := viewDay(time.Now()).AddDate(0, 0, -viewsRetentionDays)
+	_, err := database.DB.Exec(dbCtx, queryViewsCleanup, cutoff)
+	return err
+}
+
+func StartViewCounter(ctx context.Context) {
+	ticker := time.NewTicker(viewsFlushInterval)
+	defer ticker.Stop()
+
+	lastCleanup := time.Time{}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := FlushViews(ctx); err != nil {
+				logger.Error("Failed to flush views: %v", err)
+			}
+			if time.Since(lastCleanup) > 24*time.Hour {
+				if err := cleanupViews(ctx); err != nil {
+					logger.Warn("Failed to clean up old views: %v", err)
+				} else {
+					lastCleanup = time.Now()
+				}
+			}
+		}
+	}
+}
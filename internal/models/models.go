@@ -13,6 +13,7 @@ type Novel struct {
 	Description string    `json:"description"`
 	AgeRating   *string   `json:"age_rating"`
 	CoverURL    *string   `json:"cover_url"`
+	ViewsCount  int64     `json:"views_count"`
 	CreatedAt   time.Time `json:"created_at"`
 }
 
@@ -24,6 +25,11 @@ type NovelsPage struct {
 	TotalPages int     `json:"total_pages"`
 }
 
+type SimilarNovels struct {
+	NovelID string  `json:"novel_id"`
+	Novels  []Novel `json:"novels"`
+}
+
 type Source struct {
 	Name    string  `json:"name"`
 	LogoURL *string `json:"logo_url"`
@@ -44,6 +50,7 @@ type Chapter struct {
 	TitleEn    *string   `json:"title_en"`
 	Content    string    `json:"content"`
 	Source     *Source   `json:"source"`
+	ViewsCount int64     `json:"views_count"`
 	CreatedAt  time.Time `json:"created_at"`
 }
 
@@ -53,6 +60,26 @@ type ChaptersList struct {
 	Count    int              `json:"count"`
 }
 
+type ChapterUpdate struct {
+	NovelID         string    `json:"novel_id"`
+	NovelTitle      string    `json:"novel_title"`
+	NovelCoverURL   *string   `json:"novel_cover_url"`
+	Day             time.Time `json:"day"`
+	FirstChapterID  string    `json:"first_chapter_id"`
+	FirstChapterNum int       `json:"first_chapter_num"`
+	LastChapterNum  int       `json:"last_chapter_num"`
+	ChaptersCount   int       `json:"chapters_count"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type UpdatesPage struct {
+	Updates    []ChapterUpdate `json:"updates"`
+	Page       int             `json:"page"`
+	PageSize   int             `json:"page_size"`
+	TotalCount int             `json:"total_count"`
+	TotalPages int             `json:"total_pages"`
+}
+
 type SitemapItem struct {
 	ID        string    `json:"id"`
 	CreatedAt time.Time `json:"created_at"`
@@ -68,6 +95,8 @@ type ProfilePublic struct {
 	DisplayName     string    `json:"display_name"`
 	AvatarSeed      string    `json:"avatar_seed"`
 	HasCustomAvatar bool      `json:"has_custom_avatar"`
+	AvatarStatus    string    `json:"avatar_status,omitempty"`
+	IsPublic        bool      `json:"is_public"`
 	CreatedAt       time.Time `json:"created_at"`
 }
 
@@ -80,43 +109,357 @@ type ProfileWithToken struct {
 }
 
 type SyncCodeResponse struct {
-	SyncCode  string `json:"sync_code"`
-	ExpiresAt string `json:"expires_at"`
+	SyncCode             string `json:"sync_code"`
+	ExpiresAt            string `json:"expires_at"`
+	RequiresConfirmation bool   `json:"requires_confirmation"`
+}
+
+type Session struct {
+	ID          string    `json:"id"`
+	DeviceLabel string    `json:"device_label"`
+	CreatedAt   time.Time `json:"created_at"`
+	LastSeenAt  time.Time `json:"last_seen_at"`
+	Current     bool      `json:"current"`
+}
+
+type BlockedUser struct {
+	UserID          string    `json:"user_id"`
+	DisplayName     string    `json:"display_name"`
+	AvatarSeed      string    `json:"avatar_seed"`
+	HasCustomAvatar bool      `json:"has_custom_avatar"`
+	Kind            string    `json:"kind"`
+	CreatedAt       time.Time `json:"created_at"`
 }
 
 type LoginResponse struct {
-	Profile     ProfilePublic          `json:"profile"`
-	SecretToken string                 `json:"secret_token"`
-	Cookies     map[string]CookieValue `json:"cookies"`
+	Status      string                 `json:"status"`
+	Profile     *ProfilePublic         `json:"profile,omitempty"`
+	SecretToken string                 `json:"secret_token,omitempty"`
+	Cookies     map[string]CookieValue `json:"cookies,omitempty"`
+	RequestID   string                 `json:"request_id,omitempty"`
+	PollToken   string                 `json:"poll_token,omitempty"`
+}
+
+type LoginRequest struct {
+	ID          string    `json:"id"`
+	DeviceLabel string    `json:"device_label"`
+	IP          string    `json:"ip"`
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
 }
 
 type Comment struct {
+	ID                  string     `json:"id"`
+	ChapterID           string     `json:"chapter_id"`
+	UserID              string     `json:"user_id"`
+	ParentID            *string    `json:"parent_id"`
+	Depth               int        `json:"depth"`
+	RepliesCount        int        `json:"replies_count"`
+	LikesCount          int        `json:"likes_count"`
+	DislikesCount       int        `json:"dislikes_count"`
+	ContentHTML         string     `json:"content_html"`
+	Status              string     `json:"status"`
+	TelegramMessageID   *int64     `json:"telegram_message_id,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	EditedAt            *time.Time `json:"edited_at"`
+	IsDeleted           bool       `json:"is_deleted"`
+	UserDisplayName     string     `json:"user_display_name,omitempty"`
+	UserAvatarSeed      string     `json:"user_avatar_seed,omitempty"`
+	UserHasCustomAvatar bool       `json:"user_has_custom_avatar,omitempty"`
+	Replies             []Comment  `json:"replies,omitempty"`
+	HasMoreReplies      bool       `json:"has_more_replies,omitempty"`
+}
+
+type CommentReplies struct {
+	Replies []Comment `json:"replies"`
+	HasMore bool      `json:"has_more"`
+}
+
+type CommentsPage struct {
+	Comments         []Comment `json:"comments"`
+	Page             int       `json:"page"`
+	PageSize         int       `json:"page_size"`
+	TotalCount       int       `json:"total_count"`
+	TotalPages       int       `json:"total_pages"`
+	TotalWithReplies int       `json:"total_with_replies"`
+}
+
+type CreateCommentInput struct {
+	ChapterID      string `json:"chapter_id"`
+	ParentID       string `json:"parent_id"`
+	Content        string `json:"content"`
+	TurnstileToken string `json:"turnstile_token"`
+}
+
+type CommentReaction struct {
+	CommentID     string `json:"comment_id"`
+	Reaction      string `json:"reaction"`
+	LikesCount    int    `json:"likes_count"`
+	DislikesCount int    `json:"dislikes_count"`
+}
+
+type LibraryNovel struct {
+	Title         string  `json:"title"`
+	TitleEn       string  `json:"title_en"`
+	Author        string  `json:"author"`
+	CoverURL      *string `json:"cover_url"`
+	ChaptersCount int     `json:"chapters_count"`
+}
+
+type LibraryEntry struct {
+	NovelID   string        `json:"novel_id"`
+	Shelf     string        `json:"shelf"`
+	Rating    *int          `json:"rating"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	Novel     *LibraryNovel `json:"novel,omitempty"`
+}
+
+type Library struct {
+	Entries []LibraryEntry `json:"entries"`
+	Counts  map[string]int `json:"counts"`
+}
+
+type ReadingProgress struct {
+	NovelID       string    `json:"novel_id"`
+	ChapterID     string    `json:"chapter_id"`
+	ChapterNum    int       `json:"chapter_num"`
+	Paragraph     int       `json:"paragraph"`
+	ScrollPercent float64   `json:"scroll_percent"`
+	TotalChapters int       `json:"total_chapters"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type HistoryEntry struct {
+	ID            int64     `json:"id"`
+	NovelID       string    `json:"novel_id"`
+	NovelTitle    string    `json:"novel_title"`
+	NovelCoverURL *string   `json:"novel_cover_url"`
+	ChapterID     string    `json:"chapter_id"`
+	ChapterNum    int       `json:"chapter_num"`
+	ChapterTitle  string    `json:"chapter_title"`
+	ReadAt        time.Time `json:"read_at"`
+}
+
+type HistoryPage struct {
+	Entries  []HistoryEntry `json:"entries"`
+	Page     int            `json:"page"`
+	PageSize int            `json:"page_size"`
+	HasMore  bool           `json:"has_more"`
+}
+
+type Notification struct {
+	ID              int64     `json:"id"`
+	Type            string    `json:"type"`
+	NovelID         string    `json:"novel_id"`
+	NovelTitle      string    `json:"novel_title"`
+	NovelCoverURL   *string   `json:"novel_cover_url"`
+	ChapterID       *string   `json:"chapter_id"`
+	FirstChapterNum int       `json:"first_chapter_num"`
+	LastChapterNum  int       `json:"last_chapter_num"`
+	ChaptersCount   int       `json:"chapters_count"`
+	CommentID       *string   `json:"comment_id"`
+	ActorName       *string   `json:"actor_name"`
+	IsRead          bool      `json:"is_read"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type NotificationsPage struct {
+	Notifications []Notification `json:"notifications"`
+	Page          int            `json:"page"`
+	PageSize      int            `json:"page_size"`
+	HasMore       bool           `json:"has_more"`
+	UnreadCount   int            `json:"unread_count"`
+}
+
+type FollowStatus struct {
+	NovelID   string `json:"novel_id"`
+	Following bool   `json:"following"`
+}
+
+type RatingSummary struct {
+	NovelID      string  `json:"novel_id"`
+	Average      float64 `json:"average"`
+	Count        int     `json:"count"`
+	Distribution []int   `json:"distribution"`
+}
+
+type Review struct {
 	ID                  string    `json:"id"`
-	ChapterID           string    `json:"chapter_id"`
+	NovelID             string    `json:"novel_id"`
 	UserID              string    `json:"user_id"`
 	ContentHTML         string    `json:"content_html"`
 	Status              string    `json:"status"`
+	Score               *int      `json:"score"`
 	TelegramMessageID   *int64    `json:"telegram_message_id,omitempty"`
 	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
 	UserDisplayName     string    `json:"user_display_name,omitempty"`
 	UserAvatarSeed      string    `json:"user_avatar_seed,omitempty"`
 	UserHasCustomAvatar bool      `json:"user_has_custom_avatar,omitempty"`
 }
 
-type CommentsPage struct {
-	Comments   []Comment `json:"comments"`
-	Page       int       `json:"page"`
-	PageSize   int       `json:"page_size"`
-	TotalCount int       `json:"total_count"`
-	TotalPages int       `json:"total_pages"`
+type ReviewsPage struct {
+	Reviews    []Review `json:"reviews"`
+	Page       int      `json:"page"`
+	PageSize   int      `json:"page_size"`
+	TotalCount int      `json:"total_count"`
+	TotalPages int      `json:"total_pages"`
 }
 
-type CreateCommentInput struct {
-	ChapterID      string `json:"chapter_id"`
+type UserRating struct {
+	NovelID string  `json:"novel_id"`
+	Score   *int    `json:"score"`
+	Review  *Review `json:"review"`
+}
+
+type CreateReviewInput struct {
+	NovelID        string `json:"novel_id"`
 	Content        string `json:"content"`
 	TurnstileToken string `json:"turnstile_token"`
 }
 
+type UserComment struct {
+	ID           string    `json:"id"`
+	ChapterID    string    `json:"chapter_id"`
+	ChapterNum   int       `json:"chapter_num"`
+	ChapterTitle string    `json:"chapter_title"`
+	NovelID      string    `json:"novel_id"`
+	NovelTitle   string    `json:"novel_title"`
+	ContentHTML  string    `json:"content_html"`
+	LikesCount   int       `json:"likes_count"`
+	RepliesCount int       `json:"replies_count"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type UserCommentStats struct {
+	CommentsCount   int `json:"comments_count"`
+	LikesReceived   int `json:"likes_received"`
+	RepliesReceived int `json:"replies_received"`
+	NovelsCount     int `json:"novels_count"`
+}
+
+type UserPage struct {
+	Profile    ProfilePublic    `json:"profile"`
+	Stats      UserCommentStats `json:"stats"`
+	Comments   []UserComment    `json:"comments"`
+	Page       int              `json:"page"`
+	PageSize   int              `json:"page_size"`
+	TotalPages int              `json:"total_pages"`
+}
+
+type ExportProfile struct {
+	DisplayName     string    `json:"display_name"`
+	AvatarSeed      string    `json:"avatar_seed"`
+	HasCustomAvatar bool      `json:"has_custom_avatar"`
+	IsPublic        bool      `json:"is_public"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type ExportLibraryEntry struct {
+	NovelID   string    `json:"novel_id"`
+	Shelf     string    `json:"shelf"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ExportProgress struct {
+	NovelID       string    `json:"novel_id"`
+	ChapterID     string    `json:"chapter_id"`
+	Paragraph     int       `json:"paragraph"`
+	ScrollPercent float64   `json:"scroll_percent"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type ExportHistoryEntry struct {
+	NovelID   string    `json:"novel_id"`
+	ChapterID string    `json:"chapter_id"`
+	ReadAt    time.Time `json:"read_at"`
+}
+
+type ExportFollow struct {
+	NovelID   string    `json:"novel_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ExportRating struct {
+	NovelID   string    `json:"novel_id"`
+	Score     int       `json:"score"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ExportReview struct {
+	ID          string    `json:"id"`
+	NovelID     string    `json:"novel_id"`
+	ContentHTML string    `json:"content_html"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type ExportComment struct {
+	ID          string     `json:"id"`
+	ChapterID   string     `json:"chapter_id"`
+	ParentID    *string    `json:"parent_id"`
+	ContentHTML string     `json:"content_html"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	EditedAt    *time.Time `json:"edited_at"`
+}
+
+type ProfileExport struct {
+	Version    int                    `json:"version"`
+	ExportedAt time.Time              `json:"exported_at"`
+	ProfileID  string                 `json:"profile_id"`
+	Profile    ExportProfile          `json:"profile"`
+	Cookies    map[string]CookieValue `json:"cookies"`
+	Library    []ExportLibraryEntry   `json:"library"`
+	Progress   []ExportProgress       `json:"progress"`
+	History    []ExportHistoryEntry   `json:"history"`
+	Follows    []ExportFollow         `json:"follows"`
+	Ratings    []ExportRating         `json:"ratings"`
+	Reviews    []ExportReview         `json:"reviews"`
+	Comments   []ExportComment        `json:"comments"`
+}
+
+type ProfileImportResult struct {
+	Profile  ProfileWithToken       `json:"profile"`
+	Cookies  map[string]CookieValue `json:"cookies"`
+	Library  int                    `json:"library"`
+	Progress int                    `json:"progress"`
+	History  int                    `json:"history"`
+	Follows  int                    `json:"follows"`
+	Ratings  int                    `json:"ratings"`
+	Avatar   bool                   `json:"avatar"`
+}
+
+type ProfileCleanupResult struct {
+	Profiles int `json:"profiles"`
+	Avatars  int `json:"avatars"`
+}
+
+type ReaderTheme struct {
+	Background string `json:"background"`
+	Text       string `json:"text"`
+	Link       string `json:"link"`
+}
+
+type ReaderSettings struct {
+	Version          int           `json:"version,omitempty"`
+	Theme            string        `json:"theme"`
+	FontSize         int           `json:"fontSize"`
+	FontFamily       string        `json:"fontFamily"`
+	Indent           int           `json:"indent"`
+	Density          string        `json:"density"`
+	Justify          bool          `json:"justify"`
+	LineHeight       float64       `json:"lineHeight,omitempty"`
+	ParagraphSpacing float64       `json:"paragraphSpacing,omitempty"`
+	MaxWidth         int           `json:"maxWidth,omitempty"`
+	CustomThemes     []ReaderTheme `json:"customThemes,omitempty"`
+}
+
 type UpdateProfileInput struct {
 	DisplayName *string `json:"display_name,omitempty"`
 }
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"sort"
@@ -82,6 +83,12 @@ func (h *Handler) RobotsTxt(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(content))
 }
 
+func (h *Handler) ServiceWorker(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/javascript")
+	w.Header().Set("Cache-Control", "no-cache")
+	http.ServeFile(w, r, "./assets/static/dist/sw.js")
+}
+
 func (h *Handler) Sitemap(w http.ResponseWriter, r *http.Request) {
 	items, err := data.GetSitemapData(r.Context())
 	if err != nil {
@@ -102,6 +109,7 @@ func (h *Handler) Sitemap(w http.ResponseWriter, r *http.Request) {
 		{Path: "dmca"},
 		{Path: "privacy"},
 		{Path: "copyright"},
+		{Path: "updates"},
 	}
 
 	content, err := templates.RenderSitemap(templates.SitemapData{
@@ -119,19 +127,21 @@ func (h *Handler) Sitemap(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(content))
 }
 
-func (h *Handler) getReaderSettings(r *http.Request) views.ReaderSettings {
-	settings := views.DefaultReaderSettings
+func (h *Handler) getReaderSettings(r *http.Request) models.ReaderSettings {
+	if settings, ok := h.getProfileReaderSettings(r); ok {
+		return settings
+	}
 
 	cookie, err := r.Cookie("kappalib_reader_settings")
 	if err != nil {
 		logger.Debug("Default settings. Reason: %v", err)
-		return settings
+		return data.DefaultReaderSettings
 	}
 
 	rawValue := cookie.Value
 	if rawValue == "" {
 		logger.Warn("Empty reader settings cookie.")
-		return settings
+		return data.DefaultReaderSettings
 	}
 
 	decoded, err := url.QueryUnescape(rawValue)
@@ -140,68 +150,29 @@ func (h *Handler) getReaderSettings(r *http.Request) views.ReaderSettings {
 		decoded = rawValue
 	}
 
-	var parsed struct {
-		Theme      string `json:"theme"`
-		FontSize   int    `json:"fontSize"`
-		FontFamily string `json:"fontFamily"`
-		Indent     int    `json:"indent"`
-		Density    string `json:"density"`
-		Justify    bool   `json:"justify"`
-	}
-
-	if err := json.Unmarshal([]byte(decoded), &parsed); err != nil {
-		logger.Warn("Failed to parse reader settings cookie: %v", err)
-		return settings
-	}
-
-	switch parsed.Theme {
-	case "light", "dark", "auto":
-		settings.Theme = parsed.Theme
-	default:
-		logger.Warn("Invalid theme value in reader settings: %s", parsed.Theme)
-		return settings
-	}
-
-	if parsed.FontSize < 14 || parsed.FontSize > 26 {
-		logger.Warn("Invalid fontSize value in reader settings: %d", parsed.FontSize)
-		return settings
-	}
-	settings.FontSize = parsed.FontSize
-
-	if !h.isValidFontFamily(parsed.FontFamily) {
-		logger.Warn("Invalid fontFamily value in reader settings: %s", parsed.FontFamily)
-		return settings
-	}
-	settings.FontFamily = parsed.FontFamily
-
-	if parsed.Indent < 0 || parsed.Indent > 3 {
-		logger.Warn("Invalid indent value in reader settings: %d", parsed.Indent)
-		return settings
-	}
-	settings.Indent = parsed.Indent
-
-	switch parsed.Density {
-	case "compact", "normal", "relaxed":
-		settings.Density = parsed.Density
-	default:
-		logger.Warn("Invalid density value in reader settings: %s", parsed.Density)
+	settings, err := data.ParseReaderSettings([]byte(decoded))
+	if err != nil {
+		logger.Warn("Invalid reader settings cookie: %v", err)
 		return settings
 	}
 
-	settings.Justify = parsed.Justify
-
 	logger.Debug("User settings applied successful.")
 
 	return settings
 }
 
-func (h *Handler) isValidFontFamily(family string) bool {
-	for _, f := range views.FontOptions {
-		if f.Value == family {
-			return true
-		}
+func (h *Handler) getProfileReaderSettings(r *http.Request) (models.ReaderSettings, bool) {
+	cookie, err := r.Cookie(data.ReaderSettingsCookieName)
+	if err != nil || cookie.Value == "" {
+		return models.ReaderSettings{}, false
 	}
-	return false
+
+	settings, ok := data.GetCookieReaderSettings(r.Context(), cookie.Value)
+	if !ok {
+		return models.ReaderSettings{}, false
+	}
+
+	return *settings, true
 }
 
 func (h *Handler) getNovelCookieData(r *http.Request, novelID string, chapters []models.ChapterSummary, totalChapters int) NovelCookieData {
@@ -343,6 +314,8 @@ func (h *Handler) Novel(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	h.recordView(r, id, "")
+
 	cookieData := h.getNovelCookieData(r, id, chapters.Chapters, chapters.Count)
 
 	if len(chapters.Chapters) > 0 {
@@ -357,6 +330,18 @@ func (h *Handler) Novel(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	var similar []models.Novel
+	if s, err := data.GetSimilarNovels(r.Context(), id, 8); err == nil {
+		similar = s.Novels
+	} else {
+		logger.Warn("Failed to fetch similar novels for %s: %v", id, err)
+	}
+
+	rating, err := data.GetRatingSummary(r.Context(), id)
+	if err != nil {
+		logger.Warn("Failed to fetch rating for %s: %v", id, err)
+	}
+
 	desc := fmt.Sprintf("%d глав · %s", chapters.Count, novel.Description)
 	if len([]rune(desc)) > 155 {
 		desc = string([]rune(desc)[:155]) + "..."
@@ -383,6 +368,10 @@ func (h *Handler) Novel(w http.ResponseWriter, r *http.Request) {
 		Status:   novel.Status,
 		CoverURL: views.DerefStr(novel.CoverURL),
 	}
+	if rating != nil && rating.Count > 0 {
+		schemaNovel.RatingValue = fmt.Sprintf("%.1f", rating.Average)
+		schemaNovel.RatingCount = rating.Count
+	}
 
 	schema, err := templates.RenderSchemaNovel(templates.SchemaNovelData{
 		Domain:      "https://kappalib.ru",
@@ -423,6 +412,8 @@ func (h *Handler) Novel(w http.ResponseWriter, r *http.Request) {
 		ProgressPercent: cookieData.ProgressPercent,
 		NextChapterNum:  cookieData.NextChapterNum,
 		TotalChapters:   chapters.Count,
+		Similar:         similar,
+		Rating:          rating,
 	}
 
 	h.render(w, r, views.Novel(props))
@@ -444,6 +435,21 @@ func (h *Handler) Home(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var trending []models.Novel
+	var updates []views.UpdatesDay
+	if page == 1 {
+		trending, err = data.GetTrendingNovels(r.Context(), 10)
+		if err != nil {
+			logger.Warn("Failed to fetch trending novels: %v", err)
+		}
+
+		if updatesPage, err := data.GetRecentUpdates(r.Context(), 1, 6); err == nil {
+			updates = views.GroupUpdatesByDay(updatesPage.Updates)
+		} else {
+			logger.Warn("Failed to fetch recent updates: %v", err)
+		}
+	}
+
 	canonical := "https://kappalib.ru"
 	if page > 1 {
 		canonical = fmt.Sprintf("https://kappalib.ru/?page=%d", page)
@@ -477,11 +483,136 @@ func (h *Handler) Home(w http.ResponseWriter, r *http.Request) {
 		TotalPages: dataResp.TotalPages,
 		SortOrder:  cookieData.SortOrder,
 		LastRead:   cookieData.LastReadWidget,
+		Trending:   trending,
+		Updates:    updates,
 	}
 
 	h.render(w, r, views.Home(props))
 }
 
+func (h *Handler) Updates(w http.ResponseWriter, r *http.Request) {
+	page := 1
+	if p, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && p > 0 {
+		page = p
+	}
+
+	updatesPage, err := data.GetRecentUpdates(r.Context(), page, 20)
+	if err != nil {
+		h.renderError(w, r, http.StatusServiceUnavailable, "Сервис временно недоступен", "Не удалось загрузить обновления. Пожалуйста, попробуйте позже.")
+		logger.Error("Failed to fetch updates page: %v", err)
+		return
+	}
+
+	canonical := "https://kappalib.ru/updates"
+	if page > 1 {
+		canonical = fmt.Sprintf("https://kappalib.ru/updates?page=%d", page)
+	}
+
+	props := views.UpdatesProps{
+		BaseProps: views.BaseProps{
+			Title:          "Обновления — kappalib",
+			Description:    "Новые главы веб-новелл и ранобэ, добавленные за последние дни.",
+			Canonical:      canonical,
+			Version:        h.assetVersion,
+			ReaderSettings: h.getReaderSettings(r),
+		},
+		Days:       views.GroupUpdatesByDay(updatesPage.Updates),
+		Page:       page,
+		TotalPages: updatesPage.TotalPages,
+	}
+
+	h.render(w, r, views.Updates(props))
+}
+
+func (h *Handler) Library(w http.ResponseWriter, r *http.Request) {
+	shelf := r.URL.Query().Get("shelf")
+	if shelf == "" {
+		shelf = "reading"
+	}
+
+	props := views.LibraryProps{
+		BaseProps: views.BaseProps{
+			Title:          "Моя библиотека — kappalib",
+			Description:    "Ваши новеллы: читаю, в планах, прочитано и брошено.",
+			Canonical:      "https://kappalib.ru/library",
+			Version:        h.assetVersion,
+			ReaderSettings: h.getReaderSettings(r),
+		},
+		Shelf: shelf,
+	}
+
+	h.render(w, r, views.Library(props))
+}
+
+func (h *Handler) History(w http.ResponseWriter, r *http.Request) {
+	props := views.HistoryProps{
+		BaseProps: views.BaseProps{
+			Title:          "История чтения — kappalib",
+			Description:    "Недавно прочитанные главы.",
+			Canonical:      "https://kappalib.ru/history",
+			Version:        h.assetVersion,
+			ReaderSettings: h.getReaderSettings(r),
+		},
+	}
+
+	h.render(w, r, views.History(props))
+}
+
+func (h *Handler) Notifications(w http.ResponseWriter, r *http.Request) {
+	props := views.NotificationsProps{
+		BaseProps: views.BaseProps{
+			Title:          "Уведомления — kappalib",
+			Description:    "Новые главы в новеллах, за которыми вы следите.",
+			Canonical:      "https://kappalib.ru/notifications",
+			Version:        h.assetVersion,
+			ReaderSettings: h.getReaderSettings(r),
+		},
+	}
+
+	h.render(w, r, views.Notifications(props))
+}
+
+func (h *Handler) User(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	page := 1
+	if p, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && p > 0 {
+		page = p
+	}
+
+	userPage, err := data.GetUserPage(r.Context(), id, page)
+	if err != nil {
+		switch err.Error() {
+		case "profile not found":
+			h.renderError(w, r, http.StatusNotFound, "Пользователь не найден", "Мы не смогли найти этого пользователя.")
+		case "profile is private":
+			h.renderError(w, r, http.StatusForbidden, "Профиль скрыт", "Пользователь ограничил доступ к своему профилю.")
+		default:
+			h.renderError(w, r, http.StatusServiceUnavailable, "Сервис временно недоступен", "Не удалось загрузить профиль. Пожалуйста, попробуйте позже.")
+			logger.Error("Failed to fetch user page %s: %v", id, err)
+		}
+		return
+	}
+
+	canonical := fmt.Sprintf("https://kappalib.ru/user/%s", id)
+	if page > 1 {
+		canonical = fmt.Sprintf("%s?page=%d", canonical, page)
+	}
+
+	props := views.UserProps{
+		BaseProps: views.BaseProps{
+			Title:          fmt.Sprintf("%s — kappalib", userPage.Profile.DisplayName),
+			Description:    fmt.Sprintf("Профиль читателя %s: %s.", userPage.Profile.DisplayName, views.PluralizeComments(userPage.Stats.CommentsCount)),
+			Canonical:      canonical,
+			OGImage:        views.AvatarURL(userPage.Profile),
+			Version:        h.assetVersion,
+			ReaderSettings: h.getReaderSettings(r),
+		},
+		User: userPage,
+	}
+
+	h.render(w, r, views.User(props))
+}
+
 func (h *Handler) Chapter(w http.ResponseWriter, r *http.Request) {
 	novelID := chi.URLParam(r, "id")
 	chapterID := chi.URLParam(r, "chapterId")
@@ -498,6 +629,8 @@ func (h *Handler) Chapter(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.recordView(r, novelID, chapter.ID)
+
 	allChapters, _ := data.GetChapters(r.Context(), novelID)
 	var prevID, nextID string
 
@@ -660,6 +793,22 @@ func (h *Handler) GetStatus(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+func (h *Handler) recordView(r *http.Request, novelID, chapterID string) {
+	ua := r.UserAgent()
+	if isBot(ua) {
+		return
+	}
+	data.RecordView(novelID, chapterID, clientIP(r)+"|"+ua)
+}
+
+func clientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
+
 func isBot(ua string) bool {
 	ua = strings.ToLower(ua)
 	bots := []string{"googlebot", "yandex", "bingbot", "duckduckbot", "baiduspider", "slurp", "facebookexternalhit", "twitterbot"}
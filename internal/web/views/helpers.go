@@ -1,12 +1,24 @@
 package views
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
+	"os"
 	"strings"
 	"time"
+
+	"github.com/ch1kulya/kappalib/internal/data"
+	"github.com/ch1kulya/kappalib/internal/models"
 )
 
+var moscowTZ = time.FixedZone("MSK", 3*60*60)
+
+var monthsGenitive = []string{
+	"января", "февраля", "марта", "апреля", "мая", "июня",
+	"июля", "августа", "сентября", "октября", "ноября", "декабря",
+}
+
 func MapStatus(status string) string {
 	s := strings.ToLower(status)
 	switch s {
@@ -36,11 +48,36 @@ func GetSortLabel(sort string) string {
 		return "По алфавиту"
 	case "created":
 		return "Недавно добавленные"
+	case "trending":
+		return "В тренде"
+	case "popular":
+		return "Популярные"
+	case "rating":
+		return "По рейтингу"
 	default:
 		return "Сначала старые"
 	}
 }
 
+func FormatRating(avg float64) string {
+	return strings.Replace(fmt.Sprintf("%.1f", avg), ".", ",", 1)
+}
+
+func PluralizeRatings(n int) string {
+	return fmt.Sprintf("%d %s", n, pluralize(n, "оценка", "оценки", "оценок"))
+}
+
+func RatingBarPercent(rating *models.RatingSummary, score int) int {
+	maxCount := 0
+	for _, cnt := range rating.Distribution {
+		maxCount = max(maxCount, cnt)
+	}
+	if maxCount == 0 || score < 1 || score > len(rating.Distribution) {
+		return 0
+	}
+	return rating.Distribution[score-1] * 100 / maxCount
+}
+
 func DerefStr(s *string) string {
 	if s == nil {
 		return ""
@@ -152,6 +189,83 @@ func FormatRelativeTime(t time.Time) string {
 	}
 }
 
+func FormatViews(n int64) string {
+	label := pluralize(int(n%100), "просмотр", "просмотра", "просмотров")
+	switch {
+	case n >= 1_000_000:
+		return strings.Replace(fmt.Sprintf("%.1f млн просмотров", float64(n)/1_000_000), ".", ",", 1)
+	case n >= 10_000:
+		return fmt.Sprintf("%d тыс. просмотров", n/1000)
+	default:
+		return fmt.Sprintf("%d %s", n, label)
+	}
+}
+
+func FormatDayLabel(day time.Time) string {
+	now := time.Now().In(moscowTZ)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	y, m, d := day.Date()
+	date := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+
+	switch {
+	case date.Equal(today):
+		return "Сегодня"
+	case date.Equal(today.AddDate(0, 0, -1)):
+		return "Вчера"
+	case y == now.Year():
+		return fmt.Sprintf("%d %s", d, monthsGenitive[m-1])
+	default:
+		return fmt.Sprintf("%d %s %d", d, monthsGenitive[m-1], y)
+	}
+}
+
+func FormatJoinDate(t time.Time) string {
+	t = t.In(moscowTZ)
+	return fmt.Sprintf("%d %s %d", t.Day(), monthsGenitive[t.Month()-1], t.Year())
+}
+
+func PluralizeComments(n int) string {
+	return fmt.Sprintf("%d %s", n, pluralize(n, "комментарий", "комментария", "комментариев"))
+}
+
+func avatarObjectURL(profileID, suffix, ext string) string {
+	scheme := "https"
+	if os.Getenv("S3_USE_SSL") == "false" {
+		scheme = "http"
+	}
+	return fmt.Sprintf("%s://%s/%s/avatars/%s%s.%s", scheme, os.Getenv("S3_ENDPOINT"), os.Getenv("S3_BUCKET"), profileID, suffix, ext)
+}
+
+func AvatarURL(profile models.ProfilePublic) string {
+	if profile.HasCustomAvatar {
+		return avatarObjectURL(profile.ID, "", "jpg")
+	}
+	return fmt.Sprintf("https://api.dicebear.com/9.x/bottts-neutral/svg?seed=%s&backgroundType=solid,gradientLinear", profile.AvatarSeed)
+}
+
+func AvatarWebPSrcSet(profile models.ProfilePublic) string {
+	return fmt.Sprintf("%s 1x, %s 2x", avatarObjectURL(profile.ID, "_128", "webp"), avatarObjectURL(profile.ID, "", "webp"))
+}
+
+func FormatChapterRange(first, last int) string {
+	if first == last {
+		return fmt.Sprintf("Глава %d", first)
+	}
+	return fmt.Sprintf("Главы %d–%d", first, last)
+}
+
+func GroupUpdatesByDay(updates []models.ChapterUpdate) []UpdatesDay {
+	var days []UpdatesDay
+	for _, u := range updates {
+		label := FormatDayLabel(u.Day)
+		if len(days) == 0 || days[len(days)-1].Label != label {
+			days = append(days, UpdatesDay{Label: label})
+		}
+		days[len(days)-1].Updates = append(days[len(days)-1].Updates, u)
+	}
+	return days
+}
+
 var FontOptions = []FontOption{
 	{Value: "default", Label: "Стандартный", Family: "inherit"},
 	{Value: "literata", Label: "Literata", Family: "Literata, serif"},
@@ -173,15 +287,6 @@ var FontURLs = map[string]string{
 	"roboto":       "https://cdn.jsdelivr.net/npm/@fontsource/roboto@5/index.min.css",
 }
 
-var DefaultReaderSettings = ReaderSettings{
-	Theme:      "auto",
-	FontSize:   18,
-	FontFamily: "default",
-	Indent:     0,
-	Density:    "normal",
-	Justify:    false,
-}
-
 func GetFontFamily(fontKey string) string {
 	for _, f := range FontOptions {
 		if f.Value == fontKey {
@@ -204,7 +309,33 @@ func GetFontURL(fontKey string) string {
 	return FontURLs[fontKey]
 }
 
-func chapterContentClasses(settings ReaderSettings) string {
+func readerSettingsJSON(settings models.ReaderSettings) string {
+	raw, _ := json.Marshal(settings)
+	return string(raw)
+}
+
+func readerThemeAttr(settings models.ReaderSettings) string {
+	if theme := data.ReaderCustomTheme(settings); theme != nil {
+		if data.RelativeLuminance(theme.Background) < 0.179 {
+			return "dark"
+		}
+		return "light"
+	}
+	if settings.Theme == "auto" {
+		return ""
+	}
+	return settings.Theme
+}
+
+func readerThemeStyle(settings models.ReaderSettings) string {
+	theme := data.ReaderCustomTheme(settings)
+	if theme == nil {
+		return ""
+	}
+	return fmt.Sprintf("--bg-primary: %s; --primary: %s; --reader-link: %s;", theme.Background, theme.Text, theme.Link)
+}
+
+func chapterContentClasses(settings models.ReaderSettings) string {
 	classes := "chapter-content"
 	classes += " density-" + settings.Density
 	if settings.Justify {
@@ -213,7 +344,7 @@ func chapterContentClasses(settings ReaderSettings) string {
 	return classes
 }
 
-func chapterContentStyle(settings ReaderSettings) string {
+func chapterContentStyle(settings models.ReaderSettings) string {
 	style := fmt.Sprintf("font-size: %.4frem;", float64(settings.FontSize)/16)
 	if settings.FontFamily != "default" {
 		style += fmt.Sprintf(" font-family: %s;", GetFontFamily(settings.FontFamily))
@@ -223,10 +354,19 @@ func chapterContentStyle(settings ReaderSettings) string {
 	} else {
 		style += " --reader-indent: 0;"
 	}
+	if settings.LineHeight > 0 {
+		style += fmt.Sprintf(" --reader-line-height: %g;", settings.LineHeight)
+	}
+	if settings.ParagraphSpacing > 0 {
+		style += fmt.Sprintf(" --reader-paragraph-spacing: %gem;", settings.ParagraphSpacing)
+	}
+	if settings.MaxWidth > 0 {
+		style += fmt.Sprintf(" --reader-max-width: %dch;", settings.MaxWidth)
+	}
 	return style
 }
 
-func chapterTitleStyle(settings ReaderSettings) string {
+func chapterTitleStyle(settings models.ReaderSettings) string {
 	baseFontSize := float64(settings.FontSize)
 	titleRatio := 1.5 / 1.125
 	titleFontSize := baseFontSize * titleRatio
@@ -242,9 +382,22 @@ func chapterTitleStyle(settings ReaderSettings) string {
 	return style
 }
 
-func chapterTitleClasses(settings ReaderSettings) string {
+func chapterTitleClasses(settings models.ReaderSettings) string {
 	if settings.Justify {
 		return "justify-text"
 	}
 	return ""
 }
+
+var LibraryShelves = []struct{ Val, Label string }{
+	{"reading", "Читаю"},
+	{"planned", "В планах"},
+	{"completed", "Прочитано"},
+	{"dropped", "Брошено"},
+}
+
+var CommentSorts = []struct{ Val, Label string }{
+	{"newest", "Сначала новые"},
+	{"oldest", "Сначала старые"},
+	{"top", "Лучшие"},
+}
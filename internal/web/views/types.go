@@ -13,7 +13,7 @@ type BaseProps struct {
 	IsAdult        bool
 	Novel          *models.Novel
 	PrefetchURL    string
-	ReaderSettings ReaderSettings
+	ReaderSettings models.ReaderSettings
 }
 
 type LastReadWidgetData struct {
@@ -31,6 +31,38 @@ type HomeProps struct {
 	TotalPages int
 	SortOrder  string
 	LastRead   *LastReadWidgetData
+	Trending   []models.Novel
+	Updates    []UpdatesDay
+}
+
+type UpdatesDay struct {
+	Label   string
+	Updates []models.ChapterUpdate
+}
+
+type UpdatesProps struct {
+	BaseProps
+	Days       []UpdatesDay
+	Page       int
+	TotalPages int
+}
+
+type LibraryProps struct {
+	BaseProps
+	Shelf string
+}
+
+type HistoryProps struct {
+	BaseProps
+}
+
+type NotificationsProps struct {
+	BaseProps
+}
+
+type UserProps struct {
+	BaseProps
+	User *models.UserPage
 }
 
 type NovelProps struct {
@@ -43,6 +75,8 @@ type NovelProps struct {
 	ProgressPercent int
 	NextChapterNum  int
 	TotalChapters   int
+	Similar         []models.Novel
+	Rating          *models.RatingSummary
 }
 
 type ChapterProps struct {
@@ -65,15 +99,6 @@ type ErrorProps struct {
 	ErrorMessage string
 }
 
-type ReaderSettings struct {
-	Theme      string
-	FontSize   int
-	FontFamily string
-	Indent     int
-	Density    string
-	Justify    bool
-}
-
 type FontOption struct {
 	Value  string
 	Label  string
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS chapter_views;
+DROP TABLE IF EXISTS novel_views;
+
+ALTER TABLE chapters DROP COLUMN IF EXISTS views_count;
+ALTER TABLE novels DROP COLUMN IF EXISTS views_count;
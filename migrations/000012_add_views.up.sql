@@ -0,0 +1,19 @@
+ALTER TABLE novels ADD COLUMN IF NOT EXISTS views_count BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE chapters ADD COLUMN IF NOT EXISTS views_count BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS novel_views (
+    novel_id VARCHAR(20) NOT NULL REFERENCES novels(id) ON DELETE CASCADE,
+    visitor_hash VARCHAR(64) NOT NULL,
+    day DATE NOT NULL,
+    PRIMARY KEY (novel_id, day, visitor_hash)
+);
+
+CREATE TABLE IF NOT EXISTS chapter_views (
+    chapter_id VARCHAR(20) NOT NULL REFERENCES chapters(id) ON DELETE CASCADE,
+    visitor_hash VARCHAR(64) NOT NULL,
+    day DATE NOT NULL,
+    PRIMARY KEY (chapter_id, day, visitor_hash)
+);
+
+CREATE INDEX IF NOT EXISTS idx_novel_views_day ON novel_views(day);
+CREATE INDEX IF NOT EXISTS idx_chapter_views_day ON chapter_views(day);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS novel_rankings (
+    novel_id VARCHAR(20) PRIMARY KEY REFERENCES novels(id) ON DELETE CASCADE,
+    trending_score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    popular_score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    trending_rank INTEGER NOT NULL,
+    popular_rank INTEGER NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_novel_rankings_trending ON novel_rankings(trending_rank);
+CREATE INDEX IF NOT EXISTS idx_novel_rankings_popular ON novel_rankings(popular_rank);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS novel_similar (
+    novel_id VARCHAR(20) NOT NULL REFERENCES novels(id) ON DELETE CASCADE,
+    similar_id VARCHAR(20) NOT NULL REFERENCES novels(id) ON DELETE CASCADE,
+    score DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (novel_id, similar_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_novel_similar_score ON novel_similar(novel_id, score DESC);
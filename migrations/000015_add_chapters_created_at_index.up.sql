@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_chapters_created_at ON chapters(created_at DESC);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS library_entries (
+    user_id VARCHAR(20) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    novel_id VARCHAR(20) NOT NULL REFERENCES novels(id) ON DELETE CASCADE,
+    shelf VARCHAR(20) NOT NULL CHECK (shelf IN ('reading', 'planned', 'completed', 'dropped')),
+    rating SMALLINT CHECK (rating BETWEEN 1 AND 10),
+    created_at TIMESTAMPTZ DEFAULT now(),
+    updated_at TIMESTAMPTZ DEFAULT now(),
+    PRIMARY KEY (user_id, novel_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_library_entries_user_shelf ON library_entries(user_id, shelf, updated_at DESC);
+CREATE INDEX IF NOT EXISTS idx_library_entries_novel_id ON library_entries(novel_id);
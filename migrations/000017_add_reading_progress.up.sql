@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS reading_progress (
+    user_id VARCHAR(20) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    novel_id VARCHAR(20) NOT NULL REFERENCES novels(id) ON DELETE CASCADE,
+    chapter_id VARCHAR(20) NOT NULL REFERENCES chapters(id) ON DELETE CASCADE,
+    paragraph INTEGER NOT NULL DEFAULT 0 CHECK (paragraph >= 0),
+    scroll_percent REAL NOT NULL DEFAULT 0 CHECK (scroll_percent BETWEEN 0 AND 100),
+    updated_at TIMESTAMPTZ DEFAULT now(),
+    PRIMARY KEY (user_id, novel_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_reading_progress_user_updated ON reading_progress(user_id, updated_at DESC);
+CREATE INDEX IF NOT EXISTS idx_reading_progress_novel_id ON reading_progress(novel_id);
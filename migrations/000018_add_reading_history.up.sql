@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS reading_history (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(20) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    novel_id VARCHAR(20) NOT NULL REFERENCES novels(id) ON DELETE CASCADE,
+    chapter_id VARCHAR(20) NOT NULL REFERENCES chapters(id) ON DELETE CASCADE,
+    read_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_reading_history_user_read_at ON reading_history(user_id, read_at DESC);
+CREATE INDEX IF NOT EXISTS idx_reading_history_read_at ON reading_history(read_at);
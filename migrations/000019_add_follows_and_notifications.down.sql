@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS trg_notify_followers_new_chapters ON chapters;
+DROP FUNCTION IF EXISTS notify_followers_new_chapters();
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS novel_follows;
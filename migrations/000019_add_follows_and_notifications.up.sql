@@ -0,0 +1,66 @@
+CREATE TABLE IF NOT EXISTS novel_follows (
+    user_id VARCHAR(20) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    novel_id VARCHAR(20) NOT NULL REFERENCES novels(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    PRIMARY KEY (user_id, novel_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_novel_follows_novel_id ON novel_follows(novel_id);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(20) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(30) NOT NULL,
+    novel_id VARCHAR(20) NOT NULL REFERENCES novels(id) ON DELETE CASCADE,
+    chapter_id VARCHAR(20) REFERENCES chapters(id) ON DELETE SET NULL,
+    first_chapter_num INTEGER NOT NULL DEFAULT 0,
+    last_chapter_num INTEGER NOT NULL DEFAULT 0,
+    chapters_count INTEGER NOT NULL DEFAULT 0,
+    is_read BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    updated_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_updated ON notifications(user_id, updated_at DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_user_unread ON notifications(user_id) WHERE NOT is_read;
+CREATE INDEX IF NOT EXISTS idx_notifications_created_at ON notifications(created_at);
+
+CREATE OR REPLACE FUNCTION notify_followers_new_chapters() RETURNS TRIGGER AS $$
+BEGIN
+    WITH batches AS (
+        SELECT
+            novel_id,
+            (array_agg(id ORDER BY chapter_num ASC))[1] AS first_chapter_id,
+            MIN(chapter_num) AS first_num,
+            MAX(chapter_num) AS last_num,
+            COUNT(*) AS cnt
+        FROM new_chapters
+        GROUP BY novel_id
+    ), merged AS (
+        UPDATE notifications n
+        SET chapter_id = CASE WHEN b.first_num < n.first_chapter_num THEN b.first_chapter_id ELSE n.chapter_id END,
+            first_chapter_num = LEAST(n.first_chapter_num, b.first_num),
+            last_chapter_num = GREATEST(n.last_chapter_num, b.last_num),
+            chapters_count = n.chapters_count + b.cnt,
+            updated_at = now()
+        FROM batches b
+        WHERE n.novel_id = b.novel_id AND n.type = 'new_chapters' AND NOT n.is_read
+        RETURNING n.user_id, n.novel_id
+    )
+    INSERT INTO notifications (user_id, type, novel_id, chapter_id, first_chapter_num, last_chapter_num, chapters_count)
+    SELECT f.user_id, 'new_chapters', b.novel_id, b.first_chapter_id, b.first_num, b.last_num, b.cnt
+    FROM batches b
+    JOIN novel_follows f ON f.novel_id = b.novel_id
+    WHERE NOT EXISTS (
+        SELECT 1 FROM merged m WHERE m.user_id = f.user_id AND m.novel_id = b.novel_id
+    );
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_notify_followers_new_chapters ON chapters;
+
+CREATE TRIGGER trg_notify_followers_new_chapters
+AFTER INSERT ON chapters
+REFERENCING NEW TABLE AS new_chapters
+FOR EACH STATEMENT EXECUTE FUNCTION notify_followers_new_chapters();
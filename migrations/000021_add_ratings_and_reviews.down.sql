@@ -0,0 +1,17 @@
+DROP TABLE IF EXISTS novel_reviews;
+
+DROP TRIGGER IF EXISTS trg_update_novel_rating ON novel_ratings;
+DROP FUNCTION IF EXISTS update_novel_rating();
+
+DROP INDEX IF EXISTS idx_novels_rating;
+ALTER TABLE novels DROP COLUMN IF EXISTS rating_count;
+ALTER TABLE novels DROP COLUMN IF EXISTS rating_sum;
+
+ALTER TABLE library_entries ADD COLUMN IF NOT EXISTS rating SMALLINT CHECK (rating BETWEEN 1 AND 10);
+
+UPDATE library_entries l
+SET rating = r.score
+FROM novel_ratings r
+WHERE r.user_id = l.user_id AND r.novel_id = l.novel_id;
+
+DROP TABLE IF EXISTS novel_ratings;
@@ -0,0 +1,64 @@
+CREATE TABLE IF NOT EXISTS novel_ratings (
+    user_id VARCHAR(20) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    novel_id VARCHAR(20) NOT NULL REFERENCES novels(id) ON DELETE CASCADE,
+    score SMALLINT NOT NULL CHECK (score BETWEEN 1 AND 10),
+    created_at TIMESTAMPTZ DEFAULT now(),
+    updated_at TIMESTAMPTZ DEFAULT now(),
+    PRIMARY KEY (user_id, novel_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_novel_ratings_novel_id ON novel_ratings(novel_id);
+
+INSERT INTO novel_ratings (user_id, novel_id, score, created_at, updated_at)
+SELECT user_id, novel_id, rating, created_at, updated_at
+FROM library_entries
+WHERE rating IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE library_entries DROP COLUMN IF EXISTS rating;
+
+ALTER TABLE novels ADD COLUMN IF NOT EXISTS rating_sum INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE novels ADD COLUMN IF NOT EXISTS rating_count INTEGER NOT NULL DEFAULT 0;
+
+WITH totals AS (
+    SELECT novel_id, SUM(score) AS total, COUNT(*) AS cnt FROM novel_ratings GROUP BY novel_id
+)
+UPDATE novels n
+SET rating_sum = t.total, rating_count = t.cnt
+FROM totals t
+WHERE n.id = t.novel_id;
+
+CREATE INDEX IF NOT EXISTS idx_novels_rating ON novels (((rating_sum + 35.0) / (rating_count + 5)) DESC);
+
+CREATE OR REPLACE FUNCTION update_novel_rating() RETURNS TRIGGER AS $$
+BEGIN
+    IF (TG_OP = 'INSERT') THEN
+        UPDATE novels SET rating_sum = rating_sum + NEW.score, rating_count = rating_count + 1 WHERE id = NEW.novel_id;
+    ELSIF (TG_OP = 'UPDATE') THEN
+        UPDATE novels SET rating_sum = rating_sum - OLD.score + NEW.score WHERE id = NEW.novel_id;
+    ELSIF (TG_OP = 'DELETE') THEN
+        UPDATE novels SET rating_sum = rating_sum - OLD.score, rating_count = rating_count - 1 WHERE id = OLD.novel_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_update_novel_rating ON novel_ratings;
+
+CREATE TRIGGER trg_update_novel_rating
+AFTER INSERT OR UPDATE OF score OR DELETE ON novel_ratings
+FOR EACH ROW EXECUTE FUNCTION update_novel_rating();
+
+CREATE TABLE IF NOT EXISTS novel_reviews (
+    id VARCHAR(20) PRIMARY KEY DEFAULT generate_short_id('rev_'),
+    novel_id VARCHAR(20) NOT NULL REFERENCES novels(id) ON DELETE CASCADE,
+    user_id VARCHAR(20) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    content_html TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    telegram_message_id BIGINT,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    updated_at TIMESTAMPTZ DEFAULT now(),
+    UNIQUE (user_id, novel_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_novel_reviews_novel_status ON novel_reviews(novel_id, status, created_at DESC);
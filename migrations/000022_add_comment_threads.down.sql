@@ -0,0 +1,11 @@
+DROP TRIGGER IF EXISTS trg_update_comment_replies ON comments;
+DROP FUNCTION IF EXISTS update_comment_replies();
+
+DELETE FROM notifications WHERE type = 'comment_reply';
+ALTER TABLE notifications DROP COLUMN IF EXISTS comment_id;
+
+DROP INDEX IF EXISTS idx_comments_chapter_top_level;
+DROP INDEX IF EXISTS idx_comments_parent_id;
+ALTER TABLE comments DROP COLUMN IF EXISTS replies_count;
+ALTER TABLE comments DROP COLUMN IF EXISTS depth;
+ALTER TABLE comments DROP COLUMN IF EXISTS parent_id;
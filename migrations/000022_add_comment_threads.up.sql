@@ -0,0 +1,46 @@
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS parent_id VARCHAR(20) REFERENCES comments(id) ON DELETE CASCADE;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS depth SMALLINT NOT NULL DEFAULT 0;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS replies_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_comments_parent_id ON comments(parent_id, created_at) WHERE parent_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_comments_chapter_top_level ON comments(chapter_id, created_at DESC) WHERE parent_id IS NULL AND status = 'approved';
+
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS comment_id VARCHAR(20) REFERENCES comments(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_notifications_comment_id ON notifications(comment_id) WHERE comment_id IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION update_comment_replies() RETURNS TRIGGER AS $$
+BEGIN
+    IF (TG_OP = 'DELETE') THEN
+        IF OLD.parent_id IS NOT NULL AND OLD.status = 'approved' THEN
+            UPDATE comments SET replies_count = GREATEST(replies_count - 1, 0) WHERE id = OLD.parent_id;
+        END IF;
+        RETURN NULL;
+    END IF;
+
+    IF NEW.parent_id IS NULL OR NEW.status IS NOT DISTINCT FROM OLD.status THEN
+        RETURN NULL;
+    END IF;
+
+    IF NEW.status = 'approved' THEN
+        UPDATE comments SET replies_count = replies_count + 1 WHERE id = NEW.parent_id;
+
+        INSERT INTO notifications (user_id, type, novel_id, chapter_id, comment_id)
+        SELECT p.user_id, 'comment_reply', ch.novel_id, NEW.chapter_id, NEW.id
+        FROM comments p
+        JOIN chapters ch ON ch.id = p.chapter_id
+        WHERE p.id = NEW.parent_id
+          AND p.user_id <> NEW.user_id
+          AND NOT EXISTS (SELECT 1 FROM notifications WHERE comment_id = NEW.id);
+    ELSIF OLD.status = 'approved' THEN
+        UPDATE comments SET replies_count = GREATEST(replies_count - 1, 0) WHERE id = NEW.parent_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_update_comment_replies ON comments;
+
+CREATE TRIGGER trg_update_comment_replies
+AFTER UPDATE OF status OR DELETE ON comments
+FOR EACH ROW EXECUTE FUNCTION update_comment_replies();
@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS trg_update_comment_reaction_counts ON comment_reactions;
+DROP FUNCTION IF EXISTS update_comment_reaction_counts();
+
+DROP INDEX IF EXISTS idx_comments_chapter_top;
+ALTER TABLE comments DROP COLUMN IF EXISTS dislikes_count;
+ALTER TABLE comments DROP COLUMN IF EXISTS likes_count;
+
+DROP TABLE IF EXISTS comment_reactions;
@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS comment_reactions (
+    user_id VARCHAR(20) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    comment_id VARCHAR(20) NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    value SMALLINT NOT NULL CHECK (value IN (-1, 1)),
+    created_at TIMESTAMPTZ DEFAULT now(),
+    PRIMARY KEY (user_id, comment_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_comment_reactions_comment_id ON comment_reactions(comment_id);
+
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS likes_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS dislikes_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_comments_chapter_top ON comments(chapter_id, (likes_count - dislikes_count) DESC, created_at DESC) WHERE parent_id IS NULL AND status = 'approved';
+
+CREATE OR REPLACE FUNCTION update_comment_reaction_counts() RETURNS TRIGGER AS $$
+BEGIN
+    IF (TG_OP = 'INSERT' OR TG_OP = 'UPDATE') THEN
+        UPDATE comments SET
+            likes_count = likes_count + CASE WHEN NEW.value = 1 THEN 1 ELSE 0 END,
+            dislikes_count = dislikes_count + CASE WHEN NEW.value = -1 THEN 1 ELSE 0 END
+        WHERE id = NEW.comment_id;
+    END IF;
+    IF (TG_OP = 'DELETE' OR TG_OP = 'UPDATE') THEN
+        UPDATE comments SET
+            likes_count = GREATEST(likes_count - CASE WHEN OLD.value = 1 THEN 1 ELSE 0 END, 0),
+            dislikes_count = GREATEST(dislikes_count - CASE WHEN OLD.value = -1 THEN 1 ELSE 0 END, 0)
+        WHERE id = OLD.comment_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_update_comment_reaction_counts ON comment_reactions;
+
+CREATE TRIGGER trg_update_comment_reaction_counts
+AFTER INSERT OR UPDATE OF value OR DELETE ON comment_reactions
+FOR EACH ROW EXECUTE FUNCTION update_comment_reaction_counts();
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS comment_edits;
+
+ALTER TABLE comments DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE comments DROP COLUMN IF EXISTS edited_at;
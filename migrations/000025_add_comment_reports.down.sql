@@ -0,0 +1,9 @@
+DROP TRIGGER IF EXISTS trg_update_comment_reports_count ON comment_reports;
+DROP FUNCTION IF EXISTS update_comment_reports_count();
+DROP TABLE IF EXISTS comment_reports;
+
+ALTER TABLE comments DROP COLUMN IF EXISTS reports_count;
+
+UPDATE comments SET status = 'rejected' WHERE status = 'hidden';
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS comments_status_check;
+ALTER TABLE comments ADD CONSTRAINT comments_status_check CHECK (status IN ('pending', 'approved', 'rejected'));
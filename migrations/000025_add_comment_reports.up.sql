@@ -0,0 +1,31 @@
+ALTER TABLE comments DROP CONSTRAINT IF EXISTS comments_status_check;
+ALTER TABLE comments ADD CONSTRAINT comments_status_check CHECK (status IN ('pending', 'approved', 'rejected', 'hidden'));
+
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS reports_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS comment_reports (
+    user_id VARCHAR(20) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    comment_id VARCHAR(20) NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('spam', 'offensive', 'spoiler', 'other')),
+    created_at TIMESTAMPTZ DEFAULT now(),
+    PRIMARY KEY (user_id, comment_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_comment_reports_comment_id ON comment_reports(comment_id);
+
+CREATE OR REPLACE FUNCTION update_comment_reports_count() RETURNS TRIGGER AS $$
+BEGIN
+    IF (TG_OP = 'INSERT') THEN
+        UPDATE comments SET reports_count = reports_count + 1 WHERE id = NEW.comment_id;
+    ELSIF (TG_OP = 'DELETE') THEN
+        UPDATE comments SET reports_count = GREATEST(reports_count - 1, 0) WHERE id = OLD.comment_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_update_comment_reports_count ON comment_reports;
+
+CREATE TRIGGER trg_update_comment_reports_count
+AFTER INSERT OR DELETE ON comment_reports
+FOR EACH ROW EXECUTE FUNCTION update_comment_reports_count();
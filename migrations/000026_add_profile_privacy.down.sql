@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_comments_user_approved;
+
+ALTER TABLE users DROP COLUMN IF EXISTS is_public;
@@ -0,0 +1,4 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_public BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE users ALTER COLUMN is_public SET DEFAULT true;
+
+CREATE INDEX IF NOT EXISTS idx_comments_user_approved ON comments(user_id, created_at DESC) WHERE status = 'approved' AND deleted_at IS NULL;
@@ -0,0 +1,15 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS secret_token VARCHAR(64);
+
+UPDATE users u SET secret_token = s.token
+FROM (
+    SELECT DISTINCT ON (user_id) user_id, token
+    FROM sessions
+    ORDER BY user_id, last_seen_at DESC
+) s
+WHERE s.user_id = u.id;
+
+UPDATE users SET secret_token = md5(random()::text) || md5(random()::text) WHERE secret_token IS NULL;
+
+ALTER TABLE users ALTER COLUMN secret_token SET NOT NULL;
+
+DROP TABLE IF EXISTS sessions;
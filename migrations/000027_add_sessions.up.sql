@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS sessions (
+    id VARCHAR(20) PRIMARY KEY DEFAULT generate_short_id('ses_'),
+    user_id VARCHAR(20) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token VARCHAR(64) NOT NULL UNIQUE,
+    device_label VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT now(),
+    last_seen_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id, last_seen_at DESC);
+
+INSERT INTO sessions (user_id, token, device_label, created_at, last_seen_at)
+SELECT id, secret_token, 'Неизвестное устройство', created_at, last_active_at
+FROM users
+ON CONFLICT (token) DO NOTHING;
+
+ALTER TABLE users DROP COLUMN IF EXISTS secret_token;
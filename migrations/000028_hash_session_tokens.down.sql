@@ -0,0 +1,5 @@
+DELETE FROM sessions WHERE token IS NULL;
+
+ALTER TABLE sessions DROP CONSTRAINT IF EXISTS sessions_token_check;
+ALTER TABLE sessions ALTER COLUMN token SET NOT NULL;
+ALTER TABLE sessions DROP COLUMN IF EXISTS token_hash;
@@ -0,0 +1,3 @@
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS token_hash VARCHAR(64) UNIQUE;
+ALTER TABLE sessions ALTER COLUMN token DROP NOT NULL;
+ALTER TABLE sessions ADD CONSTRAINT sessions_token_check CHECK (token IS NOT NULL OR token_hash IS NOT NULL);
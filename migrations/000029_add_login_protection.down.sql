@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS login_requests;
+DROP TABLE IF EXISTS login_attempts;
+
+ALTER TABLE users DROP COLUMN IF EXISTS sync_code_confirm;
@@ -0,0 +1,26 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS sync_code_confirm BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS login_attempts (
+    id BIGSERIAL PRIMARY KEY,
+    ip VARCHAR(64) NOT NULL,
+    user_id VARCHAR(20) REFERENCES users(id) ON DELETE SET NULL,
+    outcome VARCHAR(20) NOT NULL CHECK (outcome IN ('success', 'invalid_code', 'locked', 'pending')),
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_attempts_created_at ON login_attempts(created_at);
+CREATE INDEX IF NOT EXISTS idx_login_attempts_ip ON login_attempts(ip, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS login_requests (
+    id VARCHAR(20) PRIMARY KEY DEFAULT generate_short_id('lrq_'),
+    user_id VARCHAR(20) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    poll_token_hash VARCHAR(64) NOT NULL,
+    device_label VARCHAR(100) NOT NULL DEFAULT '',
+    ip VARCHAR(64) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'denied', 'completed')),
+    created_at TIMESTAMPTZ DEFAULT now(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_requests_user_id ON login_requests(user_id, created_at DESC) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_login_requests_expires_at ON login_requests(expires_at);
@@ -0,0 +1,2 @@
+ALTER TABLE users DROP COLUMN IF EXISTS avatar_telegram_message_id;
+ALTER TABLE users DROP COLUMN IF EXISTS avatar_status;
@@ -0,0 +1,2 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_status VARCHAR(20) CHECK (avatar_status IN ('pending', 'rejected'));
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_telegram_message_id BIGINT;
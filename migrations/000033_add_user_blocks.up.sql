@@ -0,0 +1,44 @@
+CREATE TABLE IF NOT EXISTS user_blocks (
+    user_id VARCHAR(20) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    blocked_user_id VARCHAR(20) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(10) NOT NULL DEFAULT 'block' CHECK (kind IN ('mute', 'block')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, blocked_user_id),
+    CHECK (user_id <> blocked_user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_blocks_blocked_user_id ON user_blocks(blocked_user_id);
+
+CREATE OR REPLACE FUNCTION update_comment_replies() RETURNS TRIGGER AS $$
+BEGIN
+    IF (TG_OP = 'DELETE') THEN
+        IF OLD.parent_id IS NOT NULL AND OLD.status = 'approved' THEN
+            UPDATE comments SET replies_count = GREATEST(replies_count - 1, 0) WHERE id = OLD.parent_id;
+        END IF;
+        RETURN NULL;
+    END IF;
+
+    IF NEW.parent_id IS NULL OR NEW.status IS NOT DISTINCT FROM OLD.status THEN
+        RETURN NULL;
+    END IF;
+
+    IF NEW.status = 'approved' THEN
+        UPDATE comments SET replies_count = replies_count + 1 WHERE id = NEW.parent_id;
+
+        INSERT INTO notifications (user_id, type, novel_id, chapter_id, comment_id)
+        SELECT r.user_id, 'comment_reply', ch.novel_id, NEW.chapter_id, NEW.id
+        FROM (
+            SELECT COALESCE(NEW.reply_to_user_id, p.user_id) AS user_id
+            FROM comments p
+            WHERE p.id = NEW.parent_id
+        ) r
+        JOIN chapters ch ON ch.id = NEW.chapter_id
+        WHERE r.user_id <> NEW.user_id
+          AND NOT EXISTS (SELECT 1 FROM notifications WHERE comment_id = NEW.id)
+          AND NOT EXISTS (SELECT 1 FROM user_blocks b WHERE b.user_id = r.user_id AND b.blocked_user_id = NEW.user_id);
+    ELSIF OLD.status = 'approved' THEN
+        UPDATE comments SET replies_count = GREATEST(replies_count - 1, 0) WHERE id = NEW.parent_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1 @@
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS reports_kept_at TIMESTAMPTZ;
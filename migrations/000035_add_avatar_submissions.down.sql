@@ -0,0 +1,3 @@
+UPDATE users SET avatar_status = NULL, avatar_telegram_message_id = NULL WHERE avatar_status = 'pending';
+
+ALTER TABLE users DROP COLUMN IF EXISTS avatar_submission_id;
@@ -0,0 +1,3 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_submission_id VARCHAR(32);
+
+UPDATE users SET avatar_status = NULL, avatar_telegram_message_id = NULL WHERE avatar_status = 'pending';
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_novels_description_trgm;
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_novels_description_trgm ON novels USING gist (description gist_trgm_ops);
@@ -0,0 +1,33 @@
+CREATE OR REPLACE FUNCTION notify_followers_new_chapters() RETURNS TRIGGER AS $$
+BEGIN
+    WITH batches AS (
+        SELECT
+            novel_id,
+            (array_agg(id ORDER BY chapter_num ASC))[1] AS first_chapter_id,
+            MIN(chapter_num) AS first_num,
+            MAX(chapter_num) AS last_num,
+            COUNT(*) AS cnt
+        FROM new_chapters
+        GROUP BY novel_id
+    ), merged AS (
+        UPDATE notifications n
+        SET chapter_id = CASE WHEN b.first_num < n.first_chapter_num THEN b.first_chapter_id ELSE n.chapter_id END,
+            first_chapter_num = LEAST(n.first_chapter_num, b.first_num),
+            last_chapter_num = GREATEST(n.last_chapter_num, b.last_num),
+            chapters_count = n.chapters_count + b.cnt,
+            updated_at = now()
+        FROM batches b
+        WHERE n.novel_id = b.novel_id AND n.type = 'new_chapters' AND NOT n.is_read
+          AND EXISTS (SELECT 1 FROM novel_follows f WHERE f.user_id = n.user_id AND f.novel_id = n.novel_id)
+        RETURNING n.user_id, n.novel_id
+    )
+    INSERT INTO notifications (user_id, type, novel_id, chapter_id, first_chapter_num, last_chapter_num, chapters_count)
+    SELECT f.user_id, 'new_chapters', b.novel_id, b.first_chapter_id, b.first_num, b.last_num, b.cnt
+    FROM batches b
+    JOIN novel_follows f ON f.novel_id = b.novel_id
+    WHERE NOT EXISTS (
+        SELECT 1 FROM merged m WHERE m.user_id = f.user_id AND m.novel_id = b.novel_id
+    );
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;